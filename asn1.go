@@ -0,0 +1,33 @@
+package bytebits
+
+
+// AppendASN1 appends to dst the contents octets
+// of the ASN.1 BER or DER encoding of bit string s as a BIT STRING,
+// namely an initial octet giving the number of unused bits
+// in the final octet, from 0 to 7, followed by the bits of s
+// in big-endian bit order, with the unused bits set to zero as DER requires.
+// The identifier and length octets are not included.
+// Returns the extended slice.
+func (s BitString) AppendASN1(dst []byte) []byte {
+	dst = append(dst, byte(-s.len & 7))
+	return append(dst, s.buf...)
+}
+
+// ParseASN1BitString parses the contents octets
+// of an ASN.1 BER or DER encoded BIT STRING in primitive form,
+// as produced by AppendASN1, and returns the resulting bit string.
+// Any nonzero unused bits, which BER permits but DER does not,
+// are cleared in the returned bit string.
+// Returns ErrInvalidCode if the contents are empty,
+// the unused-bits count exceeds 7,
+// or the unused-bits count is nonzero for an empty bit string.
+func ParseASN1BitString(content []byte) (BitString, error) {
+	if len(content) == 0 {
+		return BitString{}, ErrInvalidCode
+	}
+	u := int(content[0])
+	if u > 7 || (len(content) == 1 && u != 0) {
+		return BitString{}, ErrInvalidCode
+	}
+	return MakeBitString(content[1:], 0, (len(content) - 1) * 8 - u), nil
+}
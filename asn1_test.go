@@ -0,0 +1,43 @@
+package bytebits
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"testing"
+)
+
+
+func TestASN1BitString(t *testing.T) {
+	for _, w := range []int{0, 1, 7, 8, 9, 23} {
+		s := MakeBitString([]byte{0xb5, 0x5a, 0xff}, 1, w)
+
+		// Compare with the standard encoding/asn1 package
+		want, err := asn1.Marshal(asn1.BitString{
+			Bytes: s.Bytes(), BitLength: w})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := s.AppendASN1([]byte{0x03, byte(len(s.Bytes()) + 1)})
+		if !bytes.Equal(got, want) {
+			t.Errorf("w=%v: AppendASN1 got %x, want %x", w, got, want)
+		}
+
+		p, err := ParseASN1BitString(got[2:])
+		if err != nil || Compare(p, s) != 0 {
+			t.Errorf("w=%v: ParseASN1BitString(%x) got %v, %v",
+				w, got[2:], p, err)
+		}
+	}
+
+	// BER permits nonzero unused bits, which parsing clears
+	p, err := ParseASN1BitString([]byte{0x04, 0xff})
+	if err != nil || p.Len() != 4 || p.Bytes()[0] != 0xf0 {
+		t.Errorf("ParseASN1BitString(04ff): got %v, %v", p, err)
+	}
+	for _, bad := range [][]byte{{}, {0x08, 0x00}, {0x01}} {
+		if _, err := ParseASN1BitString(bad); err != ErrInvalidCode {
+			t.Errorf("ParseASN1BitString(%x): got %v, want %v",
+				bad, err, ErrInvalidCode)
+		}
+	}
+}
@@ -32,31 +32,33 @@ func beNorm(b []byte, o int) ([]byte, int) {
 // Returns the full byte slice after growing it if needed,
 // and the normalized slice and offset of the field within the full size.
 func beGrow(b []byte, o, w int) ([]byte, []byte, int) {
-	b = Grow(b, (o + w) >> 3)
+	b = Grow(b, (o + w + 7) >> 3)
 	xb, xo := beNorm(b, o)
 	return b, xb, xo
 }
 
 // Get the next 64 bits from slice b at bit offset o (0-7).
 // Returns the byte slice and bit offset just past the returned bits.
+// The common byte-aligned case is kept small enough for the compiler
+// to inline into tight decoding loops,
+// with the unaligned case split out into beGet64u.
 func beGet64(b []byte, o int) ([]byte, int, uint64) {
+	if o != 0 || smallFootprint {
+		return beGet64u(b, o)
+	}
+	return b[8:], 0, binary.BigEndian.Uint64(b)	// touches only 8 bytes
+}
 
-	// get next 8 bytes into a uint64
-	v :=	uint64(b[0]) << 56 |
-		uint64(b[1]) << 48 |
-		uint64(b[2]) << 40 |
-		uint64(b[3]) << 32 |
-		uint64(b[4]) << 24 |
-		uint64(b[5]) << 16 |
-		uint64(b[6]) << 8 |
-		uint64(b[7])
-	b = b[8:]
+// Get the next 64 bits from slice b at a bit offset o that may be unaligned.
+func beGet64u(b []byte, o int) ([]byte, int, uint64) {
 
-	if o == 0 {		// byte-aligned: touches only 8 bytes
-		return b, o, v
+	if smallFootprint {	// use the compact general-purpose loop
+		return beGetN(b, o, 64)
 	}
 
 	// not byte-aligned: touches 9 bytes total
+	v := binary.BigEndian.Uint64(b)
+	b = b[8:]
 	return b, o, (v << o) | uint64(b[0]) >> (8-o)
 }
 
@@ -68,8 +70,12 @@ func beGet(b []byte, o, n int) ([]byte, int, uint64) {
 	if n >= 64 {			// get a full uint64
 		return beGet64(b, o)
 	} 
+	return beGetN(b, o, n)
+}
 
-	// Get a partial uint64 a few bits at a time
+// Get n bits, where n is at most 64, from slice b at bit offset o (0-7),
+// a few bits at a time.
+func beGetN(b []byte, o, n int) ([]byte, int, uint64) {
 	v := uint64(0)
 	for n > 0 {
 		if o == 0 {		// currently byte-aligned
@@ -101,33 +107,34 @@ func beGet(b []byte, o, n int) ([]byte, int, uint64) {
 }
 
 // Put 64 bits into slice b at offset o, which must be in the range 0-7.
+// The common byte-aligned case is a single 8-byte store,
+// with the unaligned case kept out of line in bePut64u
+// so that this leaf stays within the compiler's inlining budget.
 func bePut64(b []byte, o int, v uint64) ([]byte, int) {
+	if o != 0 || smallFootprint {
+		return bePut64u(b, o, v), o
+	}
+	binary.BigEndian.PutUint64(b, v)	// touches only 8 bytes
+	return b[8:], 0
+}
+
+// Put 64 bits into slice b at a bit offset o that may be unaligned.
+// Returns the byte slice just past the put bits; the bit offset is unchanged.
+//go:noinline
+func bePut64u(b []byte, o int, v uint64) []byte {
 
-	if o == 0 {		// currently byte-aligned
-		b[0] = byte(v >> 56)
-		b[1] = byte(v >> 48)
-		b[2] = byte(v >> 40)
-		b[3] = byte(v >> 32)
-		b[4] = byte(v >> 24)
-		b[5] = byte(v >> 16)
-		b[6] = byte(v >> 8)
-		b[7] = byte(v)
-		return b[8:], o
+	if smallFootprint {	// use the compact general-purpose loop
+		b, _ = bePutN(b, o, 64, v)
+		return b
 	}
 
 	// not byte-aligned: touches 9 bytes total
 	r := 8-o		// remaining bits in the current byte
+	t := b[8] & (0xff >> o)	// save the bits following the put bits
 	b[0] = (b[0] &^ (0xff >> o)) | byte(v >> (56+o))
-	v <<= r			// shift rest of v into byte-alignment
-	b[1] = byte(v >> 56)
-	b[2] = byte(v >> 48)
-	b[3] = byte(v >> 40)
-	b[4] = byte(v >> 32)
-	b[5] = byte(v >> 24)
-	b[6] = byte(v >> 16)
-	b[7] = byte(v >> 8)
-	b[8] = byte(v) | (b[8] & (byte(0xff) >> o))
-	return b[8:], o
+	binary.BigEndian.PutUint64(b[1:], v << r)
+	b[8] |= t
+	return b[8:]
 }
 
 // Put n or a maximum of 64 bits into slice b at bit offset o (0-7).
@@ -138,8 +145,12 @@ func bePut(b []byte, o, n int, v uint64) ([]byte, int) {
 	if n >= 64 {			// put a full uint64
 		return bePut64(b, o, v)
 	}
+	return bePutN(b, o, n, v)
+}
 
-	// Put a partial uint64 a few bits at a time
+// Put n bits, where n is at most 64, into slice b at bit offset o (0-7),
+// a few bits at a time.
+func bePutN(b []byte, o, n int, v uint64) ([]byte, int) {
 	for n > 0 {
 		if o == 0 {		// currently byte-aligned
 			if n >= 8 {	// put the next full byte
@@ -171,8 +182,24 @@ func bePut(b []byte, o, n int, v uint64) ([]byte, int) {
 }
 
 
+// beCopy copies w bits from slice xb at bit offset xo (0-7)
+// into slice zb at bit offset zo (0-7),
+// and returns the slices and offsets just past the copied bits.
 func beCopy(zb, xb []byte, zo, xo, w int) ([]byte, []byte, int, int) {
 	var v uint64
+	if zo == xo && w >= 64 {
+		// Same alignment: complete the current byte,
+		// then copy the whole bytes with the builtin copy
+		if h := 8 - zo; zo != 0 {
+			xb, xo, v = beGet(xb, xo, h)
+			zb, zo = bePut(zb, zo, h, v)
+			w -= h
+		}
+		n := w >> 3
+		copy(zb[:n], xb[:n])
+		zb, xb = zb[n:], xb[n:]
+		w &= 7
+	}
 	for w >= 64 {
 		xb, xo, v = beGet64(xb, xo)
 		zb, zo = bePut64(zb, zo, v)
@@ -183,6 +210,40 @@ func beCopy(zb, xb []byte, zo, xo, w int) ([]byte, []byte, int, int) {
 	return zb, xb, zo, xo
 }
 
+// beMove moves w bits in b from offset src to offset dst,
+// 64 bits at a time, correctly handling overlapping ranges
+// by copying from the end when moving toward higher offsets.
+func beMove(b []byte, dst, src, w int) {
+	var v uint64
+	for w > 0 {
+		n := w
+		if n > 64 {
+			n = 64
+		}
+		s, d := src, dst
+		if dst > src {		// move the last n bits first
+			s, d = src + w - n, dst + w - n
+		} else {
+			src, dst = src + n, dst + n
+		}
+		sb, so := beNorm(b, s)
+		_, _, v = beGet(sb, so, n)
+		db, do := beNorm(b, d)
+		bePut(db, do, n, v)
+		w -= n
+	}
+}
+
+// beFill sets w bits starting at offset o in b to the bits of v,
+// which must be all zeros or all ones.
+func beFill(b []byte, o, w int, v uint64) ([]byte, int) {
+	for w >= 64 {
+		b, o = bePut64(b, o, v)
+		w -= 64
+	}
+	return bePut(b, o, w, v)
+}
+
 
 // Copy copies a bit-field of width bits starting at offset xofs in x
 // into a field of the same width starting at offset zofs in z,
@@ -226,24 +287,87 @@ func (be BigEndianOrder) Uint32(x []byte, xofs int) uint32 {
 
 // Uint64 extracts a uint64 starting at bit position xofs from the left of x.
 func (be BigEndianOrder) Uint64(x []byte, xofs int) uint64 {
-	return uint64(be.get(x, xofs, 64))
+	if xofs & 7 != 0 || smallFootprint {
+		return be.get(x, xofs, 64)
+	}
+	return binary.BigEndian.Uint64(x[xofs >> 3:])	// byte-aligned fast path
 }
 
+// Uint extracts an unsigned integer w bits wide, where w is at most 64,
+// starting at bit position xofs from the left of x.
+// Panics with ErrOutOfRange if w exceeds 64.
+func (be BigEndianOrder) Uint(x []byte, xofs, w int) uint64 {
+	if w > 64 {
+		panic(ErrOutOfRange)
+	}
+	return be.get(x, xofs, w)
+}
 
-// SetBits setsw all bits in a bit-field of width bits
-// starting at offset zofs in z to the same bit value b.
-// Copies z and returns a new slice if z is null or not large enough.
+// Uint128 extracts a 128-bit unsigned integer
+// starting at bit position xofs from the left of x,
+// returning its most- and least-significant 64 bits.
+func (be BigEndianOrder) Uint128(x []byte, xofs int) (hi, lo uint64) {
+	xb, xo := beNorm(x, xofs)
+	xb, xo, hi = beGet64(xb, xo)
+	_, _, lo = beGet64(xb, xo)
+	return hi, lo
+}
+
+// Words extracts an unsigned integer w bits wide, of any width,
+// starting at bit position xofs from the left of x,
+// into the slice dst as (w+63)/64 words, most-significant word first.
+// The value is right-aligned, so the first word holds the leading
+// w mod 64 bits of the field, or 64 bits if w is a multiple of 64,
+// and each following word holds the next 64 bits.
+// Copies dst and returns a new slice if dst is nil or not large enough.
+func (be BigEndianOrder) Words(x []byte, xofs, w int, dst []uint64) []uint64 {
+	n := (w + 63) >> 6
+	if len(dst) < n {
+		dst = make([]uint64, n)
+	}
+	if n == 0 {
+		return dst
+	}
+	xb, xo := beNorm(x, xofs)
+	xb, xo, dst[0] = beGet(xb, xo, w - (n-1) * 64)
+	for i := 1; i < n; i++ {
+		xb, xo, dst[i] = beGet64(xb, xo)
+	}
+	return dst
+}
+
+// Bits extracts a bit-field of width w bits starting at offset xofs in x
+// into a destination slice just large enough to hold it,
+// left- or right-aligned according to align,
+// with all bits in the destination outside the field cleared to zero.
+// Returns z[:(w+7)/8], or a new slice if z is not large enough.
+// The slices x and z must not overlap.
 //
-//func (_ BigEndianOrder) SetBits(z []byte, zofs, bits int, bit uint) []byte {
-//	var zp bePos
-//	z = zp.Grow(z, zofs, bits)
-//	v := ^(uint64(bit & 1) - 1)	// v = all zero bits or all one bits
-//	for bits >= 64 {
-//		zp.Put(64, v)
-//	}
-//	zp.Put(bits, v)
-//	return z
-//}
+func (be BigEndianOrder) Bits(z, x []byte, xofs, w int, align Align) []byte {
+	n := (w + 7) >> 3
+	z = Grow(z, n)[:n]
+	for i := range z {
+		z[i] = 0
+	}
+	return be.Copy(z, x, alignOfs(n, w, align), xofs, w)
+}
+
+
+// SetBits sets all bits in a bit-field of width w bits
+// starting at offset zofs in z to the same bit value b (0 or 1),
+// and returns z.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+//
+func (_ BigEndianOrder) SetBits(z []byte, zofs, w int, b uint) []byte {
+	if b > 1 {
+		panic(ErrBadBitValue)
+	}
+	z, zb, zo := beGrow(z, zofs, w)
+	beFill(zb, zo, w, -uint64(b))	// all zero bits or all one bits
+	return z
+}
 
 // Put up to w bits into slice zb at bit offset zofs.
 func (_ BigEndianOrder) put(z []byte, zofs, w int, v uint64) []byte {
@@ -284,7 +408,57 @@ func (be BigEndianOrder) PutUint32(z []byte, zofs int, v uint32) []byte {
 // Copies z and returns a new slice if z is null or not large enough.
 //
 func (be BigEndianOrder) PutUint64(z []byte, zofs int, v uint64) []byte {
-	return be.put(z, zofs, 64, uint64(v))
+	if zofs & 7 != 0 || zofs >> 3 > len(z) - 8 || smallFootprint {
+		return be.put(z, zofs, 64, v)
+	}
+	binary.BigEndian.PutUint64(z[zofs >> 3:], v)	// byte-aligned fast path
+	return z
+}
+
+// PutUint sets the unsigned integer w bits wide starting at zofs in slice z
+// to the least-significant w bits of v, where w is at most 64.
+// Copies z and returns a new slice if z is null or not large enough.
+// Panics with ErrOutOfRange if w exceeds 64.
+//
+func (be BigEndianOrder) PutUint(z []byte, zofs, w int, v uint64) []byte {
+	if w > 64 {
+		panic(ErrOutOfRange)
+	}
+	return be.put(z, zofs, w, v)
+}
+
+// PutUint128 sets the 128-bit unsigned integer starting at zofs in slice z
+// to the value with most- and least-significant 64 bits hi and lo.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (be BigEndianOrder) PutUint128(z []byte, zofs int, hi, lo uint64) []byte {
+	z, zb, zo := beGrow(z, zofs, 128)
+	zb, zo = bePut64(zb, zo, hi)
+	bePut64(zb, zo, lo)
+	return z
+}
+
+// PutWords sets the unsigned integer w bits wide, of any width,
+// starting at zofs in slice z to the value held in words v,
+// most-significant word first, in the layout that Words produces.
+// Bits of the first word beyond the leading w mod 64 bits are ignored.
+// Copies z and returns a new slice if z is null or not large enough.
+// Panics with ErrLengthMismatch if v does not have exactly (w+63)/64 words.
+//
+func (be BigEndianOrder) PutWords(z []byte, zofs, w int, v []uint64) []byte {
+	n := (w + 63) >> 6
+	if len(v) != n {
+		panic(ErrLengthMismatch)
+	}
+	if n == 0 {
+		return z
+	}
+	z, zb, zo := beGrow(z, zofs, w)
+	zb, zo = bePut(zb, zo, w - (n-1) * 64, v[0])
+	for _, vw := range v[1:] {
+		zb, zo = bePut64(zb, zo, vw)
+	}
+	return z
 }
 
 // PutBytes writes the contents of byte b slice into slice z at bit offset zofs.
@@ -304,6 +478,87 @@ func (be BigEndianOrder) PutBytes(z []byte, zofs int, b []byte) []byte {
 	return z
 }
 
+// PutBits inserts a bit-field of width w bits, left- or right-aligned
+// in slice x according to align, into slice z at bit offset zofs.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+// Panics with ErrShortBuffer if x is too short to hold w bits.
+//
+func (be BigEndianOrder) PutBits(z []byte, zofs int, x []byte, w int,
+		align Align) []byte {
+	return be.Copy(z, x, zofs, alignOfs(len(x), w, align), w)
+}
+
+
+// AppendBit appends bit value v to the bit string of n bits in b,
+// and returns the grown slice and its new length n+1 in bits.
+// The slice b holds the bit string in its first (n+7)/8 bytes,
+// as a previous Append method returns it;
+// any bytes beyond these are discarded.
+// Bits following the end of the bit string in its last byte
+// are kept zero, so the slice may be used directly as padded output.
+// Grows the slice as needed, as Grow does.
+//
+func (be BigEndianOrder) AppendBit(b []byte, n int, v uint) ([]byte, int) {
+	return be.AppendUint(b, n, 1, uint64(v))
+}
+
+// AppendUint8 appends the uint8 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (be BigEndianOrder) AppendUint8(b []byte, n int, v uint8) ([]byte, int) {
+	return be.AppendUint(b, n, 8, uint64(v))
+}
+
+// AppendUint16 appends the uint16 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (be BigEndianOrder) AppendUint16(b []byte, n int, v uint16) ([]byte, int) {
+	return be.AppendUint(b, n, 16, uint64(v))
+}
+
+// AppendUint32 appends the uint32 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (be BigEndianOrder) AppendUint32(b []byte, n int, v uint32) ([]byte, int) {
+	return be.AppendUint(b, n, 32, uint64(v))
+}
+
+// AppendUint64 appends the uint64 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (be BigEndianOrder) AppendUint64(b []byte, n int, v uint64) ([]byte, int) {
+	return be.AppendUint(b, n, 64, v)
+}
+
+// AppendUint appends the least-significant w bits of v,
+// where w is at most 64, to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+// Panics with ErrOutOfRange if w exceeds 64.
+//
+func (be BigEndianOrder) AppendUint(b []byte, n, w int, v uint64) ([]byte, int) {
+	if w > 64 {
+		panic(ErrOutOfRange)
+	}
+	b = appendGrow(b, n, w)
+	zb, zo := beNorm(b, n)
+	bePut(zb, zo, w, v)
+	return b, n + w
+}
+
+// AppendBits appends the bit-field of width w bits starting at offset xofs
+// in x to the bit string of n bits in b, as AppendBit does,
+// and returns the grown slice and its length in bits.
+// The slices x and b must not overlap.
+//
+func (be BigEndianOrder) AppendBits(b []byte, n int, x []byte, xofs, w int) ([]byte, int) {
+	b = appendGrow(b, n, w)
+	zb, zo := beNorm(b, n)
+	xb, xo := beNorm(x, xofs)
+	beCopy(zb, xb, zo, xo, w)
+	return b, n + w
+}
+
 
 // RotateLeft sets slice z to the contents of x rotated left by rot bits.
 // To rotate right, pass a negative value for rot.
@@ -361,8 +616,136 @@ func (be BigEndianOrder) RotateLeft(z, x []byte, rot int) []byte {
 }
 
 
+// ShiftLeft sets slice z to the contents of x shifted left by s bits,
+// toward lower offsets, filling the vacated bits on the right with zeros,
+// and returns z[:len(x)].
+// To shift right, pass a negative value for s.
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place shifts,
+// but must not otherwise overlap.
+//
+func (be BigEndianOrder) ShiftLeft(z, x []byte, s int) []byte {
+	if s < 0 {
+		return be.ShiftRight(z, x, -s)
+	}
+	l := len(x)
+	z = Grow(z, l)[:l]
+	q, r := s >> 3, uint(s & 7)
+	if q >= l {
+		q = l
+	}
+
+	// Shift whole bytes, then the remaining bits within bytes
+	copy(z, x[q:])
+	for i := l-q; i < l; i++ {
+		z[i] = 0
+	}
+	if r != 0 && l > 0 {
+		for i := 0; i < l-1; i++ {
+			z[i] = z[i] << r | z[i+1] >> (8-r)
+		}
+		z[l-1] <<= r
+	}
+	return z
+}
+
+// ShiftRight sets slice z to the contents of x shifted right by s bits,
+// toward higher offsets, filling the vacated bits on the left with zeros,
+// and returns z[:len(x)].
+// To shift left, pass a negative value for s.
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place shifts,
+// but must not otherwise overlap.
+//
+func (be BigEndianOrder) ShiftRight(z, x []byte, s int) []byte {
+	if s < 0 {
+		return be.ShiftLeft(z, x, -s)
+	}
+	l := len(x)
+	z = Grow(z, l)[:l]
+	q, r := s >> 3, uint(s & 7)
+	if q >= l {
+		q = l
+	}
+
+	// Shift whole bytes, then the remaining bits within bytes
+	copy(z[q:], x[:l-q])
+	for i := 0; i < q; i++ {
+		z[i] = 0
+	}
+	if r != 0 && l > 0 {
+		for i := l-1; i > 0; i-- {
+			z[i] = z[i] >> r | z[i-1] << (8-r)
+		}
+		z[0] >>= r
+	}
+	return z
+}
+
+
+// BinaryToGray sets slice z to the reflected binary Gray code of x,
+// treating the whole slice as one unsigned integer with the first bit most significant,
+// and returns z[:len(x)].
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place conversion,
+// but must not otherwise overlap.
+func (be BigEndianOrder) BinaryToGray(z, x []byte) []byte {
+	l := len(x)
+	z = Grow(z, l)[:l]
+	be.Field(z, 0, l * 8).BinaryToGray(be.Field(x, 0, l * 8))
+	return z
+}
+
+// GrayToBinary sets slice z to the binary value of the Gray code in x,
+// inverting BinaryToGray, and returns z[:len(x)].
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place conversion,
+// but must not otherwise overlap.
+func (be BigEndianOrder) GrayToBinary(z, x []byte) []byte {
+	l := len(x)
+	z = Grow(z, l)[:l]
+	be.Field(z, 0, l * 8).GrayToBinary(be.Field(x, 0, l * 8))
+	return z
+}
+
+
+// SwapRanges exchanges the contents of the two non-overlapping ranges
+// of w bits starting at offsets ofs1 and ofs2 in z, in place,
+// 64 bits at a time.
+// All other bits within z are left unmodified.
+// Panics with ErrOutOfRange if the ranges overlap,
+// or with ErrShortBuffer if either range extends past the end of z.
+func (be BigEndianOrder) SwapRanges(z []byte, ofs1, ofs2, w int) {
+	if ofs1 < 0 || ofs2 < 0 || w < 0 ||
+			(w > 0 && ofs1 < ofs2 + w && ofs2 < ofs1 + w && ofs1 != ofs2) {
+		panic(ErrOutOfRange)
+	}
+	if ofs1 + w > len(z) * 8 || ofs2 + w > len(z) * 8 {
+		panic(ErrShortBuffer)
+	}
+	if ofs1 == ofs2 {
+		return
+	}
+
+	ab, ao := beNorm(z, ofs1)
+	bb, bo := beNorm(z, ofs2)
+	var av, bv uint64
+	for w >= 64 {
+		_, _, av = beGet64(ab, ao)
+		_, _, bv = beGet64(bb, bo)
+		ab, ao = bePut64(ab, ao, bv)
+		bb, bo = bePut64(bb, bo, av)
+		w -= 64
+	}
+	_, _, av = beGet(ab, ao, w)
+	_, _, bv = beGet(bb, bo, w)
+	bePut(ab, ao, w, bv)
+	bePut(bb, bo, w, av)
+}
+
 // Leading counts the number of consecutive leading bits with value b
 // in slice z starting from the most-significant bit of the first byte.
+// Panics with ErrBadBitValue if b is not 0 or 1.
 func (be BigEndianOrder) Leading(z []byte, b uint) (n int) {
 	switch b {
 	case 0:
@@ -374,19 +757,20 @@ func (be BigEndianOrder) Leading(z []byte, b uint) (n int) {
 		}
 	case 1:
 		for _, v := range(z) {
-			if v != 0 {
+			if v != 0xff {
 				return n + bits.LeadingZeros8(^v)
 			}
 			n += 8
 		}
 	default:
-		panic("Count: invalid bit value")
+		panic(ErrBadBitValue)
 	}
 	return n
 }
 
 // Trailing counts the number of consecutive trailing bits with value b
 // in slice z starting from the least-significant bit of the last byte.
+// Panics with ErrBadBitValue if b is not 0 or 1.
 func (be BigEndianOrder) Trailing(z []byte, b uint) (n int) {
 	switch b {
 	case 0:
@@ -406,11 +790,124 @@ func (be BigEndianOrder) Trailing(z []byte, b uint) (n int) {
 			n += 8
 		}
 	default:
-		panic("Count: invalid bit value")
+		panic(ErrBadBitValue)
 	}
 	return n
 }
 
+// LeadingAt counts the number of consecutive leading bits with value b
+// in the w-bit range starting at offset ofs in z,
+// scanning toward higher offsets from the most-significant bit at ofs.
+// Returns w if all bits in the range have value b.
+// Panics with ErrBadBitValue if b is not 0 or 1,
+// or with ErrOutOfRange or ErrShortBuffer if the range is not within z.
+func (be BigEndianOrder) LeadingAt(z []byte, ofs, w int, b uint) (n int) {
+	checkRange(len(z), ofs, w)
+	m := bitMask(b)
+	zb, zo := beNorm(z, ofs)
+	var v uint64
+	for w - n >= 64 {
+		zb, zo, v = beGet64(zb, zo)
+		if v ^= m; v != 0 {
+			return n + bits.LeadingZeros64(v)
+		}
+		n += 64
+	}
+	if r := w - n; r > 0 {
+		zb, zo, v = beGet(zb, zo, r)
+		if v ^= m & (1 << r - 1); v != 0 {
+			return n + bits.LeadingZeros64(v) - (64 - r)
+		}
+	}
+	return w
+}
+
+// TrailingAt counts the number of consecutive trailing bits with value b
+// in the w-bit range starting at offset ofs in z,
+// scanning toward lower offsets from the least-significant bit at ofs+w-1.
+// Returns w if all bits in the range have value b.
+// Panics with ErrBadBitValue if b is not 0 or 1,
+// or with ErrOutOfRange or ErrShortBuffer if the range is not within z.
+func (be BigEndianOrder) TrailingAt(z []byte, ofs, w int, b uint) (n int) {
+	checkRange(len(z), ofs, w)
+	m := bitMask(b)
+	var v uint64
+	for w - n >= 64 {
+		zb, zo := beNorm(z, ofs + w - n - 64)
+		_, _, v = beGet64(zb, zo)
+		if v ^= m; v != 0 {
+			return n + bits.TrailingZeros64(v)
+		}
+		n += 64
+	}
+	if r := w - n; r > 0 {
+		zb, zo := beNorm(z, ofs)
+		_, _, v = beGet(zb, zo, r)
+		if v ^= m & (1 << r - 1); v != 0 {
+			return n + bits.TrailingZeros64(v)
+		}
+	}
+	return w
+}
+
+// CommonPrefixLen returns the number of leading bits that are equal
+// in the bit strings starting at offset xofs in x and offset yofs in y,
+// comparing at most maxBits bits.
+// Both x and y must contain at least maxBits bits past their offsets.
+// The comparison proceeds 64 bits at a time,
+// locating the first difference by XOR and leading-zero count.
+func (be BigEndianOrder) CommonPrefixLen(x, y []byte, xofs, yofs, maxBits int) int {
+	xb, xo := beNorm(x, xofs)
+	yb, yo := beNorm(y, yofs)
+	var xv, yv uint64
+	n := 0
+	for maxBits - n >= 64 {
+		xb, xo, xv = beGet64(xb, xo)
+		yb, yo, yv = beGet64(yb, yo)
+		if d := xv ^ yv; d != 0 {
+			return n + bits.LeadingZeros64(d)
+		}
+		n += 64
+	}
+	r := maxBits - n	// remaining bits to compare, 0-63
+	xb, xo, xv = beGet(xb, xo, r)
+	yb, yo, yv = beGet(yb, yo, r)
+	if d := xv ^ yv; d != 0 {
+		return n + bits.LeadingZeros64(d) - (64 - r)
+	}
+	return maxBits
+}
+
+// String returns "BigEndian", like the standard binary.ByteOrder values.
+func (_ BigEndianOrder) String() string {
+	return "BigEndian"
+}
+
+// LSBFirst reports whether bits are numbered from the least-significant end,
+// which for BigEndianOrder is false: the first bit of each byte is its most significant.
+// Code that consumes values bit by bit in stream order,
+// such as CRC computation, uses it to decide whether to reverse values.
+func (_ BigEndianOrder) LSBFirst() bool {
+	return false
+}
+
+// ByteOrder returns the standard binary.ByteOrder with the same byte order,
+// for use in byte-aligned encoding code from the standard library.
+// BigEndianOrder cannot implement binary.ByteOrder itself,
+// because its bit-offset Uint16 and related methods take
+// different parameters than the byte-aligned methods of that interface.
+func (_ BigEndianOrder) ByteOrder() binary.ByteOrder {
+	return binary.BigEndian
+}
+
+// AppendByteOrder returns the standard binary.AppendByteOrder
+// with the same byte order.
+func (_ BigEndianOrder) AppendByteOrder() binary.AppendByteOrder {
+	return binary.BigEndian
+}
+
+// Field returns a Field referring to the big-endian bit field
+// starting at bit offset ofs in buf and extending for width bits.
 func (be BigEndianOrder) Field(buf []byte, ofs, width int) Field {
 	return (&BigEndianField{}).Init(buf, ofs, width)
 }
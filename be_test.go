@@ -2,6 +2,7 @@ package bytebits
 
 import (
 	"bytes"
+	"math/rand"
 	"testing"
 	"encoding/hex"
 )
@@ -71,3 +72,394 @@ func TestRotateLeft(t *testing.T) {
 	}
 }
 
+
+func TestCommonPrefixLen(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]byte, 40)
+	y := make([]byte, 40)
+	for i := 0; i < 1000; i++ {
+		rnd.Read(x)
+		xofs, yofs := rnd.Intn(64), rnd.Intn(64)
+		maxBits := rnd.Intn(200)
+
+		// Make y share a random-length prefix with x
+		rnd.Read(y)
+		same := rnd.Intn(maxBits + 1)
+		BigEndian.Copy(y, x, yofs, xofs, same)
+
+		want := 0
+		for want < maxBits && BigEndian.Bit(x, xofs+want) ==
+				BigEndian.Bit(y, yofs+want) {
+			want++
+		}
+		got := BigEndian.CommonPrefixLen(x, y, xofs, yofs, maxBits)
+		if got != want {
+			t.Errorf("CommonPrefixLen(%v, %v, %v) = %v, want %v",
+				xofs, yofs, maxBits, got, want)
+		}
+	}
+}
+
+func TestCopyBits(t *testing.T) {
+	x := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	// Copying into a nil slice should allocate just enough bytes
+	z := CopyBits(BigEndian, nil, x, 3, 4, 13)	// 1110 1010 1101 1
+	if want := []byte{0x1d, 0x5b}; !bytes.Equal(z, want) {
+		t.Errorf("CopyBits into nil: %x, want %x", z, want)
+	}
+
+	// Other bits of the destination must be left unmodified
+	z = []byte{0xff, 0xff, 0xff}
+	z = CopyBits(BigEndian, z, x, 5, 0, 8)
+	if want := []byte{0xfe, 0xf7, 0xff}; !bytes.Equal(z, want) {
+		t.Errorf("CopyBits: %x, want %x", z, want)
+	}
+}
+
+func TestUint64(t *testing.T) {
+	const v uint64 = 0x0123456789abcdef
+	for ofs := 0; ofs < 16; ofs++ {
+		z := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff}
+		z = BigEndian.PutUint64(z, ofs, v)
+		if len(z) != 11 {
+			t.Errorf("PutUint64 at %v grew slice to %v", ofs, len(z))
+		}
+		if got := BigEndian.Uint64(z, ofs); got != v {
+			t.Errorf("Uint64 at %v: %x, want %x", ofs, got, v)
+		}
+		if n := Count(z, 1); n != 88 - 64 + 32 {
+			t.Errorf("PutUint64 at %v disturbed surrounding bits: %x",
+				ofs, z)
+		}
+	}
+}
+
+func TestCopySameAlignment(t *testing.T) {
+	x := make([]byte, 100)
+	rand.Read(x)
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, ofs := range []int{0, 3, 8, 13} {
+			for _, w := range []int{63, 64, 65, 100, 512, 700} {
+				z := bytes.Repeat([]byte{0xa5}, 100)
+				zofs := ofs + 16
+				order.Copy(z, x, zofs, ofs, w)
+				for i := 0; i < len(z) * 8; i++ {
+					want := order.Bit([]byte{0xa5}, i & 7)
+					if i >= zofs && i < zofs + w {
+						want = order.Bit(x, i - 16)
+					}
+					if order.Bit(z, i) != want {
+						t.Fatalf("%v Copy of %v bits at %v: bit %v wrong",
+							order, w, ofs, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkCopyAligned(b *testing.B) {
+	x := make([]byte, 64 << 10)
+	z := make([]byte, len(x))
+	b.SetBytes(int64(len(x)))
+	for i := 0; i < b.N; i++ {
+		BigEndian.Copy(z, x, 3, 3, len(x) * 8 - 8)
+	}
+}
+
+func TestAppendUint(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		// Stale bytes in spare capacity must not leak into the padding
+		b := append(make([]byte, 0, 64), 0x5a, 0xff, 0xff)[:1]
+		n := 8
+		w := order.NewAppendWriter([]byte{0x5a})
+		b, n = order.AppendBit(b, n, 1)
+		w.WriteBits(1, 1)
+		b, n = order.AppendUint8(b, n, 0xc3)
+		w.WriteBits(8, 0xc3)
+		b, n = order.AppendUint16(b, n, 0x1234)
+		w.WriteBits(16, 0x1234)
+		b, n = order.AppendUint(b, n, 5, 0x15)
+		w.WriteBits(5, 0x15)
+		b, n = order.AppendUint32(b, n, 0xdeadbeef)
+		w.WriteBits(32, 0xdeadbeef)
+		b, n = order.AppendUint64(b, n, 0x0123456789abcdef)
+		w.WriteBits(64, 0x0123456789abcdef)
+		b, n = order.AppendBits(b, n, testBits, 3, 150)
+		for ofs := 3; ofs < 153; ofs += 50 {
+			w.WriteBits(50, order.Uint(testBits, ofs, 50))
+		}
+		if n != w.Len() || !bytes.Equal(b, w.Bytes()) {
+			t.Errorf("%v Append: got %x of %v bits, want %x of %v bits",
+				order, b, n, w.Bytes(), w.Len())
+		}
+	}
+	if panicValue(func() { BigEndian.AppendUint(nil, 0, 65, 0) }) !=
+			ErrOutOfRange {
+		t.Errorf("AppendUint of 65 bits did not panic")
+	}
+}
+
+func BenchmarkUint64Aligned(b *testing.B) {
+	x := make([]byte, 1024)
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		for ofs := 0; ofs < len(x)*8; ofs += 64 {
+			s += BigEndian.Uint64(x, ofs)
+		}
+	}
+	_ = s
+}
+
+func BenchmarkPutUint64Aligned(b *testing.B) {
+	z := make([]byte, 1024)
+	for i := 0; i < b.N; i++ {
+		for ofs := 0; ofs < len(z)*8; ofs += 64 {
+			BigEndian.PutUint64(z, ofs, uint64(ofs))
+		}
+	}
+}
+
+// panicValue calls f and returns the value it panics with, if any.
+func panicValue(f func()) (v interface{}) {
+	defer func() { v = recover() }()
+	f()
+	return nil
+}
+
+func TestTypedPanics(t *testing.T) {
+	if v := panicValue(func() { And(nil, []byte{1}, []byte{1, 2}) });
+			v != ErrLengthMismatch {
+		t.Errorf("And with mismatched lengths panicked with %v", v)
+	}
+	if v := panicValue(func() { Count([]byte{1}, 2) }); v != ErrBadBitValue {
+		t.Errorf("Count with bad bit value panicked with %v", v)
+	}
+	if v := panicValue(func() { BigEndian.Field(make([]byte, 2), 9, 8) });
+			v != ErrShortBuffer {
+		t.Errorf("Field beyond buffer panicked with %v", v)
+	}
+	if v := panicValue(func() { BigEndian.Uint(make([]byte, 9), 0, 65) });
+			v != ErrOutOfRange {
+		t.Errorf("Uint wider than 64 bits panicked with %v", v)
+	}
+}
+
+func TestGenericUint(t *testing.T) {
+	type port uint16
+	x := []byte{0xde, 0xad, 0xbe, 0xef, 0x01}
+	if v := GetUint[uint8](BigEndian, x, 4); v != 0xea {
+		t.Errorf("GetUint[uint8] = %x", v)
+	}
+	if v := GetUint[port](BigEndian, x, 4); v != 0xeadb {
+		t.Errorf("GetUint[port] = %x", v)
+	}
+	if v := GetUint[uint32](BigEndian, x, 4); v != 0xeadbeef0 {
+		t.Errorf("GetUint[uint32] = %x", v)
+	}
+
+	z := PutUint(BigEndian, nil, 4, port(0xabcd))
+	if want := []byte{0x0a, 0xbc, 0xd0}; !bytes.Equal(z, want) {
+		t.Errorf("PutUint(port) = %x, want %x", z, want)
+	}
+	z = PutUint(BigEndian, nil, 0, uint64(0x0123456789abcdef))
+	if v := GetUint[uint64](BigEndian, z, 0); v != 0x0123456789abcdef {
+		t.Errorf("GetUint[uint64] = %x", v)
+	}
+}
+
+func TestSwapRanges(t *testing.T) {
+	for _, w := range []int{0, 1, 13, 64, 100, 200} {
+		for _, d := range []int{0, 3, 64} {	// gap between ranges
+			z := make([]byte, (2*w + d + 20) >> 3 + 1)
+			rand.Read(z)
+			orig := append([]byte(nil), z...)
+			o1, o2 := 5, 5 + w + d
+			BigEndian.SwapRanges(z, o2, o1, w)
+
+			want := BigEndian.Copy(append([]byte(nil), orig...),
+					orig, o1, o2, w)
+			want = BigEndian.Copy(want, orig, o2, o1, w)
+			if !bytes.Equal(z, want) {
+				t.Errorf("SwapRanges w=%v gap=%v: got %x, want %x",
+					w, d, z, want)
+			}
+		}
+	}
+	if v := panicValue(func() {
+		BigEndian.SwapRanges(make([]byte, 4), 0, 8, 9)
+	}); v != ErrOutOfRange {
+		t.Errorf("SwapRanges of overlapping ranges: got %v", v)
+	}
+}
+
+func TestWords(t *testing.T) {
+	x := make([]byte, 40)
+	rand.Read(x)
+	for _, w := range []int{0, 1, 64, 65, 128, 200} {
+		v := BigEndian.Words(x, 5, w, nil)
+		if len(v) != (w + 63) / 64 {
+			t.Fatalf("Words w=%v: got %v words", w, len(v))
+		}
+
+		// Compare with bitwise extraction of the right-aligned value
+		for i := 0; i < w; i++ {
+			j := w - 1 - i		// bit significance
+			got := uint(v[len(v) - 1 - j/64] >> (j%64)) & 1
+			if want := BigEndian.Bit(x, 5 + i); got != want {
+				t.Fatalf("Words w=%v: bit %v is %v, want %v",
+					w, i, got, want)
+			}
+		}
+
+		z := BigEndian.PutWords(nil, 3, w, v)
+		if !bytes.Equal(BigEndian.Copy(nil, z, 0, 3, w),
+				BigEndian.Copy(nil, x, 0, 5, w)) {
+			t.Errorf("PutWords w=%v: round trip failed", w)
+		}
+	}
+
+	hi, lo := BigEndian.Uint128(x, 7)
+	v := BigEndian.Words(x, 7, 128, nil)
+	if hi != v[0] || lo != v[1] {
+		t.Errorf("Uint128: got %x %x, want %x %x", hi, lo, v[0], v[1])
+	}
+	z := BigEndian.PutUint128(nil, 7, hi, lo)
+	if h, l := BigEndian.Uint128(z, 7); h != hi || l != lo {
+		t.Errorf("PutUint128: round trip failed")
+	}
+}
+
+func TestByteOrder(t *testing.T) {
+	var order BitOrder = BigEndian
+	b := order.AppendByteOrder().AppendUint32(nil, 0xdeadbeef)
+	if v := order.ByteOrder().Uint32(b); v != 0xdeadbeef ||
+			order.Uint32(b, 0) != v {
+		t.Errorf("ByteOrder mismatch: got %x", v)
+	}
+	if order.String() != "BigEndian" {
+		t.Errorf("String: got %v", order.String())
+	}
+}
+
+func TestBits(t *testing.T) {
+	src := []byte{0x12, 0x36, 0xe5}	// bits 14-18: 1 0 1 1 1
+	if b := BigEndian.Bits(nil, src, 14, 5, Right); !bytes.Equal(b, []byte{0x17}) {
+		t.Errorf("Bits Right: got %x, want 17", b)
+	}
+	if b := BigEndian.Bits(nil, src, 14, 5, Left); !bytes.Equal(b, []byte{0xb8}) {
+		t.Errorf("Bits Left: got %x, want b8", b)
+	}
+	if b := LittleEndian.Bits(nil, src, 3, 9, Left); !bytes.Equal(b, []byte{0xc2, 0x00}) {
+		t.Errorf("LittleEndian Bits Left: got %x, want c200", b)
+	}
+	if b := LittleEndian.Bits(nil, src, 3, 9, Right); !bytes.Equal(b, []byte{0x00, 0x61}) {
+		t.Errorf("LittleEndian Bits Right: got %x, want 0061", b)
+	}
+
+	// Round trips at assorted offsets, widths, and alignments
+	x := make([]byte, 20)
+	rand.Read(x)
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 7, 8, 13, 64, 77} {
+			for _, align := range []Align{Left, Right} {
+				dst := make([]byte, 16)
+				b := order.Bits(dst, x, 11, w, align)
+				if len(b) != (w+7)/8 {
+					t.Fatalf("%v Bits w=%v: got %v bytes", order, w, len(b))
+				}
+				if Count(b, 1) != Count(order.Copy(nil, x, 0, 11, w), 1) {
+					t.Errorf("%v Bits w=%v: wrong or unclean bits %x",
+						order, w, b)
+				}
+				z := order.PutBits(nil, 5, b, w, align)
+				if !bytes.Equal(order.Copy(nil, z, 0, 5, w),
+						order.Copy(nil, x, 0, 11, w)) {
+					t.Errorf("%v PutBits w=%v align=%v: round trip failed",
+						order, w, align)
+				}
+			}
+		}
+	}
+
+	defer func() {
+		if recover() != ErrShortBuffer {
+			t.Errorf("PutBits from a short slice did not panic")
+		}
+	}()
+	BigEndian.PutBits(nil, 0, []byte{0}, 9, Right)
+}
+
+func TestSetBits(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 6, 9, 64, 100} {
+			for _, ofs := range []int{0, 3, 8, 13} {
+				z := order.SetBits(make([]byte, 16), ofs, w, 1)
+				if n := Count(z, 1); n != w {
+					t.Errorf("%v SetBits(%v, %v, 1): %v bits set",
+						order, ofs, w, n)
+				}
+				if w > 0 && (order.Bit(z, ofs) != 1 ||
+						order.Bit(z, ofs+w-1) != 1) {
+					t.Errorf("%v SetBits(%v, %v, 1): wrong range %x",
+						order, ofs, w, z)
+				}
+				z = order.SetBits(Not(nil, z), ofs, w, 0)
+				if n := Count(z, 1); n != 128 - w {
+					t.Errorf("%v SetBits(%v, %v, 0): %v bits set",
+						order, ofs, w, n)
+				}
+			}
+		}
+	}
+	if z := BigEndian.SetBits(nil, 4, 8, 1); !bytes.Equal(z, []byte{0x0f, 0xf0}) {
+		t.Errorf("SetBits grow: got %x", z)
+	}
+}
+
+func TestLeadingTrailingAt(t *testing.T) {
+	// Reference implementations scanning one bit at a time
+	scan := func(order BitOrder, z []byte, i, step, w int, b uint) int {
+		for n := 0; n < w; n++ {
+			if order.Bit(z, i + n*step) != b {
+				return n
+			}
+		}
+		return w
+	}
+	z := make([]byte, 40)
+	for trial := 0; trial < 200; trial++ {
+		rand.Read(z)
+		// Plant long runs so that multi-word scans are exercised
+		BigEndian.SetBits(z, rand.Intn(160), rand.Intn(160), uint(trial & 1))
+		ofs, w := rand.Intn(100), rand.Intn(220)
+		for b := uint(0); b <= 1; b++ {
+			first, last := ofs, ofs + w - 1
+			if n, want := BigEndian.LeadingAt(z, ofs, w, b),
+					scan(BigEndian, z, first, 1, w, b); n != want {
+				t.Errorf("BigEndian.LeadingAt(%v, %v, %v): got %v, want %v",
+					ofs, w, b, n, want)
+			}
+			if n, want := BigEndian.TrailingAt(z, ofs, w, b),
+					scan(BigEndian, z, last, -1, w, b); n != want {
+				t.Errorf("BigEndian.TrailingAt(%v, %v, %v): got %v, want %v",
+					ofs, w, b, n, want)
+			}
+			if n, want := LittleEndian.LeadingAt(z, ofs, w, b),
+					scan(LittleEndian, z, last, -1, w, b); n != want {
+				t.Errorf("LittleEndian.LeadingAt(%v, %v, %v): got %v, want %v",
+					ofs, w, b, n, want)
+			}
+			if n, want := LittleEndian.TrailingAt(z, ofs, w, b),
+					scan(LittleEndian, z, first, 1, w, b); n != want {
+				t.Errorf("LittleEndian.TrailingAt(%v, %v, %v): got %v, want %v",
+					ofs, w, b, n, want)
+			}
+		}
+	}
+	if n := BigEndian.LeadingAt([]byte{0xf0, 0x0f}, 4, 8, 0); n != 8 {
+		t.Errorf("LeadingAt of zero range: got %v, want 8", n)
+	}
+}
@@ -2,6 +2,7 @@ package bytebits
 
 import (
 	"io"
+	"iter"
 	"math/bits"
 )
 
@@ -16,10 +17,16 @@ var EOF = io.EOF
 
 // Init sets the field to refer to a big-endian bit field within slice buf,
 // starting at bit offset ofs and extending for width bits.
-// The caller must ensure that the underlying slice is large enough
+// The underlying slice must be large enough
 // to contain the complete bit field specified;
-// otherwise accesses to the bit field may yield bounds check panics.
+// otherwise Init panics with ErrShortBuffer.
 func (z *BigEndianField) Init(buf []byte, ofs, width int) Field {
+	if ofs < 0 || width < 0 {
+		panic(ErrOutOfRange)
+	}
+	if ofs + width > len(buf) * 8 {
+		panic(ErrShortBuffer)
+	}
 	z.b = buf[ofs >> 3:]
 	z.o = ofs & 7
 	z.w = width
@@ -30,11 +37,24 @@ func (z *BigEndianField) Init(buf []byte, ofs, width int) Field {
 // copying buf to a new larger buffer if needed to include the bit field.
 // Returns buf or the newly-allocated buffer if it was grown.
 func (z *BigEndianField) Grow(buf []byte, ofs, width int) []byte {
-	buf = Grow(buf, (ofs+width) >> 3)
+	buf = Grow(buf, (ofs+width+7) >> 3)
 	z.Init(buf, ofs, width)
 	return buf
 }
 
+// Slice returns a new field aliasing the w-bit sub-range
+// starting at bit offset ofs within field z,
+// so that changes to either field are visible through the other.
+// The new field inherits z's padding policy.
+// Panics with ErrOutOfRange if the sub-range is not within z.
+func (z *BigEndianField) Slice(ofs, w int) Field {
+	if ofs < 0 || w < 0 || ofs + w > z.w {
+		panic(ErrOutOfRange)
+	}
+	b, o := beNorm(z.b, z.o + ofs)
+	return &BigEndianField{b, o, w, z.p}
+}
+
 // ReadBits implements the BitReader interface,
 // reading up to n bits from the start of the field, or 64 bits maximum.
 // On success, returns the bits read
@@ -53,16 +73,164 @@ func (z *BigEndianField) ReadBits(n int) (v uint64, err error) {
 	return v, nil
 }
 
-// Copy sets the contents of bit field z to that of field x,
+// ReadBitsAt implements the BitReaderAt interface,
+// reading n bits, or 64 bits maximum,
+// starting off bits from the start of the field,
+// without consuming them or otherwise modifying the field.
+// Returns an EOF error if the field ends before reading n bits,
+// or ErrOutOfRange if off is negative.
+func (z *BigEndianField) ReadBitsAt(n int, off int64) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if off < 0 {
+		return 0, ErrOutOfRange
+	}
+	if off + int64(n) > int64(z.w) {
+		return 0, EOF
+	}
+	b, o := beNorm(z.b, z.o + int(off))
+	_, _, v = beGet(b, o, n)
+	return v, nil
+}
+
+// ReadBool reads a single bit from the start of the field
+// and returns true if it is 1, shrinking the field to skip it.
+// Returns an EOF error if the field is empty.
+func (z *BigEndianField) ReadBool() (bool, error) {
+	return ReadBool(z)
+}
+
+// ReadBitsInto reads n bits from the start of the field,
+// which may be more than 64,
+// into a byte slice left-aligned as for BigEndian.Bits,
+// and shrinks the field to skip the n bits read.
+// Returns dst[:(n+7)/8], or a new slice if dst is not large enough.
+// Returns an EOF error, reading nothing,
+// if the bit field is less than n bits wide.
+func (z *BigEndianField) ReadBitsInto(dst []byte, n int) ([]byte, error) {
+	if n > z.w {
+		return dst, EOF
+	}
+	dst = BigEndian.Bits(dst, z.b, z.o, n, Left)
+	z.b, z.o = beNorm(z.b, z.o + n)
+	z.w -= n
+	return dst, nil
+}
+
+// Reader returns a BitReader that reads the contents of field z
+// from its start, without consuming or otherwise modifying z itself.
+// The reader is an independent copy of the field,
+// so multiple readers may scan the same field concurrently.
+func (z *BigEndianField) Reader() BitReader {
+	c := *z
+	return &c
+}
+
+// WriteBits implements the BitWriter interface,
+// writing the least-significant n bits of v, or 64 bits maximum,
+// to the start of the field.
+// On success, shrinks the field to skip the n bits written.
+// Returns io.ErrShortWrite if the bit field is less than n bits wide.
+func (z *BigEndianField) WriteBits(n int, v uint64) error {
+	if n > 64 {
+		n = 64
+	}
+	if n > z.w {
+		return io.ErrShortWrite
+	}
+	z.b, z.o = bePut(z.b, z.o, n, v)
+	z.w -= n
+	return nil
+}
+
+// WriteBool writes a single bit to the start of the field,
+// 1 if b is true or 0 if false, shrinking the field to skip it.
+// Returns io.ErrShortWrite if the field is empty.
+func (z *BigEndianField) WriteBool(b bool) error {
+	return WriteBool(z, b)
+}
+
+// SetPadding sets the policy that WriteTo and ReadFrom apply
+// to a trailing partial byte of field z.
+// Fields created by Field, and zero-valued fields, use PadZeros.
+func (z *BigEndianField) SetPadding(p Padding) {
+	z.p = p
+}
+
+// WriteTo implements the io.WriterTo interface,
+// writing the contents of field z to w as whole bytes,
+// first bit first as for BigEndian.Bits with Left alignment,
+// and completing or omitting a trailing partial byte
+// according to the field's padding policy.
+// Shrinks the field to skip the bits written,
+// which exclude any truncated trailing bits.
+// Returns the number of bytes written and any error encountered.
+func (z *BigEndianField) WriteTo(w io.Writer) (int64, error) {
+	b := z.Bytes(Left)
+	if r := z.w & 7; r != 0 {
+		if z.p == Truncate {
+			b = b[:len(b)-1]
+		} else {
+			bePut(b[len(b)-1:], r, 8 - r, z.p.bits(r, false))
+		}
+	}
+	n, err := w.Write(b)
+	bits := n * 8
+	if bits > z.w {
+		bits = z.w
+	}
+	z.b, z.o = beNorm(z.b, z.o + bits)
+	z.w -= bits
+	return int64(n), err
+}
+
+// ReadFrom implements the io.ReaderFrom interface,
+// filling field z from the start with bytes read from r,
+// first bit first as for BigEndian.PutBits with Left alignment,
+// until the field is full or r reaches EOF.
+// The field's padding policy determines whether a trailing partial byte
+// is read, with its extra bits discarded, or left unfilled.
+// Shrinks the field to skip the bits filled.
+// Returns the number of bytes read and any error other than EOF.
+func (z *BigEndianField) ReadFrom(r io.Reader) (int64, error) {
+	l := z.w >> 3
+	if z.w & 7 != 0 && z.p != Truncate {
+		l++
+	}
+	b := make([]byte, l)
+	n, err := io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	bits := n * 8
+	if bits > z.w {
+		bits = z.w
+	}
+	beCopy(z.b, b, z.o, 0, bits)
+	z.b, z.o = beNorm(z.b, z.o + bits)
+	z.w -= bits
+	return int64(n), err
+}
+
+// Set sets the contents of bit field z to that of field x,
 // and returns z.
 // The source field x must be at least as long as field z.
+// If x is a LittleEndianField, Set converts its first z.Len() bits
+// to big-endian bit order, preserving their value as an integer,
+// so that the first bit of z receives the most-significant bit of x.
 func (z *BigEndianField) Set(x Field) Field {
+	if cx, ok := x.(*LittleEndianField); ok {
+		convertField(z, cx.Slice(0, z.w).(fieldDigits))
+		return z
+	}
 	xf := x.(*BigEndianField)
 	xb, xo, zb, zo, w := xf.b, xf.o, z.b, z.o, z.w
 	var xv uint64
 	for w >= 64 {
 		xb, xo, xv = beGet64(xb, xo)
 		zb, zo = bePut64(zb, zo, xv)
+		w -= 64
 	}
 	xb, xo, xv = beGet(xb, xo, w)
 	zb, zo = bePut(zb, zo, w, xv)
@@ -80,6 +248,7 @@ func (z *BigEndianField) And(x, y Field) Field {
 		xb, xo, xv = beGet64(xb, xo)
 		yb, yo, yv = beGet64(yb, yo)
 		zb, zo = bePut64(zb, zo, xv & yv)
+		w -= 64
 	}
 	xb, xo, xv = beGet(xb, xo, w)
 	yb, yo, yv = beGet(yb, yo, w)
@@ -99,6 +268,7 @@ func (z *BigEndianField) AndNot(x, y Field) Field {
 		xb, xo, xv = beGet64(xb, xo)
 		yb, yo, yv = beGet64(yb, yo)
 		zb, zo = bePut64(zb, zo, xv &^ yv)
+		w -= 64
 	}
 	xb, xo, xv = beGet(xb, xo, w)
 	yb, yo, yv = beGet(yb, yo, w)
@@ -117,6 +287,7 @@ func (z *BigEndianField) Or(x, y Field) Field {
 		xb, xo, xv = beGet64(xb, xo)
 		yb, yo, yv = beGet64(yb, yo)
 		zb, zo = bePut64(zb, zo, xv | yv)
+		w -= 64
 	}
 	xb, xo, xv = beGet(xb, xo, w)
 	yb, yo, yv = beGet(yb, yo, w)
@@ -135,6 +306,7 @@ func (z *BigEndianField) Xor(x, y Field) Field {
 		xb, xo, xv = beGet64(xb, xo)
 		yb, yo, yv = beGet64(yb, yo)
 		zb, zo = bePut64(zb, zo, xv ^ yv)
+		w -= 64
 	}
 	xb, xo, xv = beGet(xb, xo, w)
 	yb, yo, yv = beGet(yb, yo, w)
@@ -152,6 +324,7 @@ func (z *BigEndianField) Not(x Field) Field {
 	for w >= 64 {
 		xb, xo, xv = beGet64(xb, xo)
 		zb, zo = bePut64(zb, zo, ^xv)
+		w -= 64
 	}
 	xb, xo, xv = beGet(xb, xo, w)
 	zb, zo = bePut(zb, zo, w, ^xv)
@@ -181,7 +354,243 @@ func (z *BigEndianField) RotateLeft(x Field, rot int) Field {
 	return z
 }
 
+// Reverse sets field z to the contents of field x in reverse bit order,
+// so that the first bit of x becomes the last bit of z and vice versa,
+// and returns z.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place reversal,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *BigEndianField) Reverse(x Field) Field {
+	xf := x.(*BigEndianField)
+	lo, hi := 0, z.w
+	for hi - lo > 0 {
+		// Swap k bits from each end, at most 64,
+		// leaving the center bit of an odd-width middle in place
+		k := (hi - lo) >> 1
+		if k > 64 {
+			k = 64
+		}
+		if k == 0 {
+			xb, xo := beNorm(xf.b, xf.o + lo)
+			zb, zo := beNorm(z.b, z.o + lo)
+			beCopy(zb, xb, zo, xo, 1)
+			break
+		}
+		ab, ao := beNorm(xf.b, xf.o + lo)
+		_, _, a := beGet(ab, ao, k)
+		bb, bo := beNorm(xf.b, xf.o + hi - k)
+		_, _, b := beGet(bb, bo, k)
+		zb, zo := beNorm(z.b, z.o + lo)
+		bePut(zb, zo, k, bits.Reverse64(b) >> (64 - k))
+		zb, zo = beNorm(z.b, z.o + hi - k)
+		bePut(zb, zo, k, bits.Reverse64(a) >> (64 - k))
+		lo, hi = lo + k, hi - k
+	}
+	return z
+}
+
+// BinaryToGray sets field z to the reflected binary Gray code of field x,
+// in which each bit is the exclusive-or of the corresponding bit of x
+// and the next more-significant bit, and returns z.
+// Fields are treated as unsigned integers with the first bit most significant,
+// as in BigEndian.Uint.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place conversion,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *BigEndianField) BinaryToGray(x Field) Field {
+	xf := x.(*BigEndianField)
+	var c uint64	// least-significant bit of the preceding chunk
+	for o := 0; o < z.w; o += 64 {	// convert from the most-significant end
+		n := min(z.w - o, 64)
+		xb, xo := beNorm(xf.b, xf.o + o)
+		_, _, v := beGet(xb, xo, n)
+		zb, zo := beNorm(z.b, z.o + o)
+		bePut(zb, zo, n, v ^ (v >> 1 | c << (n-1)))
+		c = v & 1
+	}
+	return z
+}
+
+// GrayToBinary sets field z to the binary value of the Gray code in field x,
+// inverting BinaryToGray, and returns z.
+// Each bit of z is the parity of the corresponding bit of x
+// and all more-significant bits.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place conversion,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *BigEndianField) GrayToBinary(x Field) Field {
+	xf := x.(*BigEndianField)
+	var c uint64	// least-significant bit of the preceding chunk
+	for o := 0; o < z.w; o += 64 {	// convert from the most-significant end
+		n := min(z.w - o, 64)
+		xb, xo := beNorm(xf.b, xf.o + o)
+		_, _, v := beGet(xb, xo, n)
+		for s := 1; s < n; s <<= 1 {	// prefix parity within the chunk
+			v ^= v >> s
+		}
+		v ^= -c & (1 << n - 1)	// parity of all more-significant chunks
+		zb, zo := beNorm(z.b, z.o + o)
+		bePut(zb, zo, n, v)
+		c = v & 1
+	}
+	return z
+}
+
+// ShiftLeft sets field z to field x shifted left by s bits,
+// toward lower offsets, filling the vacated bits with zeros.
+// To shift right, pass a negative value for s.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place shifts,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *BigEndianField) ShiftLeft(x Field, s int) Field {
+	if s < 0 {
+		return z.ShiftRight(x, -s)
+	}
+	xf := x.(*BigEndianField)
+	zb, zo, w := z.b, z.o, z.w
+	if s > w {
+		s = w
+	}
+	if sameStart(xf.b, zb, xf.o, zo) {
+		beMove(zb, zo, zo + s, w - s)
+		zb, zo = beNorm(zb, zo + w - s)
+	} else {
+		xb, xo := beNorm(xf.b, xf.o + s)
+		zb, _, zo, _ = beCopy(zb, xb, zo, xo, w - s)
+	}
+	beFill(zb, zo, s, 0)
+	return z
+}
+
+// ShiftRight sets field z to field x shifted right by s bits,
+// toward higher offsets, filling the vacated bits with zeros.
+// To shift left, pass a negative value for s.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place shifts,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *BigEndianField) ShiftRight(x Field, s int) Field {
+	if s < 0 {
+		return z.ShiftLeft(x, -s)
+	}
+	xf := x.(*BigEndianField)
+	zb, zo, w := z.b, z.o, z.w
+	if s > w {
+		s = w
+	}
+	if sameStart(xf.b, zb, xf.o, zo) {	// copy from the end
+		beMove(zb, zo + s, zo, w - s)
+	} else {
+		tb, to := beNorm(zb, zo + s)
+		beCopy(tb, xf.b, to, xf.o, w - s)
+	}
+	beFill(zb, zo, s, 0)
+	return z
+}
+
+// InsertBits inserts n zero bits into field z at bit offset ofs,
+// moving the bits previously at ofs and beyond n bits toward the end,
+// and grows the field's width by n bits.
+// The inserted bits may then be set via z.Slice(ofs, n).
+// The underlying buffer must have room for the grown field;
+// otherwise InsertBits panics with ErrShortBuffer.
+// Panics with ErrOutOfRange if ofs is not within the field or n is negative.
+func (z *BigEndianField) InsertBits(ofs, n int) {
+	if ofs < 0 || ofs > z.w || n < 0 {
+		panic(ErrOutOfRange)
+	}
+	if z.o + z.w + n > len(z.b) * 8 {
+		panic(ErrShortBuffer)
+	}
+	beMove(z.b, z.o + ofs + n, z.o + ofs, z.w - ofs)
+	zb, zo := beNorm(z.b, z.o + ofs)
+	beFill(zb, zo, n, 0)
+	z.w += n
+}
+
+// DeleteBits deletes n bits from field z starting at bit offset ofs,
+// moving the bits following them n bits toward the start,
+// and shrinks the field's width by n bits.
+// The bits in the underlying buffer past the field's new end
+// are left unmodified.
+// Panics with ErrOutOfRange if the deleted range is not within the field.
+func (z *BigEndianField) DeleteBits(ofs, n int) {
+	if ofs < 0 || n < 0 || ofs + n > z.w {
+		panic(ErrOutOfRange)
+	}
+	beMove(z.b, z.o + ofs, z.o + ofs + n, z.w - ofs - n)
+	z.w -= n
+}
+
+// Swap exchanges the contents of bit field z with those of field x,
+// which must be of the same width, 64 bits at a time,
+// without allocating a temporary buffer.
+// The fields may be in different buffers but must not overlap.
+// Panics with ErrLengthMismatch if the fields' widths differ.
+func (z *BigEndianField) Swap(x Field) {
+	xf := x.(*BigEndianField)
+	if xf.w != z.w {
+		panic(ErrLengthMismatch)
+	}
+	xb, xo, zb, zo, w := xf.b, xf.o, z.b, z.o, z.w
+	var xv, zv uint64
+	for w >= 64 {
+		_, _, xv = beGet64(xb, xo)
+		_, _, zv = beGet64(zb, zo)
+		xb, xo = bePut64(xb, xo, zv)
+		zb, zo = bePut64(zb, zo, xv)
+		w -= 64
+	}
+	_, _, xv = beGet(xb, xo, w)
+	_, _, zv = beGet(zb, zo, w)
+	bePut(xb, xo, w, zv)
+	bePut(zb, zo, w, xv)
+}
+
+// Len returns the width of field z in bits.
+func (z *BigEndianField) Len() int {
+	return z.w
+}
+
+// Equal reports whether fields z and x have the same width and contents,
+// regardless of their alignment in their underlying buffers.
+func (z *BigEndianField) Equal(x Field) bool {
+	return x.Len() == z.w && z.Compare(x) == 0
+}
+
+// Compare compares the contents of fields z and x as unsigned integers
+// with the first bit most significant, as in BigEndian.Uint,
+// and returns -1 if z < x, 0 if z == x, or +1 if z > x.
+// Panics with ErrLengthMismatch if the fields' widths differ.
+func (z *BigEndianField) Compare(x Field) int {
+	xf := x.(*BigEndianField)
+	if xf.w != z.w {
+		panic(ErrLengthMismatch)
+	}
+	xb, xo, zb, zo, w := xf.b, xf.o, z.b, z.o, z.w
+	var xv, zv uint64
+	for w >= 64 {
+		xb, xo, xv = beGet64(xb, xo)
+		zb, zo, zv = beGet64(zb, zo)
+		if zv != xv {
+			break
+		}
+		w -= 64
+	}
+	if w < 64 {
+		xb, xo, xv = beGet(xb, xo, w)
+		zb, zo, zv = beGet(zb, zo, w)
+	}
+	switch {
+	case zv < xv:
+		return -1
+	case zv > xv:
+		return 1
+	}
+	return 0
+}
+
 // Count returns the number of bits with value b (0 or 1) in field z.
+// Panics with ErrBadBitValue if b is not 0 or 1.
 func (z *BigEndianField) Count(b uint) (n int) {
 	zb, zo, w := z.b, z.o, z.w
 	var v uint64
@@ -190,6 +599,7 @@ func (z *BigEndianField) Count(b uint) (n int) {
 		for w >= 64 {
 			zb, zo, v = beGet64(zb, zo)
 			n += bits.OnesCount64(^v)
+			w -= 64
 		}
 		zb, zo, v = beGet(zb, zo, w)
 		n += bits.OnesCount64(v ^ ((1 << w) - 1))
@@ -197,31 +607,246 @@ func (z *BigEndianField) Count(b uint) (n int) {
 		for w >= 64 {
 			zb, zo, v = beGet64(zb, zo)
 			n += bits.OnesCount64(v)
+			w -= 64
 		}
 		zb, zo, v = beGet(zb, zo, w)
 		n += bits.OnesCount64(v)
 	default:
-		panic("Count: invalid bit value")
+		panic(ErrBadBitValue)
 	}
 	return n
 }
 
+// FindFirst returns the offset of the first bit in field z
+// with value b (0 or 1), or -1 if there is no such bit.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func (z *BigEndianField) FindFirst(b uint) int {
+	return z.Next(b, 0)
+}
+
+// Next returns the offset of the first bit in field z
+// with value b (0 or 1) at or after offset from,
+// or -1 if there is no such bit.
+// Panics with ErrBadBitValue if b is not 0 or 1,
+// or with ErrOutOfRange if from is negative.
+func (z *BigEndianField) Next(b uint, from int) int {
+	if b > 1 {
+		panic(ErrBadBitValue)
+	}
+	if from < 0 {
+		panic(ErrOutOfRange)
+	}
+	if from >= z.w {
+		return -1
+	}
+	i := from + BigEndian.LeadingAt(z.b, z.o + from, z.w - from, 1 - b)
+	if i == z.w {
+		return -1
+	}
+	return i
+}
+
+// Ones returns an iterator over the offsets of the bits in field z
+// with value 1, in increasing order.
+func (z *BigEndianField) Ones() iter.Seq[int] {
+	return z.positions(1)
+}
+
+// Zeros returns an iterator over the offsets of the bits in field z
+// with value 0, in increasing order.
+func (z *BigEndianField) Zeros() iter.Seq[int] {
+	return z.positions(0)
+}
+
+// Words returns an iterator over the contents of field z
+// 64 bits at a time in order of increasing offset,
+// yielding the number of bits n and the bits themselves
+// in the least-significant n bits of v, with the first bit most significant.
+// Every word but the last holds 64 bits;
+// the last holds the remaining 1 to 64 bits of the field.
+func (z *BigEndianField) Words() iter.Seq2[int, uint64] {
+	return func(yield func(n int, v uint64) bool) {
+		zb, zo, w := z.b, z.o, z.w
+		for w > 0 {
+			n := w
+			if n > 64 {
+				n = 64
+			}
+			var v uint64
+			zb, zo, v = beGet(zb, zo, n)
+			if !yield(n, v) {
+				return
+			}
+			w -= n
+		}
+	}
+}
+
+// positions returns an iterator over the offsets of bits with value b,
+// scanning the field 64 bits at a time.
+func (z *BigEndianField) positions(b uint) iter.Seq[int] {
+	m := ^bitMask(b)	// flips zero bits to ones if b == 0
+	return func(yield func(int) bool) {
+		zb, zo, w := z.b, z.o, z.w
+		for base := 0; base < w; base += 64 {
+			n := w - base
+			if n > 64 {
+				n = 64
+			}
+			var v uint64
+			zb, zo, v = beGet(zb, zo, n)
+			v ^= m & (1 << n - 1)
+			v <<= 64 - n	// first bit most significant
+			for v != 0 {
+				i := bits.LeadingZeros64(v)
+				if !yield(base + i) {
+					return
+				}
+				v &^= 1 << 63 >> i
+			}
+		}
+	}
+}
+
+// Canonical returns a copy of the contents of field z
+// in a freshly-allocated buffer just large enough to hold it,
+// starting at bit offset 0 and with any unused trailing bits
+// of the last byte cleared to zero.
+// Two fields with the same width and contents always yield identical
+// canonical buffers regardless of their alignment in their underlying slices,
+// making this form suitable for hashing, comparing, or storing fields.
+func (z *BigEndianField) Canonical() []byte {
+	buf := make([]byte, (z.w + 7) >> 3)
+	beCopy(buf, z.b, 0, z.o, z.w)
+	return buf
+}
+
+// String returns the contents of field z as a string of binary digits,
+// in bit offset order, first bit first.
+func (z *BigEndianField) String() string {
+	return string(appendDigits(nil, z, 1, lowerDigits))
+}
+
+// top returns as an integer the n bits, at most 64,
+// that follow the ofs most-significant bits of field z.
+func (z *BigEndianField) top(ofs, n int) uint64 {
+	b, o := beNorm(z.b, z.o + ofs)
+	_, _, v := beGet(b, o, n)
+	return v
+}
+
+// putTop sets the n bits, at most 64,
+// that follow the ofs most-significant bits of field z to integer v.
+func (z *BigEndianField) putTop(ofs, n int, v uint64) {
+	b, o := beNorm(z.b, z.o + ofs)
+	bePut(b, o, n, v)
+}
+
+// Bytes returns a copy of the contents of field z
+// in a freshly-allocated buffer just large enough to hold it,
+// left- or right-aligned according to align,
+// with any unused padding bits cleared to zero.
+func (z *BigEndianField) Bytes(align Align) []byte {
+	return z.AppendBytes(nil, align)
+}
+
+// AppendBytes appends the contents of field z to dst
+// as (z.Len()+7)/8 whole bytes, left- or right-aligned according to align,
+// with any unused padding bits cleared to zero,
+// and returns the extended slice.
+func (z *BigEndianField) AppendBytes(dst []byte, align Align) []byte {
+	l, n := len(dst), (z.w + 7) >> 3
+	dst = Grow(dst, l + n)
+	b := dst[l:]
+	for i := range b {
+		b[i] = 0
+	}
+	beCopy(b, z.b, alignOfs(n, z.w, align), z.o, z.w)
+	return dst
+}
+
+// Key returns a string that uniquely encodes
+// both the width and the bit content of field z,
+// so that the contents of fields may be used as Go map keys.
+// Like Hash, the key does not depend on the field's alignment.
+// Fields with the same bits but different widths,
+// such as a 1-bit and a 2-bit field both containing only zeros,
+// always yield different keys.
+func (z *BigEndianField) Key() string {
+	return bitsKey(z.w, z.Canonical())
+}
+
+// Hash returns a 64-bit hash of the contents of field z,
+// which depends on the seed and on the field's bit content and width,
+// but not on the alignment of the field within its underlying buffer.
+// Fields with identical contents therefore hash identically
+// wherever they are located, while fields of different widths,
+// such as a 1-bit and a 2-bit field both containing only zeros,
+// generally hash differently.
+// The hash is not cryptographically secure.
+func (z *BigEndianField) Hash(seed uint64) uint64 {
+	zb, zo, w := z.b, z.o, z.w
+	h := seed
+	var v uint64
+	for w >= 64 {
+		zb, zo, v = beGet64(zb, zo)
+		h = hashMix(h, v)
+		w -= 64
+	}
+	if w > 0 {
+		zb, zo, v = beGet(zb, zo, w)
+		h = hashMix(h, v)
+	}
+	return hashMix(h, uint64(z.w))
+}
+
 // Fill sets all bits in field z to bit value b (0 or 1).
+// Panics with ErrBadBitValue if b is not 0 or 1.
 func (z *BigEndianField) Fill(b uint) {
 	zb, zo, w := z.b, z.o, z.w
 	switch b {
 	case 0:
 		for w >= 64 {
 			zb, zo = bePut64(zb, zo, 0)
+			w -= 64
 		}
 		zb, zo = bePut(zb, zo, w, 0)
 	case 1:
 		for w >= 64 {
 			zb, zo = bePut64(zb, zo, (1<<64)-1)
+			w -= 64
 		}
 		zb, zo = bePut(zb, zo, w, (1<<64)-1)
 	default:
-		panic("Count: invalid bit value")
+		panic(ErrBadBitValue)
 	}
 }
 
+// FillPattern fills field z with repetitions of the n-bit pattern
+// in the least-significant n bits of pattern,
+// whose first bit is its most significant,
+// where n must be between 1 and 64.
+// If the field's width is not a multiple of n,
+// the last repetition is truncated to the leading bits of the pattern.
+// Panics with ErrOutOfRange if n is not within this range.
+func (z *BigEndianField) FillPattern(pattern uint64, n int) {
+	if n < 1 || n > 64 {
+		panic(ErrOutOfRange)
+	}
+	if n < 64 {
+		pattern &= 1 << n - 1
+	}
+
+	// Tile as many whole repetitions as fit into a 64-bit word
+	var rep uint64
+	k := 64 / n * n
+	for i := 0; i < 64 / n; i++ {
+		rep = rep << n | pattern
+	}
+	zb, zo, w := z.b, z.o, z.w
+	for w >= k {
+		zb, zo = bePut(zb, zo, k, rep)
+		w -= k
+	}
+	bePut(zb, zo, w, rep >> (k - w))
+}
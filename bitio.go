@@ -1,5 +1,11 @@
 package bytebits
 
+import (
+	"io"
+	"math/bits"
+)
+
+
 // BitWriter is an interface to a stream
 // that supports writing a few bits at a time.
 //
@@ -30,3 +36,375 @@ type BitReader interface {
 	ReadBits(n int) (b uint64, err error)
 }
 
+// SkipBits discards the next n bits from r,
+// using r's own Skip method if it has one, as Reader does,
+// and otherwise reading and discarding up to 64 bits at a time.
+// Returns EOF if r ends before n bits have been skipped.
+func SkipBits(r BitReader, n int64) error {
+	if s, ok := r.(interface{ Skip(int64) error }); ok {
+		return s.Skip(n)
+	}
+	if n < 0 {
+		return ErrOutOfRange
+	}
+	for ; n > 0; n -= 64 {
+		k := 64
+		if n < 64 {
+			k = int(n)
+		}
+		if _, err := r.ReadBits(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BitReaderAt is an interface to a bit stream
+// supporting random access by bit offset, analogous to io.ReaderAt.
+// The ReadBitsAt method reads n bits, or 64 bits if n > 64,
+// starting off bits from the start of the stream,
+// into the least-significant bits of the returned value b.
+// ReadBitsAt returns EOF if the stream ends before reading n bits,
+// and ErrOutOfRange if off is negative.
+// ReadBitsAt keeps no cursor, so clients may call it concurrently
+// if the underlying source permits.
+//
+type BitReaderAt interface {
+	ReadBitsAt(n int, off int64) (b uint64, err error)
+}
+
+// NewBitReaderAt returns a BitReaderAt that reads bits from ra,
+// most-significant bit of each byte first.
+// For random access to bits already in a byte slice,
+// a BigEndianField or LittleEndianField covering the slice
+// is a BitReaderAt in its own right.
+func NewBitReaderAt(ra io.ReaderAt) BitReaderAt {
+	return BigEndian.NewBitReaderAt(ra)
+}
+
+// NewBitReaderAt returns a BitReaderAt that reads bits from ra,
+// most-significant bit of each byte first.
+func (_ BigEndianOrder) NewBitReaderAt(ra io.ReaderAt) BitReaderAt {
+	return &bitReaderAt{ra, false}
+}
+
+// NewBitReaderAt returns a BitReaderAt that reads bits from ra,
+// least-significant bit of each byte first,
+// with the first bit read in the least-significant position.
+func (_ LittleEndianOrder) NewBitReaderAt(ra io.ReaderAt) BitReaderAt {
+	return &bitReaderAt{ra, true}
+}
+
+type bitReaderAt struct {
+	ra io.ReaderAt		// Underlying random-access byte source
+	lsb bool		// Read bits least-significant first
+}
+
+func (r *bitReaderAt) ReadBitsAt(n int, off int64) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if off < 0 {
+		return 0, ErrOutOfRange
+	}
+	var buf [9]byte
+	o := int(off & 7)
+	b := buf[:(o + n + 7) >> 3]
+	if m, err := r.ra.ReadAt(b, off >> 3); m < len(b) {
+		return 0, err
+	}
+	if r.lsb {
+		_, _, v = leGet(b, o, n)
+	} else {
+		_, _, v = beGet(b, o, n)
+	}
+	return v, nil
+}
+
+// BitSectionReader implements ReadBits, ReadBitsAt, and SeekBits
+// on a section of an underlying BitReaderAt,
+// analogous to io.SectionReader.
+// Each BitSectionReader has its own cursor,
+// so several may parse independent sections of the same source
+// concurrently if the source permits.
+type BitSectionReader struct {
+	r BitReaderAt
+	base int64		// Start of the section in r
+	off int64		// Current cursor position in r
+	limit int64		// End of the section in r
+}
+
+// NewBitSectionReader returns a BitSectionReader that reads from r
+// starting at bit offset off and stopping with EOF after n bits.
+func NewBitSectionReader(r BitReaderAt, off, n int64) *BitSectionReader {
+	return &BitSectionReader{r, off, off, off + n}
+}
+
+// ReadBits implements the BitReader interface,
+// reading n bits, or 64 bits maximum, from the section's cursor.
+// Returns EOF without advancing the cursor
+// if fewer than n bits remain in the section.
+func (s *BitSectionReader) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if s.off + int64(n) > s.limit {
+		return 0, EOF
+	}
+	if v, err = s.r.ReadBitsAt(n, s.off); err == nil {
+		s.off += int64(n)
+	}
+	return v, err
+}
+
+// ReadBitsAt implements the BitReaderAt interface,
+// reading n bits at bit offset off relative to the start of the section,
+// independently of the cursor.
+func (s *BitSectionReader) ReadBitsAt(n int, off int64) (uint64, error) {
+	if n > 64 {
+		n = 64
+	}
+	if off < 0 {
+		return 0, ErrOutOfRange
+	}
+	if off + int64(n) > s.limit - s.base {
+		return 0, EOF
+	}
+	return s.r.ReadBitsAt(n, s.base + off)
+}
+
+// SeekBits sets the cursor to offset, interpreted according to whence
+// as for io.Seeker, relative to the start of the section,
+// and returns the new offset relative to the start of the section.
+// Seeking to a negative offset fails with ErrOutOfRange.
+func (s *BitSectionReader) SeekBits(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		offset += s.base
+	case io.SeekCurrent:
+		offset += s.off
+	case io.SeekEnd:
+		offset += s.limit
+	default:
+		return s.off - s.base, ErrOutOfRange
+	}
+	if offset < s.base {
+		return s.off - s.base, ErrOutOfRange
+	}
+	s.off = offset
+	return offset - s.base, nil
+}
+
+// Size returns the size of the section in bits.
+func (s *BitSectionReader) Size() int64 {
+	return s.limit - s.base
+}
+
+// ReadBool reads a single bit from r and returns true if it is 1.
+// Works on any BitReader, including the one returned by Field.Reader.
+func ReadBool(r BitReader) (bool, error) {
+	v, err := r.ReadBits(1)
+	return v != 0, err
+}
+
+// WriteBool writes a single bit to w, 1 if b is true or 0 if false.
+func WriteBool(w BitWriter, b bool) error {
+	var v uint64
+	if b {
+		v = 1
+	}
+	return w.WriteBits(1, v)
+}
+
+
+
+// LimitBitReader returns a BitReader that reads from r
+// but stops with EOF after n bits.
+// The underlying implementation is a *LimitedBitReader.
+func LimitBitReader(r BitReader, n int64) BitReader {
+	return &LimitedBitReader{r, n}
+}
+
+// A LimitedBitReader reads from R but limits the number of bits returned
+// to just N, analogous to io.LimitedReader.
+// Each call to ReadBits updates N to reflect the new number remaining.
+// ReadBits returns EOF, without reading from R,
+// if fewer than the requested number of bits remain within the limit.
+type LimitedBitReader struct {
+	R BitReader	// Underlying reader
+	N int64		// Maximum number of bits remaining
+}
+
+func (l *LimitedBitReader) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if int64(n) > l.N {
+		return 0, EOF
+	}
+	v, err = l.R.ReadBits(n)
+	if err == nil {
+		l.N -= int64(n)
+	}
+	return v, err
+}
+
+
+// MultiBitReader returns a BitReader that is the logical concatenation
+// of the provided bit readers, which are read sequentially,
+// with the first bit read in the most-significant position
+// of a value combining bits from more than one reader.
+// Once all readers have returned EOF, ReadBits returns EOF.
+// A read that spans the end of the last reader
+// consumes the remaining bits before returning EOF.
+func MultiBitReader(readers ...BitReader) BitReader {
+	return BigEndian.MultiBitReader(readers...)
+}
+
+// MultiBitReader returns a BitReader that is the logical concatenation
+// of the provided MSB-first bit readers, as for the MultiBitReader function.
+func (_ BigEndianOrder) MultiBitReader(readers ...BitReader) BitReader {
+	return &multiBitReader{append([]BitReader(nil), readers...), false}
+}
+
+// MultiBitReader returns a BitReader that is the logical concatenation
+// of the provided LSB-first bit readers, as for the MultiBitReader function
+// but with the first bit read in the least-significant position.
+func (_ LittleEndianOrder) MultiBitReader(readers ...BitReader) BitReader {
+	return &multiBitReader{append([]BitReader(nil), readers...), true}
+}
+
+type multiBitReader struct {
+	readers []BitReader	// Readers not yet exhausted
+	lsb bool		// Combine bits least-significant first
+}
+
+func (mr *multiBitReader) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	k := 0		// number of bits read so far
+	add := func(x uint64, m int) {
+		if mr.lsb {
+			v |= x << k
+		} else {
+			v = v << m | x
+		}
+		k += m
+	}
+	for k < n && len(mr.readers) > 0 {
+		x, err := mr.readers[0].ReadBits(n - k)
+		if err == nil {
+			add(x, n - k)
+			break
+		}
+		if err != EOF {
+			return 0, err
+		}
+
+		// Take the last few bits of this reader one at a time
+		for {
+			x, err := mr.readers[0].ReadBits(1)
+			if err == EOF {
+				break
+			} else if err != nil {
+				return 0, err
+			}
+			add(x, 1)
+		}
+		mr.readers = mr.readers[1:]
+	}
+	if k < n {
+		return 0, EOF
+	}
+	return v, nil
+}
+
+
+// CountingBitWriter is a BitWriter that counts the bits written through it
+// while forwarding them to an underlying BitWriter W.
+// If W is nil, the writer discards all bits and only counts them,
+// as a two-pass encoder might do to measure its output
+// before committing to a layout.
+// The zero value is such a discarding writer, ready to use:
+// run the encoder in a dry run against it,
+// then read N to size headers before the real encoding pass.
+type CountingBitWriter struct {
+	W BitWriter	// Underlying writer, or nil to discard
+	N int64		// Number of bits successfully written
+}
+
+// NewCountingBitWriter returns a CountingBitWriter forwarding to w,
+// which may be nil to count bits without writing them anywhere.
+func NewCountingBitWriter(w BitWriter) *CountingBitWriter {
+	return &CountingBitWriter{W: w}
+}
+
+func (c *CountingBitWriter) WriteBits(n int, v uint64) error {
+	if n > 64 {
+		n = 64
+	}
+	if c.W != nil {
+		if err := c.W.WriteBits(n, v); err != nil {
+			return err
+		}
+	}
+	c.N += int64(n)
+	return nil
+}
+
+
+// Transcode copies n bits from src to dst, up to 64 bits at a time,
+// bridging streams of the same or opposite bit orders,
+// and returns the number of bits copied and any error encountered.
+// SrcOrder and dstOrder specify the bit orders of src and dst,
+// which may be any BitReader and BitWriter,
+// such as Field readers, AppendWriters, or LimitedBitReaders.
+// By default Transcode preserves the bit sequence:
+// the i-th bit read from src is the i-th bit written to dst,
+// so that copying between an MSB-first and an LSB-first stream
+// reverses the bits of each byte-aligned byte.
+// If reverseBytes is true, Transcode instead reverses the order
+// of each group of 8 bits, counted from the start of the copy,
+// and of any final group of fewer than 8 bits,
+// so that copying between streams of opposite bit orders
+// preserves the values of whole bytes.
+// If src ends before n bits have been copied, Transcode returns EOF.
+func Transcode(dst BitWriter, dstOrder BitOrder, src BitReader,
+		srcOrder BitOrder, n int64, reverseBytes bool) (int64, error) {
+	srcLSB, dstLSB := srcOrder.LSBFirst(), dstOrder.LSBFirst()
+	var done int64
+	for done < n {
+		k := 64
+		if n - done < 64 {
+			k = int(n - done)
+			if reverseBytes && k > 8 {
+				k &^= 7		// keep byte groups whole
+			}
+		}
+		v, err := src.ReadBits(k)
+		if err != nil {
+			return done, err
+		}
+
+		// Put v in MSB-first form, transform it, then put it in dst's
+		if srcLSB {
+			v = bits.Reverse64(v) >> (64 - k)
+		}
+		if reverseBytes {
+			if k & 7 == 0 {
+				v = bits.Reverse64(bits.ReverseBytes64(v))
+			} else {
+				v = bits.Reverse64(v) >> (64 - k)
+			}
+		}
+		if dstLSB {
+			v = bits.Reverse64(v) >> (64 - k)
+		}
+		if err := dst.WriteBits(k, v); err != nil {
+			return done, err
+		}
+		done += int64(k)
+	}
+	return done, nil
+}
@@ -0,0 +1,261 @@
+package bytebits
+
+import (
+	"bytes"
+	"io"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+
+func TestLimitBitReader(t *testing.T) {
+	src := BigEndian.Field(testBits, 0, 192).(*BigEndianField)
+	r := LimitBitReader(src, 20)
+	if v, err := r.ReadBits(12); err != nil || v != 0xdea {
+		t.Errorf("ReadBits(12): got %x, %v", v, err)
+	}
+	if _, err := r.ReadBits(9); err != EOF {
+		t.Errorf("ReadBits past limit: got %v, want EOF", err)
+	}
+	if v, err := r.ReadBits(8); err != nil || v != 0xdb {
+		t.Errorf("ReadBits up to limit: got %x, %v", v, err)
+	}
+	if l := r.(*LimitedBitReader); l.N != 0 || src.Len() != 172 {
+		t.Errorf("limit %v and source %v bits remaining", l.N, src.Len())
+	}
+	if _, err := r.ReadBits(1); err != EOF {
+		t.Errorf("ReadBits at limit: got %v, want EOF", err)
+	}
+}
+
+func TestMultiBitReader(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		var parts []BitReader
+		for _, cut := range [][2]int{{0, 13}, {13, 0}, {13, 100}, {113, 1}, {114, 78}} {
+			parts = append(parts, order.Field(testBits, cut[0], cut[1]).Reader())
+		}
+		r := order.MultiBitReader(parts...)
+		ofs := 0
+		for _, n := range []int{5, 20, 64, 1, 64, 38} {
+			v, err := r.ReadBits(n)
+			if want := order.Uint(testBits, ofs, n); err != nil || v != want {
+				t.Errorf("%v ReadBits(%v) at %v: got %x, %v, want %x",
+					order, n, ofs, v, err, want)
+			}
+			ofs += n
+		}
+		if _, err := r.ReadBits(1); err != EOF {
+			t.Errorf("%v ReadBits at end: got %v, want EOF", order, err)
+		}
+	}
+}
+
+func TestCountingBitWriter(t *testing.T) {
+	buf := make([]byte, 10)
+	f := BigEndian.Field(buf, 0, 76).(*BigEndianField)
+	c := NewCountingBitWriter(f)
+	c.WriteBits(12, 0xabc)
+	c.WriteBits(100, 0)
+	if c.N != 76 || f.Len() != 0 || buf[0] != 0xab {
+		t.Errorf("counted %v bits, %v left in field", c.N, f.Len())
+	}
+	if err := c.WriteBits(1, 1); err == nil || c.N != 76 {
+		t.Errorf("failed write counted or not reported")
+	}
+
+	d := NewCountingBitWriter(nil)
+	for i := 0; i < 10; i++ {
+		d.WriteBits(i, 0)
+	}
+	if d.N != 45 {
+		t.Errorf("discarding writer counted %v bits, want 45", d.N)
+	}
+}
+
+func TestReadWriteBool(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	flags := []bool{true, false, true, true, false, false, false, true, true}
+	for _, b := range flags {
+		if err := w.WriteBool(b); err != nil {
+			t.Fatalf("WriteBool: %v", err)
+		}
+	}
+	w.Flush()
+	if got := out.Bytes(); !bytes.Equal(got, []byte{0xb1, 0x80}) {
+		t.Errorf("WriteBool wrote %x, want b180", got)
+	}
+
+	r := NewReader(&out)
+	f := BigEndian.Field([]byte{0xb1, 0x80}, 0, 9).(*BigEndianField)
+	fr := f.Reader()
+	for i, want := range flags {
+		b, err := r.ReadBool()
+		fb, ferr := f.ReadBool()
+		rb, rerr := ReadBool(fr)
+		if err != nil || ferr != nil || rerr != nil ||
+				b != want || fb != want || rb != want {
+			t.Errorf("flag %v: got %v/%v/%v, want %v", i, b, fb, rb, want)
+		}
+	}
+	if _, err := f.ReadBool(); err != EOF {
+		t.Errorf("ReadBool on empty field: got %v, want EOF", err)
+	}
+
+	g := LittleEndian.Field(make([]byte, 1), 0, 2).(*LittleEndianField)
+	g.WriteBool(false)
+	g.WriteBool(true)
+	if err := g.WriteBool(true); err != io.ErrShortWrite {
+		t.Errorf("WriteBool on empty field: got %v", err)
+	}
+}
+
+func TestBitReaderAt(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		ras := []BitReaderAt{
+			order.NewBitReaderAt(bytes.NewReader(testBits)),
+			order.Field(testBits, 0, 192).(BitReaderAt),
+		}
+		for _, r := range ras {
+			for off := 0; off <= 192; off += 7 {
+				for _, n := range []int{0, 1, 9, 33, 64} {
+					v, err := r.ReadBitsAt(n, int64(off))
+					if off + n > 192 {
+						if err != EOF {
+							t.Errorf("%v ReadBitsAt(%v, %v): "+
+								"got %v, want EOF",
+								order, n, off, err)
+						}
+						continue
+					}
+					want := order.Uint(testBits, off, n)
+					if err != nil || v != want {
+						t.Errorf("%v ReadBitsAt(%v, %v): "+
+							"got %x, %v, want %x",
+							order, n, off, v, err, want)
+					}
+				}
+			}
+			if _, err := r.ReadBitsAt(1, -1); err != ErrOutOfRange {
+				t.Errorf("ReadBitsAt negative offset: got %v", err)
+			}
+		}
+	}
+}
+
+func TestBitSectionReader(t *testing.T) {
+	src := BigEndian.NewBitReaderAt(bytes.NewReader(testBits))
+	s := NewBitSectionReader(src, 37, 100)
+	if s.Size() != 100 {
+		t.Errorf("Size: got %v, want 100", s.Size())
+	}
+	for _, n := range []int{13, 64, 20} {
+		ofs, _ := s.SeekBits(0, io.SeekCurrent)
+		v, err := s.ReadBits(n)
+		if want := BigEndian.Uint(testBits, 37 + int(ofs), n);
+				err != nil || v != want {
+			t.Errorf("ReadBits(%v) at %v: got %x, %v, want %x",
+				n, ofs, v, err, want)
+		}
+	}
+	if _, err := s.ReadBits(4); err != EOF {
+		t.Errorf("ReadBits past section: got %v, want EOF", err)
+	}
+	if v, err := s.ReadBits(3); err != nil ||
+			v != BigEndian.Uint(testBits, 134, 3) {
+		t.Errorf("ReadBits to end of section: got %x, %v", v, err)
+	}
+
+	if ofs, err := s.SeekBits(-10, io.SeekEnd); ofs != 90 || err != nil {
+		t.Errorf("SeekBits from end: got %v, %v", ofs, err)
+	}
+	if v, _ := s.ReadBits(10); v != BigEndian.Uint(testBits, 127, 10) {
+		t.Errorf("ReadBits after SeekBits: got %x", v)
+	}
+	if _, err := s.SeekBits(-1, io.SeekStart); err != ErrOutOfRange {
+		t.Errorf("SeekBits before start: got %v", err)
+	}
+	if v, err := s.ReadBitsAt(8, 92); err != nil ||
+			v != BigEndian.Uint(testBits, 129, 8) {
+		t.Errorf("ReadBitsAt: got %x, %v", v, err)
+	}
+	if _, err := s.ReadBitsAt(8, 93); err != EOF {
+		t.Errorf("ReadBitsAt past section: got %v, want EOF", err)
+	}
+}
+
+func TestCountingBitWriterZero(t *testing.T) {
+	var c CountingBitWriter		// dry run: discard and count
+	WriteBool(&c, true)
+	c.WriteBits(70, 0)
+	if c.N != 65 {
+		t.Errorf("zero CountingBitWriter counted %v bits, want 65", c.N)
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	data := make([]byte, 1001)
+	rand.Read(data)
+	rev := make([]byte, len(data))
+	for i, b := range data {
+		rev[i] = bits.Reverse8(b)
+	}
+	for _, c := range []struct {
+		src, dst BitOrder
+		reverse bool
+		want []byte
+	}{
+		{BigEndian, LittleEndian, false, rev},
+		{BigEndian, LittleEndian, true, data},
+		{LittleEndian, BigEndian, false, rev},
+		{LittleEndian, LittleEndian, false, data},
+		{BigEndian, BigEndian, true, rev},
+	} {
+		var out bytes.Buffer
+		r := c.src.NewReader(bytes.NewReader(data))
+		w := c.dst.NewWriter(&out)
+		r.ReadBits(3)		// start the copy unaligned in src
+		w.WriteBits(3, 0)
+		n, err := Transcode(w, c.dst, r, c.src,
+				int64(len(data)) * 8 - 3, c.reverse)
+		if n != int64(len(data)) * 8 - 3 || err != nil {
+			t.Errorf("Transcode: got %v, %v", n, err)
+		}
+		w.Flush()
+		if !c.reverse && !bytes.Equal(out.Bytes()[1:], c.want[1:]) {
+			t.Errorf("Transcode %v to %v: wrong output", c.src, c.dst)
+		}
+
+		// Byte-reversal needs a byte-aligned copy to preserve bytes
+		out.Reset()
+		r = c.src.NewReader(bytes.NewReader(data))
+		w = c.dst.NewWriter(&out)
+		Transcode(w, c.dst, r, c.src, int64(len(data)) * 8, c.reverse)
+		w.Flush()
+		if !bytes.Equal(out.Bytes(), c.want) {
+			t.Errorf("Transcode %v to %v reverse %v: wrong output",
+				c.src, c.dst, c.reverse)
+		}
+
+		// Any BitReader and BitWriter, here a limited Field reader
+		// into an AppendWriter
+		fr := LimitBitReader(c.src.Field(data, 0, len(data) * 8).Reader(),
+			int64(len(data)) * 8)
+		aw := c.dst.NewAppendWriter(nil)
+		n, err = Transcode(aw, c.dst, fr, c.src,
+				int64(len(data)) * 8, c.reverse)
+		if n != int64(len(data)) * 8 || err != nil ||
+				!bytes.Equal(aw.Bytes(), c.want) {
+			t.Errorf("Transcode %v to %v reverse %v via Field: got %v, %v",
+				c.src, c.dst, c.reverse, n, err)
+		}
+	}
+
+	r := NewReader(bytes.NewReader(data[:2]))
+	w := NewWriter(io.Discard)
+	if n, err := Transcode(w, BigEndian, r, BigEndian, 17, false);
+			n != 0 || err != EOF {
+		t.Errorf("Transcode past end: got %v, %v", n, err)
+	}
+}
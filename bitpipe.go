@@ -0,0 +1,297 @@
+package bytebits
+
+import (
+	"io"
+	"sync"
+)
+
+
+// bitChunk is a group of up to 64 bits in transit through a bit pipe,
+// held in the least-significant n bits of v.
+type bitChunk struct {
+	n int
+	v uint64
+}
+
+// onceError is an error that can be set only once, safely for concurrent use.
+type onceError struct {
+	sync.Mutex
+	err error
+}
+
+func (a *onceError) Store(err error) {
+	a.Lock()
+	defer a.Unlock()
+	if a.err != nil {
+		return
+	}
+	a.err = err
+}
+
+func (a *onceError) Load() error {
+	a.Lock()
+	defer a.Unlock()
+	return a.err
+}
+
+// bitPipe is the shared state of a synchronous in-memory bit pipe.
+type bitPipe struct {
+	rdMu sync.Mutex		// Serializes reads
+	wrMu sync.Mutex		// Serializes writes
+	wrCh chan bitChunk	// Bits offered by the writer
+	rdCh chan int		// Number of offered bits the reader consumed
+
+	once sync.Once		// Protects closing done
+	done chan struct{}
+	rerr onceError
+	werr onceError
+
+	buffered bool		// Writes complete without waiting for reads
+	rem bitChunk		// Leftover bits of a buffered chunk, under rdMu
+}
+
+// read reads exactly n bits, or 64 bits if n > 64, from the pipe.
+func (p *bitPipe) read(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if p.buffered {
+		p.rdMu.Lock()
+		defer p.rdMu.Unlock()
+		return p.readBuffered(n)
+	}
+
+	select {
+	case <-p.done:
+		return 0, p.readCloseError()
+	default:
+		p.rdMu.Lock()
+		defer p.rdMu.Unlock()
+	}
+	for n > 0 {
+		select {
+		case c := <-p.wrCh:
+			k := c.n	// number of bits to take from this chunk
+			if k > n {
+				k = n
+			}
+			v = (v << k) | (c.v >> (c.n-k)) & (1 << k - 1)
+			n -= k
+			p.rdCh <- k
+		case <-p.done:
+			return 0, p.readCloseError()
+		}
+	}
+	return v, nil
+}
+
+// readBuffered reads exactly n bits, at most 64, from a buffered pipe,
+// draining any bits still buffered after the write end is closed.
+func (p *bitPipe) readBuffered(n int) (v uint64, err error) {
+	for n > 0 {
+		if p.rem.n == 0 {
+			select {
+			case p.rem = <-p.wrCh:
+			case <-p.done:
+				if p.rerr.Load() != nil {
+					return 0, p.readCloseError()
+				}
+				select {
+				case p.rem = <-p.wrCh:
+				default:
+					return 0, p.readCloseError()
+				}
+			}
+			continue
+		}
+		k := p.rem.n	// number of bits to take from this chunk
+		if k > n {
+			k = n
+		}
+		p.rem.n -= k
+		v = (v << k) | (p.rem.v >> p.rem.n) & (1 << k - 1)
+		n -= k
+	}
+	return v, nil
+}
+
+func (p *bitPipe) closeRead(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	p.rerr.Store(err)
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+// write writes n bits, or 64 bits if n > 64, from the least-significant bits
+// of v into the pipe, blocking until a reader has consumed them all.
+func (p *bitPipe) write(n int, v uint64) (err error) {
+	select {
+	case <-p.done:
+		return p.writeCloseError()
+	default:
+		p.wrMu.Lock()
+		defer p.wrMu.Unlock()
+	}
+
+	if n > 64 {
+		n = 64
+	}
+	if p.buffered {
+		select {
+		case p.wrCh <- bitChunk{n, v}:
+			return nil
+		case <-p.done:
+			return p.writeCloseError()
+		}
+	}
+	for n > 0 {
+		select {
+		case p.wrCh <- bitChunk{n, v}:
+			n -= <-p.rdCh
+		case <-p.done:
+			return p.writeCloseError()
+		}
+	}
+	return nil
+}
+
+func (p *bitPipe) closeWrite(err error) error {
+	if err == nil {
+		err = EOF
+	}
+	p.werr.Store(err)
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+// readCloseError is considered internal to the pipe type.
+func (p *bitPipe) readCloseError() error {
+	rerr := p.rerr.Load()
+	if werr := p.werr.Load(); rerr == nil && werr != nil {
+		return werr
+	}
+	return io.ErrClosedPipe
+}
+
+// writeCloseError is considered internal to the pipe type.
+func (p *bitPipe) writeCloseError() error {
+	werr := p.werr.Load()
+	if rerr := p.rerr.Load(); werr == nil && rerr != nil {
+		return rerr
+	}
+	return io.ErrClosedPipe
+}
+
+
+// A BitPipeReader is the read half of a bit pipe.
+type BitPipeReader struct {
+	p *bitPipe
+}
+
+// ReadBits implements the BitReader interface,
+// reading exactly n bits from the pipe, or 64 bits if n > 64,
+// into the least-significant bits of the returned value v.
+// The first bit read from the pipe is the most-significant bit of v.
+// ReadBits blocks until enough bits have been written to the pipe
+// or the write end is closed.
+// If the write end is closed before n bits are available,
+// ReadBits discards any partial bits read and returns EOF,
+// or the error passed to CloseWithError.
+func (r *BitPipeReader) ReadBits(n int) (v uint64, err error) {
+	return r.p.read(n)
+}
+
+// Close closes the reader;
+// subsequent writes to the write half of the pipe
+// will return the error io.ErrClosedPipe.
+func (r *BitPipeReader) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError closes the reader;
+// subsequent writes to the write half of the pipe will return the error err.
+// CloseWithError never overwrites the previous error if it exists
+// and always returns nil.
+func (r *BitPipeReader) CloseWithError(err error) error {
+	return r.p.closeRead(err)
+}
+
+
+// A BitPipeWriter is the write half of a bit pipe.
+type BitPipeWriter struct {
+	p *bitPipe
+}
+
+// WriteBits implements the BitWriter interface,
+// writing n bits, or 64 bits if n > 64,
+// from the least-significant bits of v into the pipe.
+// The most-significant of the n bits is the first written to the pipe.
+// WriteBits blocks until one or more reads have consumed all the bits
+// or the read end is closed.
+func (w *BitPipeWriter) WriteBits(n int, v uint64) error {
+	return w.p.write(n, v)
+}
+
+// Close closes the writer;
+// subsequent reads from the read half of the pipe
+// will return no bits and EOF.
+func (w *BitPipeWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError closes the writer;
+// subsequent reads from the read half of the pipe will return
+// no bits and the error err, or EOF if err is nil.
+// CloseWithError never overwrites the previous error if it exists
+// and always returns nil.
+func (w *BitPipeWriter) CloseWithError(err error) error {
+	return w.p.closeWrite(err)
+}
+
+
+// BitPipe creates a synchronous in-memory bit pipe,
+// the bit-granular analog of io.Pipe.
+// It can be used to connect code expecting a BitReader
+// with code expecting a BitWriter,
+// without materializing the intermediate bit stream in a buffer.
+//
+// Reads and writes on the pipe are matched one to one
+// except when multiple reads are needed to consume a single write,
+// or a single read needs bits from multiple writes.
+// That is, each WriteBits to the BitPipeWriter blocks
+// until it has satisfied one or more ReadBits calls
+// from the BitPipeReader that fully consume the written bits.
+// The bits are passed directly from the write to the corresponding reads;
+// there is no internal buffering.
+//
+// It is safe to call ReadBits and WriteBits in parallel with each other
+// or with Close. Parallel calls to ReadBits and parallel calls to WriteBits
+// are also safe: the individual calls will be gated sequentially.
+//
+func BitPipe() (*BitPipeReader, *BitPipeWriter) {
+	p := &bitPipe{
+		wrCh: make(chan bitChunk),
+		rdCh: make(chan int),
+		done: make(chan struct{}),
+	}
+	return &BitPipeReader{p}, &BitPipeWriter{p}
+}
+
+// BufferedBitPipe creates an in-memory bit pipe like BitPipe,
+// but with an internal buffer holding the bits of up to n writes,
+// so that a producer goroutine can run ahead of its consumer.
+// Each WriteBits blocks only while the buffer is full,
+// and returns once its bits are buffered rather than consumed.
+// After the write end is closed,
+// reads continue to return any bits remaining in the buffer
+// before returning EOF or the error passed to CloseWithError.
+func BufferedBitPipe(n int) (*BitPipeReader, *BitPipeWriter) {
+	p := &bitPipe{
+		wrCh: make(chan bitChunk, n),
+		done: make(chan struct{}),
+		buffered: true,
+	}
+	return &BitPipeReader{p}, &BitPipeWriter{p}
+}
@@ -0,0 +1,102 @@
+package bytebits
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+
+func TestBitPipe(t *testing.T) {
+	r, w := BitPipe()
+
+	// Write 0xdeadbeef as a series of odd-sized chunks
+	go func() {
+		w.WriteBits(3, 0x6)		// 110
+		w.WriteBits(9, 0x1ea)		// 1 1110 1010
+		w.WriteBits(20, 0xdbeef)
+		w.WriteBits(1, 1)
+		w.Close()
+	}()
+
+	// Read it back using a different grouping
+	var v uint64
+	for _, n := range []int{8, 4, 16, 4} {
+		b, err := r.ReadBits(n)
+		if err != nil {
+			t.Fatalf("ReadBits(%v): %v", n, err)
+		}
+		v = (v << n) | b
+	}
+	if v != 0xdeadbeef {
+		t.Errorf("BitPipe read %x, want deadbeef", v)
+	}
+
+	// Reading past the end of the stream should discard the partial read
+	if _, err := r.ReadBits(2); err != EOF {
+		t.Errorf("ReadBits past end: got %v, want EOF", err)
+	}
+}
+
+func TestBitPipeClose(t *testing.T) {
+	r, w := BitPipe()
+	errTest := errors.New("test error")
+	r.CloseWithError(errTest)
+	if err := w.WriteBits(5, 0); err != errTest {
+		t.Errorf("WriteBits after CloseWithError: got %v, want %v",
+			err, errTest)
+	}
+	if _, err := r.ReadBits(5); err != io.ErrClosedPipe {
+		t.Errorf("ReadBits after Close: got %v, want %v",
+			err, io.ErrClosedPipe)
+	}
+}
+
+func TestBufferedBitPipe(t *testing.T) {
+	r, w := BufferedBitPipe(4)
+
+	// All writes should complete without a concurrent reader
+	w.WriteBits(3, 0x6)
+	w.WriteBits(9, 0x1ea)
+	w.WriteBits(20, 0xdbeef)
+	w.WriteBits(64, 0x0123456789abcdef)
+	w.Close()
+
+	// Buffered bits should remain readable after the writer closes
+	var v uint64
+	for _, n := range []int{8, 4, 16, 4} {
+		b, err := r.ReadBits(n)
+		if err != nil {
+			t.Fatalf("ReadBits(%v): %v", n, err)
+		}
+		v = (v << n) | b
+	}
+	if v != 0xdeadbeef {
+		t.Errorf("BufferedBitPipe read %x, want deadbeef", v)
+	}
+	if v, err := r.ReadBits(64); err != nil || v != 0x0123456789abcdef {
+		t.Errorf("ReadBits(64): got %x, %v", v, err)
+	}
+	if _, err := r.ReadBits(1); err != EOF {
+		t.Errorf("ReadBits past end: got %v, want EOF", err)
+	}
+
+	// Stream many bits through a small buffer concurrently
+	r, w = BufferedBitPipe(1)
+	go func() {
+		for i := 0; i < 1000; i++ {
+			w.WriteBits(1+i%13, uint64(i))
+		}
+		w.CloseWithError(io.ErrUnexpectedEOF)
+	}()
+	for i := 0; i < 1000; i++ {
+		n := 1+i%13
+		b, err := r.ReadBits(n)
+		if err != nil || b != uint64(i) & (1 << n - 1) {
+			t.Fatalf("ReadBits(%v): got %x, %v", n, b, err)
+		}
+	}
+	if _, err := r.ReadBits(1); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadBits after CloseWithError: got %v", err)
+	}
+}
@@ -38,11 +38,14 @@
 // The BigEndian.Uint* and BigEndian.PutUint* operations use big endian
 // as both bit order within bytes and byte order within integers
 // to be read or written at arbitrary positions in a slice.
+// BigEndian.Bits(dst, src, 14, 5, Right) extracts this field
+// right-aligned into the single byte dst[0],
+// and BigEndian.PutBits(dst, 14, src, 5, Right) inserts it back.
 //
 //
 // Little Endian Bit Ordering
 //
-// To illustrate big-endian bit ordering,
+// To illustrate little-endian bit ordering,
 // a bit-field starting at bit offset 14 and having a width of five bits
 // will contain the most-significant two bits of the second byte
 // and the least-significant three bits of the third byte, as follows:
@@ -53,25 +56,55 @@
 //	| 0 1 2 3 4 5 6 7 | 0 1 2 3 4 5 6 7 | 0 1 2 3 4 5 6 7 | 
 //	+-----------------+-----------------+-----------------+
 //
-// The BigEndian.Uint* and BigEndian.PutUint* operations use big endian
-// as both bit order within bytes and byte order within integers
+// The LittleEndian.Uint* and LittleEndian.PutUint* operations use
+// little endian as both bit order within bytes and byte order within integers
 // to be read or written at arbitrary positions in a slice.
 //
 //
+// Build Tags
+//
+// The package avoids reflection and large precomputed tables,
+// so that it remains usable in embedded decoders built with TinyGo
+// and in browser-side parsers built for WebAssembly.
+// Building with TinyGo, or with the bytebits_small build tag,
+// additionally selects a reduced-footprint mode,
+// in which compact general-purpose loops replace
+// the larger unrolled code paths used for speed elsewhere.
+// The two modes are functionally identical.
+//
+//
+// Errors
+//
+// Operations that fail due to programming errors,
+// such as invalid bit values, mismatched operand lengths,
+// or offsets and widths out of range,
+// panic with one of the typed error values ErrBadBitValue,
+// ErrLengthMismatch, ErrOutOfRange, or ErrShortBuffer,
+// which callers may recover and compare against.
+// The Checked* functions wrap the bitwise Field operations
+// to return such errors instead of panicking.
+//
+//
 // Limitations
 // 
-// These functions could probably be sped up significantly
-// via architecture-specific optimizations
-// similarly to the math/bits primitives,
+// The bulk bitwise operations And, AndNot, Or, Xor, Not, and Count
+// use AVX2 on amd64 processors that support it,
+// falling back to portable code processing 64 bits at a time
+// elsewhere or when built with the purego build tag.
+// This includes arm64, which awaits NEON kernels
+// that can be tested on arm64 hardware or under emulation in CI.
+// The bit-field operations could probably be sped up further
+// via similar architecture-specific optimizations,
 // but this implementation currently does not do so.
 //
-// Still todo: little endian, shift operations, field Leading/Trailing,
+// Still todo: field Leading/Trailing,
 // more/better testing, bit I/O, ...
 //
 package bytebits
 
 import (
-	"math/bits"
+	"encoding/binary"
+	"io"
 )
 
 
@@ -124,89 +157,135 @@ type bytePos struct{
 }
 
 
-// catPos implements the bitGet interface by logically concatenating
-// two existing getters x and y.
-// Returns bits from x until xbits is exhausted, then returns bits from y.
+// BitOrder defines an interface to bit-field operations
+// that depend on bit order.
+// This package provides the BigEndian and LittleEndian implementations.
+// Code parameterized by bit order can accept a BitOrder value
+// and pass it to package-level functions such as CopyBits.
 //
-type catPos struct {
-	x, y bitGet
-	xbits int
+type BitOrder interface {
+
+	Bit(x []byte, xofs int) uint
+	Uint8(x []byte, xofs int) uint8
+	Uint16(x []byte, xofs int) uint16
+	Uint32(x []byte, xofs int) uint32
+	Uint64(x []byte, xofs int) uint64
+	Uint(x []byte, xofs, w int) uint64
+	Uint128(x []byte, xofs int) (hi, lo uint64)
+	Words(x []byte, xofs, w int, dst []uint64) []uint64
+	Bits(z, x []byte, xofs, w int, align Align) []byte
+
+	PutBit(z []byte, zofs int, v uint) []byte
+	PutUint8(z []byte, zofs int, v uint8) []byte
+	PutUint16(z []byte, zofs int, v uint16) []byte
+	PutUint32(z []byte, zofs int, v uint32) []byte
+	PutUint64(z []byte, zofs int, v uint64) []byte
+	PutUint(z []byte, zofs, w int, v uint64) []byte
+	PutUint128(z []byte, zofs int, hi, lo uint64) []byte
+	PutWords(z []byte, zofs, w int, v []uint64) []byte
+	PutBytes(z []byte, zofs int, b []byte) []byte
+	PutBits(z []byte, zofs int, x []byte, w int, align Align) []byte
+	AppendBit(b []byte, n int, v uint) ([]byte, int)
+	AppendUint8(b []byte, n int, v uint8) ([]byte, int)
+	AppendUint16(b []byte, n int, v uint16) ([]byte, int)
+	AppendUint32(b []byte, n int, v uint32) ([]byte, int)
+	AppendUint64(b []byte, n int, v uint64) ([]byte, int)
+	AppendUint(b []byte, n, w int, v uint64) ([]byte, int)
+	AppendBits(b []byte, n int, x []byte, xofs, w int) ([]byte, int)
+	SetBits(z []byte, zofs, w int, b uint) []byte
+
+	Copy(z, x []byte, zofs, xofs, w int) []byte
+	RotateLeft(z, x []byte, rot int) []byte
+	ShiftLeft(z, x []byte, s int) []byte
+	ShiftRight(z, x []byte, s int) []byte
+	BinaryToGray(z, x []byte) []byte
+	GrayToBinary(z, x []byte) []byte
+	SwapRanges(z []byte, ofs1, ofs2, w int)
+
+	Leading(x []byte, b uint) int
+	Trailing(x []byte, b uint) int
+	LeadingAt(x []byte, ofs, w int, b uint) int
+	TrailingAt(x []byte, ofs, w int, b uint) int
+
+	Field(buf []byte, ofs, width int) Field
+	NewReader(rd io.Reader) *Reader
+	NewWriter(wr io.Writer) *Writer
+	NewWriterSize(wr io.Writer, size int) *Writer
+	NewAppendWriter(b []byte) *AppendWriter
+	MultiBitReader(readers ...BitReader) BitReader
+	NewBitReaderAt(ra io.ReaderAt) BitReaderAt
+
+	String() string
+	LSBFirst() bool
+	ByteOrder() binary.ByteOrder
+	AppendByteOrder() binary.AppendByteOrder
 }
 
-func (p *catPos) Set(x, y bitGet, xbits int) {
-	p.x, p.y, p.xbits = x, y, xbits
+var _ BitOrder = BigEndian
+
+// CopyBits copies a bit-field of width w bits starting at offset xofs in x
+// into a field of the same width starting at offset zofs in z,
+// using the bit order specified by order, then returns z.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+func CopyBits(order BitOrder, z, x []byte, zofs, xofs, w int) []byte {
+	return order.Copy(z, x, zofs, xofs, w)
 }
 
 
+var zeroByte = []byte{0}	// just a single zero byte
 
-func (p *catPos) Get(n int) uint64 {
-	if p.xbits == 0 {		// x is exhausted, so just use y
-		return p.y.Get(n)
+
+// Align indicates Left or Right bit-field alignment
+// within a byte slice, for the Bits and PutBits operations.
+// A left-aligned field starts at the first bit of its slice,
+// while a right-aligned field ends at the last bit of its slice.
+type Align bool
+
+const Left Align = false	// Left alignment
+const Right Align = true	// Right alignment
+
+// alignOfs returns the bit offset of a w-bit field with alignment align
+// in a slice of l bytes,
+// panicking with ErrShortBuffer if the field does not fit.
+func alignOfs(l, w int, align Align) int {
+	if w < 0 {
+		panic(ErrOutOfRange)
 	}
-	if n > 64 {
-		n = 64
+	if w > l * 8 {
+		panic(ErrShortBuffer)
 	}
-	if p.xbits >= n {		// just get and return bits from x
-		p.xbits -= n
-		return p.x.Get(n)
+	if align == Right {
+		return l * 8 - w
 	}
-
-	// Handle the transition from x to y.
-	ybits := n - p.xbits
-	v := p.x.Get(p.xbits) << ybits
-	v |= p.y.Get(ybits)
-	p.xbits = 0
-	return v
+	return 0
 }
 
 
-
-// BitOrder defines an interface to bit-field operations
-// that depend on bit order.
-// This package provides the two implementations
-// BigEndian and LittleEndian.
-//
-// XXX
-type bitOrder interface {
-
-	Bit(src []byte, ofs int) uint
-	Uint8(src []byte, ofs int) uint8
-	Uint16(src []byte, ofs int) uint16
-	Uint32(src []byte, ofs int) uint32
-	Uint64(src []byte, ofs int) uint64
-	Bits(dst, src []byte, ofs, bits int) []byte
-
-	SetBit(dst []byte, ofs int, b uint) []byte
-	SetUint8(dst []byte, ofs int, v uint8) []byte
-	SetUint16(dst []byte, ofs int, v uint16) []byte
-	SetUint32(dst []byte, ofs int, v uint32) []byte
-	SetUint64(dst []byte, ofs int, v uint64) []byte
-	SetBits(dst, src []byte, ofs, bits int) []byte
-
-	ShiftLeft(dst, src []byte, n int) []byte
-	ShiftRight(dst, src []byte, n int) []byte
-	RotateLeft(dst, src []byte, n int) []byte
-
-	LeadingZeros(src []byte) int
-	TrailingZeros(src []byte) int
+// checkRange panics if the w-bit range at offset ofs
+// does not lie entirely within a slice of l bytes.
+func checkRange(l, ofs, w int) {
+	if ofs < 0 || w < 0 {
+		panic(ErrOutOfRange)
+	}
+	if ofs + w > l * 8 {
+		panic(ErrShortBuffer)
+	}
 }
 
-
-var zeroByte = []byte{0}	// just a single zero byte
-
-
-// Align indicates Left or Right bit-field alignment
-// for the bit-field Insert and Extract operations.
-//type Align bool
-
-//const Left Align = false	// Left alignment
-//const Right Align = true	// Right alignment
-
+// bitMask returns a word of all zero or all one bits for bit value b,
+// panicking with ErrBadBitValue if b is not 0 or 1.
+func bitMask(b uint) uint64 {
+	if b > 1 {
+		panic(ErrBadBitValue)
+	}
+	return -uint64(b)
+}
 
 func len2(x, y []byte) int {
 	l := len(x)
 	if len(y) != l {
-		panic("input slices must be the same length")
+		panic(ErrLengthMismatch)
 	}
 	return l
 }
@@ -234,51 +313,59 @@ func Grow(z []byte, l int) []byte {
 	return nz[:l]
 }
 
+// appendGrow resizes slice b, holding a bit string of n bits,
+// to hold n+w bits, and returns it.
+// Bytes beyond the first (n+7)/8 are discarded,
+// and the bytes added are cleared, as Grow may reuse stale capacity,
+// so that bits beyond the end of the bit string remain zero.
+func appendGrow(b []byte, n, w int) []byte {
+	l := (n + 7) >> 3
+	b = Grow(b[:l], (n + w + 7) >> 3)
+	clear(b[l:])
+	return b
+}
+
 // And sets z to the bitwise AND of slices x and y, and returns z.
-// The source slices x and y must be of the same length.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
 // Allocates and returns a new destination slice if z is not long enough.
 func And(z, x, y []byte) []byte {
 	l := len2(x, y)
 	z = Grow(z, l)
-	for i := range x {
-		z[i] = x[i] & y[i]
-	}
+	andBytes(z[:l], x, y)
 	return z
 }
 
 // AndNot sets z to the bitwise AND of slices x and NOT y, and returns z.
-// The source slices x and y must be of the same length.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
 // Allocates and returns a new destination slice if z is not long enough.
 func AndNot(z, x, y []byte) []byte {
 	l := len2(x, y)
 	z = Grow(z, l)
-	for i := range x {
-		z[i] = x[i] &^ y[i]
-	}
+	andNotBytes(z[:l], x, y)
 	return z
 }
 
 // Or sets z to the bitwise OR of slices x and y, and returns z.
-// The source slices x and y must be of the same length.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
 // Allocates and returns a new destination slice if z is not long enough.
 func Or(z, x, y []byte) []byte {
 	l := len2(x, y)
 	z = Grow(z, l)
-	for i := range x {
-		z[i] = x[i] | y[i]
-	}
+	orBytes(z[:l], x, y)
 	return z
 }
 
 // Xor sets z to the bitwise XOR of slices x and y, and returns z.
-// The source slices x and y must be of the same length.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
 // Allocates and returns a new destination slice if z is not long enough.
 func Xor(z, x, y []byte) []byte {
 	l := len2(x, y)
 	z = Grow(z, l)
-	for i := range x {
-		z[i] = x[i] ^ y[i]
-	}
+	xorBytes(z[:l], x, y)
 	return z
 }
 
@@ -287,26 +374,19 @@ func Xor(z, x, y []byte) []byte {
 func Not(z, x []byte) []byte {
 	l := len(x)
 	z = Grow(z, l)
-	for i := range x {
-		z[i] = ^x[i]
-	}
+	notBytes(z[:l], x)
 	return z
 }
 
 // Count returns the number of bits with value v (0 or 1) in slice x.
-func Count(x []byte, v uint) (n int) {
+// Panics with ErrBadBitValue if v is not 0 or 1.
+func Count(x []byte, v uint) int {
 	switch v {
 	case 0:
-		for _, v := range x {
-			n += bits.OnesCount8(^v)
-		}
+		return len(x) * 8 - countBytes(x)
 	case 1:
-		for _, v := range x {
-			n += bits.OnesCount8(v)
-		}
-	default:
-		panic("Count: invalid bit value")
+		return countBytes(x)
 	}
-	return n
+	panic(ErrBadBitValue)
 }
 
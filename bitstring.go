@@ -0,0 +1,140 @@
+package bytebits
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+
+// BitString is an immutable sequence of bits of arbitrary length.
+// A BitString holds its bits in big-endian bit order
+// starting at bit offset 0 of an underlying byte slice,
+// in the same canonical form that Field.Canonical produces,
+// with any unused trailing bits of the last byte zero.
+//
+// The zero value is an empty bit string.
+// BitString values may be copied and shared freely.
+// They are not comparable with ==, however;
+// use the Key method to obtain a comparable representation.
+//
+type BitString struct {
+	buf []byte	// Canonical bit content
+	len int		// Length in bits
+}
+
+// MakeBitString returns a BitString containing a copy of the w bits
+// starting at bit offset ofs in buf, interpreted in big-endian bit order.
+func MakeBitString(buf []byte, ofs, w int) BitString {
+	xb, xo := beNorm(buf, ofs)
+	s := BitString{make([]byte, (w + 7) >> 3), w}
+	beCopy(s.buf, xb, 0, xo, w)
+	return s
+}
+
+// Len returns the length of bit string s in bits.
+func (s BitString) Len() int {
+	return s.len
+}
+
+// Bit returns the value of the bit at position i from the left of s.
+// Panics with ErrOutOfRange if i is not within the bit string.
+func (s BitString) Bit(i int) uint {
+	if i < 0 || i >= s.len {
+		panic(ErrOutOfRange)
+	}
+	return uint(s.buf[i >> 3] >> (7 - i&7)) & 1
+}
+
+// Bytes returns the canonical byte representation of bit string s,
+// with its bits starting at the most-significant bit of the first byte.
+// The returned slice aliases the content of s and must not be modified.
+func (s BitString) Bytes() []byte {
+	return s.buf
+}
+
+// prefix returns a new bit string containing the first n bits of s.
+func (s BitString) prefix(n int) BitString {
+	p := BitString{make([]byte, (n + 7) >> 3), n}
+	copy(p.buf, s.buf)
+	if n & 7 != 0 {
+		p.buf[len(p.buf)-1] &= 0xff << (8 - n&7)
+	}
+	return p
+}
+
+// commonPrefix returns the length in bits of the longest common prefix
+// of bit strings x and y.
+func commonPrefix(x, y BitString) int {
+	n := x.len
+	if y.len < n {
+		n = y.len
+	}
+	return BigEndian.CommonPrefixLen(x.buf, y.buf, 0, 0, n)
+}
+
+// Compare compares bit strings x and y bitwise lexicographically,
+// returning -1 if x < y, 0 if x == y, or +1 if x > y.
+// A bit string that is a proper prefix of another orders before it.
+func Compare(x, y BitString) int {
+	c := commonPrefix(x, y)
+	switch {
+	case c < x.len && c < y.len:	// x and y differ at bit c
+		return int(x.Bit(c)) - int(y.Bit(c))
+	case x.len < y.len:
+		return -1
+	case x.len > y.len:
+		return 1
+	}
+	return 0
+}
+
+// Less reports whether bit string x orders before y
+// bitwise lexicographically, as defined by Compare.
+func Less(x, y BitString) bool {
+	return Compare(x, y) < 0
+}
+
+// BitStringSlice attaches the methods of sort.Interface to []BitString,
+// sorting in increasing bitwise lexicographic order.
+type BitStringSlice []BitString
+
+func (s BitStringSlice) Len() int           { return len(s) }
+func (s BitStringSlice) Less(i, j int) bool { return Less(s[i], s[j]) }
+func (s BitStringSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// SortBitStrings sorts a slice of bit strings
+// in increasing bitwise lexicographic order.
+func SortBitStrings(s []BitString) {
+	sort.Sort(BitStringSlice(s))
+}
+
+// Key returns a string that uniquely encodes
+// both the length and the bit content of s,
+// so that bit strings may be used as Go map keys.
+// Bit strings with the same content but different lengths,
+// such as "0" and "00", always yield different keys.
+// The key of a bit string is identical to the key
+// of any BigEndianField having the same width and content.
+func (s BitString) Key() string {
+	return bitsKey(s.len, s.buf)
+}
+
+// String returns a printable representation of bit string s
+// as a sequence of '0' and '1' characters.
+func (s BitString) String() string {
+	str := make([]byte, s.len)
+	for i := range str {
+		str[i] = '0' + byte(s.Bit(i))
+	}
+	return string(str)
+}
+
+// bitsKey returns the comparable map key for a bit sequence of width w
+// whose canonical representation is canon,
+// consisting of the width as a uvarint followed by the canonical bytes.
+func bitsKey(w int, canon []byte) string {
+	k := make([]byte, binary.MaxVarintLen64 + len(canon))
+	n := binary.PutUvarint(k, uint64(w))
+	n += copy(k[n:], canon)
+	return string(k[:n])
+}
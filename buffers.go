@@ -0,0 +1,67 @@
+package bytebits
+
+
+// BuffersReader is a BitReader that reads bits most-significant first
+// directly from a sequence of byte slices, such as net.Buffers
+// holding a packet received into scatter-gather buffers,
+// crossing fragment boundaries transparently without flattening them.
+//
+type BuffersReader struct {
+	bufs [][]byte		// fragments not yet started
+	b []byte		// remainder of the current fragment
+	o int			// bit offset within b[0], 0-7
+	rem int			// total bits remaining
+}
+
+// NewBuffersReader returns a BuffersReader reading the concatenation
+// of the byte slices in bufs, which it does not copy or modify.
+// A net.Buffers value may be passed for bufs directly.
+func NewBuffersReader(bufs [][]byte) *BuffersReader {
+	r := &BuffersReader{bufs: bufs}
+	for _, b := range bufs {
+		r.rem += len(b) * 8
+	}
+	return r
+}
+
+// Len returns the number of bits remaining to be read.
+func (r *BuffersReader) Len() int {
+	return r.rem
+}
+
+// ReadBits implements the BitReader interface,
+// reading n bits, or 64 bits maximum,
+// into the least-significant bits of the returned value,
+// with the first bit read in the most-significant position.
+// Returns EOF without consuming any bits if fewer than n bits remain.
+func (r *BuffersReader) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if n > r.rem {
+		return 0, EOF
+	}
+	for n > 0 {
+		for len(r.b) == 0 {	// advance to the next fragment
+			r.b, r.bufs, r.o = r.bufs[0], r.bufs[1:], 0
+		}
+		k := len(r.b) * 8 - r.o		// bits left in this fragment
+		if k > n {
+			k = n
+		}
+		var p uint64
+		r.b, r.o, p = beGet(r.b, r.o, k)
+		v = v << k | p
+		n -= k
+		r.rem -= k
+	}
+	return v, nil
+}
+
+// PeekBits returns the next n bits, or 64 bits maximum,
+// as ReadBits would, but without consuming them.
+// Returns EOF if fewer than n bits remain.
+func (r *BuffersReader) PeekBits(n int) (uint64, error) {
+	s := *r
+	return s.ReadBits(n)
+}
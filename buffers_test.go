@@ -0,0 +1,39 @@
+package bytebits
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+)
+
+
+func TestBuffersReader(t *testing.T) {
+	flat := make([]byte, 64)
+	rand.Read(flat)
+	bufs := net.Buffers{flat[:3], flat[3:3], flat[3:4], flat[4:20],
+				nil, flat[20:64]}
+	r := NewBuffersReader(bufs)
+
+	ofs := 0
+	for _, n := range []int{5, 20, 64, 1, 64, 64, 13, 64, 64, 64, 64, 64} {
+		if n > r.Len() {
+			n = r.Len()
+		}
+		want := BigEndian.Uint(flat, ofs, n)
+		if p, err := r.PeekBits(n); err != nil || p != want {
+			t.Errorf("PeekBits(%v) at %v: got %x, %v, want %x",
+				n, ofs, p, err, want)
+		}
+		if v, err := r.ReadBits(n); err != nil || v != want {
+			t.Errorf("ReadBits(%v) at %v: got %x, %v, want %x",
+				n, ofs, v, err, want)
+		}
+		ofs += n
+	}
+	if ofs != 512 || r.Len() != 0 {
+		t.Errorf("read %v bits, %v remaining", ofs, r.Len())
+	}
+	if _, err := r.ReadBits(1); err != EOF {
+		t.Errorf("ReadBits at end: got %v, want EOF", err)
+	}
+}
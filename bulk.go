@@ -0,0 +1,80 @@
+package bytebits
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+
+// Portable implementations of the bulk bitwise operations,
+// processing 64 bits at a time.
+// These handle whole slices on architectures without assembly versions,
+// and the short tails left over by the vector loops elsewhere.
+// The destination z has the same length as the sources,
+// and may be identical to either of them but must not otherwise overlap.
+
+func andGeneric(z, x, y []byte) {
+	i := 0
+	for ; i + 8 <= len(x); i += 8 {
+		binary.LittleEndian.PutUint64(z[i:], binary.LittleEndian.Uint64(x[i:]) &
+			binary.LittleEndian.Uint64(y[i:]))
+	}
+	for ; i < len(x); i++ {
+		z[i] = x[i] & y[i]
+	}
+}
+
+func andNotGeneric(z, x, y []byte) {
+	i := 0
+	for ; i + 8 <= len(x); i += 8 {
+		binary.LittleEndian.PutUint64(z[i:], binary.LittleEndian.Uint64(x[i:]) &^
+			binary.LittleEndian.Uint64(y[i:]))
+	}
+	for ; i < len(x); i++ {
+		z[i] = x[i] &^ y[i]
+	}
+}
+
+func orGeneric(z, x, y []byte) {
+	i := 0
+	for ; i + 8 <= len(x); i += 8 {
+		binary.LittleEndian.PutUint64(z[i:], binary.LittleEndian.Uint64(x[i:]) |
+			binary.LittleEndian.Uint64(y[i:]))
+	}
+	for ; i < len(x); i++ {
+		z[i] = x[i] | y[i]
+	}
+}
+
+func xorGeneric(z, x, y []byte) {
+	i := 0
+	for ; i + 8 <= len(x); i += 8 {
+		binary.LittleEndian.PutUint64(z[i:], binary.LittleEndian.Uint64(x[i:]) ^
+			binary.LittleEndian.Uint64(y[i:]))
+	}
+	for ; i < len(x); i++ {
+		z[i] = x[i] ^ y[i]
+	}
+}
+
+func notGeneric(z, x []byte) {
+	i := 0
+	for ; i + 8 <= len(x); i += 8 {
+		binary.LittleEndian.PutUint64(z[i:], ^binary.LittleEndian.Uint64(x[i:]))
+	}
+	for ; i < len(x); i++ {
+		z[i] = ^x[i]
+	}
+}
+
+// countGeneric returns the number of one bits in x.
+func countGeneric(x []byte) (n int) {
+	i := 0
+	for ; i + 8 <= len(x); i += 8 {
+		n += bits.OnesCount64(binary.LittleEndian.Uint64(x[i:]))
+	}
+	for ; i < len(x); i++ {
+		n += bits.OnesCount8(x[i])
+	}
+	return n
+}
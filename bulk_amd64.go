@@ -0,0 +1,88 @@
+//go:build amd64 && !purego && !tinygo
+// +build amd64,!purego,!tinygo
+
+package bytebits
+
+
+// useAVX2 is true if the processor and operating system support AVX2.
+var useAVX2 = hasAVX2()
+
+func hasAVX2() bool
+
+// The AVX2 kernels process n bytes, which must be a positive multiple of 32.
+
+//go:noescape
+func andAVX2(z, x, y *byte, n int)
+
+//go:noescape
+func andNotAVX2(z, x, y *byte, n int)
+
+//go:noescape
+func orAVX2(z, x, y *byte, n int)
+
+//go:noescape
+func xorAVX2(z, x, y *byte, n int)
+
+//go:noescape
+func notAVX2(z, x *byte, n int)
+
+//go:noescape
+func countAVX2(x *byte, n int) int
+
+// avx2Len returns the length of the prefix of a slice of length l
+// to process with the AVX2 kernels, or 0 if they are unavailable.
+func avx2Len(l int) int {
+	if !useAVX2 {
+		return 0
+	}
+	return l &^ 31
+}
+
+func andBytes(z, x, y []byte) {
+	n := avx2Len(len(x))
+	if n > 0 {
+		andAVX2(&z[0], &x[0], &y[0], n)
+	}
+	andGeneric(z[n:], x[n:], y[n:])
+}
+
+func andNotBytes(z, x, y []byte) {
+	n := avx2Len(len(x))
+	if n > 0 {
+		andNotAVX2(&z[0], &x[0], &y[0], n)
+	}
+	andNotGeneric(z[n:], x[n:], y[n:])
+}
+
+func orBytes(z, x, y []byte) {
+	n := avx2Len(len(x))
+	if n > 0 {
+		orAVX2(&z[0], &x[0], &y[0], n)
+	}
+	orGeneric(z[n:], x[n:], y[n:])
+}
+
+func xorBytes(z, x, y []byte) {
+	n := avx2Len(len(x))
+	if n > 0 {
+		xorAVX2(&z[0], &x[0], &y[0], n)
+	}
+	xorGeneric(z[n:], x[n:], y[n:])
+}
+
+func notBytes(z, x []byte) {
+	n := avx2Len(len(x))
+	if n > 0 {
+		notAVX2(&z[0], &x[0], n)
+	}
+	notGeneric(z[n:], x[n:])
+}
+
+func countBytes(x []byte) int {
+	n := avx2Len(len(x))
+	c := 0
+	if n > 0 {
+		c = countAVX2(&x[0], n)
+	}
+	return c + countGeneric(x[n:])
+}
@@ -0,0 +1,135 @@
+//go:build amd64 && !purego && !tinygo
+// +build amd64,!purego,!tinygo
+
+#include "textflag.h"
+
+// func hasAVX2() bool
+TEXT ·hasAVX2(SB), NOSPLIT, $0-1
+	MOVL	$0, AX
+	CPUID
+	CMPL	AX, $7
+	JB	no
+
+	// AVX and OSXSAVE, and the OS saving the YMM registers
+	MOVL	$1, AX
+	MOVL	$0, CX
+	CPUID
+	ANDL	$0x18000000, CX
+	CMPL	CX, $0x18000000
+	JNE	no
+	MOVL	$0, CX
+	XGETBV
+	ANDL	$6, AX
+	CMPL	AX, $6
+	JNE	no
+
+	// AVX2
+	MOVL	$7, AX
+	MOVL	$0, CX
+	CPUID
+	BTL	$5, BX
+	JCC	no
+	MOVB	$1, ret+0(FP)
+	RET
+no:
+	MOVB	$0, ret+0(FP)
+	RET
+
+// BINOP defines an AVX2 kernel computing z = x op y, 32 bytes at a time,
+// from an instruction that combines a memory operand
+// with a register holding the other operand.
+#define BINOP(name, load, op, other) \
+TEXT name(SB), NOSPLIT, $0-32 \
+	MOVQ	z+0(FP), DI \
+	MOVQ	x+8(FP), SI \
+	MOVQ	y+16(FP), DX \
+	MOVQ	n+24(FP), CX \
+loop: \
+	VMOVDQU	(load), Y0 \
+	op	(other), Y0, Y0 \
+	VMOVDQU	Y0, (DI) \
+	ADDQ	$32, SI \
+	ADDQ	$32, DX \
+	ADDQ	$32, DI \
+	SUBQ	$32, CX \
+	JNZ	loop \
+	VZEROUPPER \
+	RET
+
+// func andAVX2(z, x, y *byte, n int)
+BINOP(·andAVX2, SI, VPAND, DX)
+
+// func andNotAVX2(z, x, y *byte, n int)
+// VPANDN computes the complement of its register operand, here y,
+// ANDed with its memory operand, x.
+BINOP(·andNotAVX2, DX, VPANDN, SI)
+
+// func orAVX2(z, x, y *byte, n int)
+BINOP(·orAVX2, SI, VPOR, DX)
+
+// func xorAVX2(z, x, y *byte, n int)
+BINOP(·xorAVX2, SI, VPXOR, DX)
+
+// func notAVX2(z, x *byte, n int)
+TEXT ·notAVX2(SB), NOSPLIT, $0-24
+	MOVQ	z+0(FP), DI
+	MOVQ	x+8(FP), SI
+	MOVQ	n+16(FP), CX
+	VPCMPEQB	Y1, Y1, Y1
+loop:
+	VPXOR	(SI), Y1, Y0
+	VMOVDQU	Y0, (DI)
+	ADDQ	$32, SI
+	ADDQ	$32, DI
+	SUBQ	$32, CX
+	JNZ	loop
+	VZEROUPPER
+	RET
+
+// Bit counts of each 4-bit value, for nibble lookups with VPSHUFB,
+// repeated in each 128-bit lane.
+DATA nibbleCount<>+0x00(SB)/8, $0x0302020102010100
+DATA nibbleCount<>+0x08(SB)/8, $0x0403030203020201
+DATA nibbleCount<>+0x10(SB)/8, $0x0302020102010100
+DATA nibbleCount<>+0x18(SB)/8, $0x0403030203020201
+GLOBL nibbleCount<>(SB), RODATA|NOPTR, $32
+
+DATA nibbleMask<>+0x00(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA nibbleMask<>+0x08(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA nibbleMask<>+0x10(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA nibbleMask<>+0x18(SB)/8, $0x0f0f0f0f0f0f0f0f
+GLOBL nibbleMask<>(SB), RODATA|NOPTR, $32
+
+// func countAVX2(x *byte, n int) int
+// Counts the bits in each nibble by table lookup,
+// then sums the byte counts into four 64-bit accumulators with VPSADBW.
+TEXT ·countAVX2(SB), NOSPLIT, $0-24
+	MOVQ	x+0(FP), SI
+	MOVQ	n+8(FP), CX
+	VMOVDQU	nibbleCount<>(SB), Y4
+	VMOVDQU	nibbleMask<>(SB), Y5
+	VPXOR	Y6, Y6, Y6	// zero, for VPSADBW
+	VPXOR	Y7, Y7, Y7	// accumulators
+loop:
+	VMOVDQU	(SI), Y0
+	VPSRLW	$4, Y0, Y1
+	VPAND	Y5, Y0, Y0
+	VPAND	Y5, Y1, Y1
+	VPSHUFB	Y0, Y4, Y0
+	VPSHUFB	Y1, Y4, Y1
+	VPADDB	Y0, Y1, Y0
+	VPSADBW	Y6, Y0, Y0
+	VPADDQ	Y0, Y7, Y7
+	ADDQ	$32, SI
+	SUBQ	$32, CX
+	JNZ	loop
+
+	// Sum the four accumulators
+	VEXTRACTI128	$1, Y7, X0
+	VPADDQ	X0, X7, X7
+	VPSHUFD	$0x4e, X7, X0
+	VPADDQ	X0, X7, X7
+	VMOVQ	X7, AX
+	VZEROUPPER
+	MOVQ	AX, ret+16(FP)
+	RET
@@ -0,0 +1,32 @@
+//go:build !amd64 || purego || tinygo
+// +build !amd64 purego tinygo
+
+package bytebits
+
+// Portable bulk operations, used on all architectures without
+// assembly kernels. Arm64 is among them until NEON kernels
+// can be run under test there.
+
+func andBytes(z, x, y []byte) {
+	andGeneric(z, x, y)
+}
+
+func andNotBytes(z, x, y []byte) {
+	andNotGeneric(z, x, y)
+}
+
+func orBytes(z, x, y []byte) {
+	orGeneric(z, x, y)
+}
+
+func xorBytes(z, x, y []byte) {
+	xorGeneric(z, x, y)
+}
+
+func notBytes(z, x []byte) {
+	notGeneric(z, x)
+}
+
+func countBytes(x []byte) int {
+	return countGeneric(x)
+}
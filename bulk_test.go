@@ -0,0 +1,78 @@
+package bytebits
+
+import (
+	"bytes"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+
+// TestBulkOps checks the bulk operations, whichever implementation
+// the build selects, against byte-at-a-time reference loops,
+// at lengths around the vector sizes and in place.
+func TestBulkOps(t *testing.T) {
+	ops := []struct {
+		name string
+		f func(z, x, y []byte) []byte
+		ref func(x, y byte) byte
+	}{
+		{"And", And, func(x, y byte) byte { return x & y }},
+		{"AndNot", AndNot, func(x, y byte) byte { return x &^ y }},
+		{"Or", Or, func(x, y byte) byte { return x | y }},
+		{"Xor", Xor, func(x, y byte) byte { return x ^ y }},
+		{"Not", func(z, x, y []byte) []byte { return Not(z, x) },
+			func(x, y byte) byte { return ^x }},
+	}
+	for _, l := range []int{0, 1, 7, 8, 31, 32, 33, 63, 64, 100, 1000, 4099} {
+		x := make([]byte, l)
+		y := make([]byte, l)
+		rand.Read(x)
+		rand.Read(y)
+		want := make([]byte, l)
+		for _, op := range ops {
+			for i := range want {
+				want[i] = op.ref(x[i], y[i])
+			}
+			if z := op.f(nil, x, y); !bytes.Equal(z[:l], want) {
+				t.Errorf("%v of %v bytes: wrong result", op.name, l)
+			}
+			z := append([]byte(nil), x...)
+			if op.f(z, z, y); !bytes.Equal(z, want) {
+				t.Errorf("in-place %v of %v bytes: wrong result",
+					op.name, l)
+			}
+		}
+
+		n := 0
+		for _, b := range x {
+			n += bits.OnesCount8(b)
+		}
+		if c := Count(x, 1); c != n {
+			t.Errorf("Count of %v bytes: got %v, want %v", l, c, n)
+		}
+		if c := Count(x, 0); c != l * 8 - n {
+			t.Errorf("Count zeros of %v bytes: got %v, want %v",
+				l, c, l * 8 - n)
+		}
+	}
+}
+
+func BenchmarkXor(b *testing.B) {
+	x := make([]byte, 64 << 10)
+	y := make([]byte, len(x))
+	z := make([]byte, len(x))
+	b.SetBytes(int64(len(x)))
+	for i := 0; i < b.N; i++ {
+		Xor(z, x, y)
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	x := make([]byte, 64 << 10)
+	rand.Read(x)
+	b.SetBytes(int64(len(x)))
+	for i := 0; i < b.N; i++ {
+		Count(x, 1)
+	}
+}
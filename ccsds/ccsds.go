@@ -0,0 +1,12 @@
+// Package ccsds provides accessors for the headers of CCSDS Space Packets
+// (CCSDS 133.0-B) and TM Space Data Link Protocol transfer frames
+// (CCSDS 132.0-B), as used in spacecraft telemetry and telecommand.
+//
+// Nearly every field of these headers is packed at an unaligned bit offset,
+// so the accessors are built on the bit-field operations
+// of the bytebits package.
+// As in the netbits package, each header field is described
+// by a constant header.Field giving its bit offset and width within the header,
+// and the header types' accessor methods are thin wrappers around these.
+//
+package ccsds
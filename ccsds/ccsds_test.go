@@ -0,0 +1,67 @@
+package ccsds
+
+import (
+	"testing"
+
+	"github.com/bford/bytebits"
+)
+
+
+func TestSpacePacket(t *testing.T) {
+	p := make(SpacePacket, PrimaryHeaderLen+4)
+	p.SetType(Telecommand)
+	p.SetSecHdrFlag(true)
+	p.SetAPID(0x5a5)
+	p.SetSeqFlags(SeqUnsegmented)
+	p.SetSeqCount(uint16(PacketSeqCount.Next(0x3fff)))	// wraps to zero
+	p.SetSeqCount(uint16(PacketSeqCount.Next(uint64(p.SeqCount()))))
+	p.SetDataLen(4)
+
+	want := []byte{0x1d, 0xa5, 0xc0, 0x01, 0x00, 0x03}
+	for i, b := range want {
+		if p[i] != b {
+			t.Fatalf("header byte %v = %02x, want %02x", i, p[i], b)
+		}
+	}
+	if p.Version() != 0 || p.Type() != Telecommand || !p.SecHdrFlag() ||
+			p.APID() != 0x5a5 || p.SeqFlags() != SeqUnsegmented ||
+			p.SeqCount() != 1 || p.Len() != 10 || len(p.Data()) != 4 {
+		t.Errorf("bad header fields")
+	}
+}
+
+func TestTMFrame(t *testing.T) {
+	f := make(TMFrame, 20)
+	f.SetSCID(0x2ab)
+	f.SetVCID(5)
+	f.SetOCFFlag(true)
+	f.SetMCCount(0xfe)
+	f.SetVCCount(0x01)
+	f.SetSegLengthID(3)
+	f.SetFirstHdrPtr(2)
+
+	want := []byte{0x2a, 0xbb, 0xfe, 0x01, 0x18, 0x02}
+	for i, b := range want {
+		if f[i] != b {
+			t.Fatalf("header byte %v = %02x, want %02x", i, f[i], b)
+		}
+	}
+	data := f.Data(0, 4)
+	if len(data) != 10 || len(f.FirstPacket(data)) != 8 {
+		t.Errorf("bad data field length %v", len(data))
+	}
+	f.SetFirstHdrPtr(NoPacketStart)
+	if f.FirstPacket(data) != nil {
+		t.Errorf("FirstPacket should be nil with no packet start")
+	}
+}
+
+func TestShortHeader(t *testing.T) {
+	defer func() {
+		if v := recover(); v != bytebits.ErrShortBuffer {
+			t.Errorf("short header put panicked with %v", v)
+		}
+	}()
+	p := make(SpacePacket, PrimaryHeaderLen-1)
+	p.SetDataLength(1)
+}
@@ -0,0 +1,86 @@
+package ccsds
+
+import (
+	"github.com/bford/bytebits/header"
+)
+
+
+// Bit-field layout of the TM Transfer Frame primary header,
+// including the Transfer Frame Data Field Status.
+const (
+	FrameVersion      header.Field = 0 << 8 | 2
+	FrameSCID         header.Field = 2 << 8 | 10
+	FrameVCID         header.Field = 12 << 8 | 3
+	FrameOCFFlag      header.Field = 15 << 8 | 1
+	FrameMCCount      header.Field = 16 << 8 | 8
+	FrameVCCount      header.Field = 24 << 8 | 8
+	FrameSecHdrFlag   header.Field = 32 << 8 | 1
+	FrameSyncFlag     header.Field = 33 << 8 | 1
+	FramePacketOrder  header.Field = 34 << 8 | 1
+	FrameSegLengthID  header.Field = 35 << 8 | 2
+	FrameFirstHdrPtr  header.Field = 37 << 8 | 11
+)
+
+// Special values of the First Header Pointer.
+const (
+	NoPacketStart = 0x7ff	// No packet starts in the frame's data field
+	IdleData      = 0x7fe	// The frame's data field contains only idle data
+)
+
+// FrameHeaderLen is the length in bytes of the TM frame primary header.
+const FrameHeaderLen = 6
+
+
+// TMFrame is a byte slice holding a TM Transfer Frame,
+// beginning with its primary header.
+type TMFrame []byte
+
+func (f TMFrame) Version() uint8       { return uint8(FrameVersion.Get(f)) }
+func (f TMFrame) SCID() uint16         { return uint16(FrameSCID.Get(f)) }
+func (f TMFrame) VCID() uint8          { return uint8(FrameVCID.Get(f)) }
+func (f TMFrame) OCFFlag() bool        { return FrameOCFFlag.Get(f) != 0 }
+func (f TMFrame) MCCount() uint8       { return uint8(FrameMCCount.Get(f)) }
+func (f TMFrame) VCCount() uint8       { return uint8(FrameVCCount.Get(f)) }
+func (f TMFrame) SecHdrFlag() bool     { return FrameSecHdrFlag.Get(f) != 0 }
+func (f TMFrame) SyncFlag() bool       { return FrameSyncFlag.Get(f) != 0 }
+func (f TMFrame) PacketOrder() bool    { return FramePacketOrder.Get(f) != 0 }
+func (f TMFrame) SegLengthID() uint8   { return uint8(FrameSegLengthID.Get(f)) }
+func (f TMFrame) FirstHdrPtr() uint16  { return uint16(FrameFirstHdrPtr.Get(f)) }
+
+func (f TMFrame) SetVersion(v uint8)      { FrameVersion.Put(f, uint64(v)) }
+func (f TMFrame) SetSCID(v uint16)        { FrameSCID.Put(f, uint64(v)) }
+func (f TMFrame) SetVCID(v uint8)         { FrameVCID.Put(f, uint64(v)) }
+func (f TMFrame) SetOCFFlag(v bool)       { FrameOCFFlag.Put(f, boolBit(v)) }
+func (f TMFrame) SetMCCount(v uint8)      { FrameMCCount.Put(f, uint64(v)) }
+func (f TMFrame) SetVCCount(v uint8)      { FrameVCCount.Put(f, uint64(v)) }
+func (f TMFrame) SetSecHdrFlag(v bool)    { FrameSecHdrFlag.Put(f, boolBit(v)) }
+func (f TMFrame) SetSyncFlag(v bool)      { FrameSyncFlag.Put(f, boolBit(v)) }
+func (f TMFrame) SetPacketOrder(v bool)   { FramePacketOrder.Put(f, boolBit(v)) }
+func (f TMFrame) SetSegLengthID(v uint8)  { FrameSegLengthID.Put(f, uint64(v)) }
+func (f TMFrame) SetFirstHdrPtr(v uint16) { FrameFirstHdrPtr.Put(f, uint64(v)) }
+
+// Data returns the frame's data field, which follows the primary header
+// and occupies the rest of the slice up to any trailer.
+// The caller specifies the length of the secondary header, if present,
+// and of the trailer (Operational Control Field and/or Frame Error Control),
+// since these depend on mission-specific configuration.
+func (f TMFrame) Data(secHdrLen, trailerLen int) []byte {
+	start := FrameHeaderLen
+	if f.SecHdrFlag() {
+		start += secHdrLen
+	}
+	return f[start:len(f)-trailerLen]
+}
+
+// FirstPacket returns the portion of the data field starting with the first
+// packet header, or nil if no packet starts within the data field,
+// given the data field as returned by Data.
+// Bytes preceding it in the data field belong to a packet
+// continued from a previous frame.
+func (f TMFrame) FirstPacket(data []byte) []byte {
+	p := int(f.FirstHdrPtr())
+	if p == NoPacketStart || p == IdleData || p >= len(data) {
+		return nil
+	}
+	return data[p:]
+}
@@ -0,0 +1,98 @@
+package ccsds
+
+import (
+	"github.com/bford/bytebits/header"
+)
+
+
+// Bit-field layout of the Space Packet primary header.
+const (
+	PacketVersion    header.Field = 0 << 8 | 3
+	PacketType       header.Field = 3 << 8 | 1
+	PacketSecHdrFlag header.Field = 4 << 8 | 1
+	PacketAPID       header.Field = 5 << 8 | 11
+	PacketSeqFlags   header.Field = 16 << 8 | 2
+	PacketSeqCount   header.Field = 18 << 8 | 14
+	PacketDataLength header.Field = 32 << 8 | 16
+)
+
+// Values of the Space Packet Type field.
+const (
+	Telemetry   = 0
+	Telecommand = 1
+)
+
+// Values of the Space Packet sequence flags.
+const (
+	SeqContinuation = 0	// Continuation segment of user data
+	SeqFirst        = 1	// First segment of user data
+	SeqLast         = 2	// Last segment of user data
+	SeqUnsegmented  = 3	// Unsegmented user data
+)
+
+// IdleAPID is the APID reserved for idle packets.
+const IdleAPID = 0x7ff
+
+// PrimaryHeaderLen is the length in bytes of the packet primary header.
+const PrimaryHeaderLen = 6
+
+
+// SpacePacket is a byte slice holding a Space Packet,
+// beginning with its primary header.
+type SpacePacket []byte
+
+func (p SpacePacket) Version() uint8     { return uint8(PacketVersion.Get(p)) }
+func (p SpacePacket) Type() uint8        { return uint8(PacketType.Get(p)) }
+func (p SpacePacket) SecHdrFlag() bool   { return PacketSecHdrFlag.Get(p) != 0 }
+func (p SpacePacket) APID() uint16       { return uint16(PacketAPID.Get(p)) }
+func (p SpacePacket) SeqFlags() uint8    { return uint8(PacketSeqFlags.Get(p)) }
+func (p SpacePacket) SeqCount() uint16   { return uint16(PacketSeqCount.Get(p)) }
+func (p SpacePacket) DataLength() uint16 { return uint16(PacketDataLength.Get(p)) }
+
+func (p SpacePacket) SetVersion(v uint8)     { PacketVersion.Put(p, uint64(v)) }
+func (p SpacePacket) SetType(v uint8)        { PacketType.Put(p, uint64(v)) }
+func (p SpacePacket) SetAPID(v uint16)       { PacketAPID.Put(p, uint64(v)) }
+func (p SpacePacket) SetSeqFlags(v uint8)    { PacketSeqFlags.Put(p, uint64(v)) }
+func (p SpacePacket) SetSeqCount(v uint16)   { PacketSeqCount.Put(p, uint64(v)) }
+func (p SpacePacket) SetDataLength(v uint16) { PacketDataLength.Put(p, uint64(v)) }
+
+func (p SpacePacket) SetSecHdrFlag(v bool) {
+	PacketSecHdrFlag.Put(p, boolBit(v))
+}
+
+// IsIdle reports whether p is an idle packet.
+func (p SpacePacket) IsIdle() bool {
+	return p.APID() == IdleAPID
+}
+
+// Len returns the total length of the packet in bytes,
+// as indicated by its primary header.
+// The Packet Data Length field holds one less than
+// the length of the packet data field.
+func (p SpacePacket) Len() int {
+	return PrimaryHeaderLen + int(p.DataLength()) + 1
+}
+
+// Data returns the packet data field following the primary header,
+// including any secondary header,
+// limited by the Packet Data Length field and the length of the slice.
+func (p SpacePacket) Data() []byte {
+	end := p.Len()
+	if end > len(p) {
+		end = len(p)
+	}
+	return p[PrimaryHeaderLen:end]
+}
+
+// SetDataLen sets the Packet Data Length field
+// for a packet data field of n bytes, where n must be at least 1.
+func (p SpacePacket) SetDataLen(n int) {
+	p.SetDataLength(uint16(n - 1))
+}
+
+func boolBit(v bool) uint64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,236 @@
+package bytebits
+
+import (
+	"math/bits"
+)
+
+
+// The checksum functions compute classic byte-oriented checksums
+// directly over bit fields at arbitrary offsets and widths
+// in big-endian bit order, without first copying them to aligned buffers.
+// The bytes of a field are grouped starting from the field's first bit,
+// so that byte j of a field consists of its bits 8j through 8j+7,
+// and any final partial byte is padded on the right with zero bits.
+
+// beWords calls fn with each successive 64-bit word of the w-bit field
+// starting at offset o in b, in big-endian bit order,
+// and with the number of bytes of the field the word holds.
+// A final partial word is padded on the right with zero bits.
+func beWords(b []byte, o, w int, fn func(v uint64, n int)) {
+	b, o = beNorm(b, o)
+	var v uint64
+	for w >= 64 {
+		b, o, v = beGet64(b, o)
+		fn(v, 8)
+		w -= 64
+	}
+	if w > 0 {
+		b, o, v = beGet(b, o, w)
+		fn(v << (64 - w), (w + 7) >> 3)
+	}
+}
+
+// Fletcher16 returns the Fletcher-16 checksum
+// of the bytes of the w-bit field starting at offset xofs in x.
+func Fletcher16(x []byte, xofs, w int) uint16 {
+	var s1, s2 uint32
+	beWords(x, xofs, w, func(v uint64, n int) {
+		for i := 0; i < n; i++ {
+			s1 += uint32(v >> 56)
+			s2 += s1
+			v <<= 8
+		}
+		s1 %= 255
+		s2 %= 255
+	})
+	return uint16(s2 << 8 | s1)
+}
+
+// Fletcher32 returns the Fletcher-32 checksum
+// of the big-endian 16-bit words of the w-bit field
+// starting at offset xofs in x.
+// A final odd byte is padded on the right with zero bits.
+func Fletcher32(x []byte, xofs, w int) uint32 {
+	var s1, s2 uint64
+	beWords(x, xofs, w, func(v uint64, n int) {
+		for i := 0; i < n; i += 2 {
+			s1 += v >> 48
+			s2 += s1
+			v <<= 16
+		}
+		s1 %= 65535
+		s2 %= 65535
+	})
+	return uint32(s2 << 16 | s1)
+}
+
+// Adler32 returns the Adler-32 checksum, as used by zlib,
+// of the bytes of the w-bit field starting at offset xofs in x.
+// For byte-aligned fields the result is identical
+// to that of the standard hash/adler32 package.
+func Adler32(x []byte, xofs, w int) uint32 {
+	s1, s2 := uint32(1), uint32(0)
+	beWords(x, xofs, w, func(v uint64, n int) {
+		for i := 0; i < n; i++ {
+			s1 += uint32(v >> 56)
+			s2 += s1
+			v <<= 8
+		}
+		s1 %= 65521
+		s2 %= 65521
+	})
+	return s2 << 16 | s1
+}
+
+
+// CRCParams describes a cyclic redundancy check
+// in the style of the common CRC catalogs,
+// for computing CRCs bit by bit over bit-exact message contents.
+// The message bits are processed in stream order,
+// each entering the CRC register as its most-significant bit,
+// so a CRC conventionally described as reflected on input,
+// such as CRC-32, is computed over an LSB-first stream
+// with RefOut set.
+type CRCParams struct {
+	Width int		// Register width in bits, from 1 to 64
+	Poly uint64		// Generator polynomial, without its top term
+	Init uint64		// Initial register value
+	XorOut uint64		// Value XORed with the final register
+	RefOut bool		// Reflect the final register before XorOut
+}
+
+// Parameters of some widely-used CRCs.
+var (
+	// CRC32 is the CRC-32 of Ethernet, zlib, and PNG,
+	// for use over LSB-first streams.
+	CRC32 = CRCParams{32, 0x04c11db7, 0xffffffff, 0xffffffff, true}
+
+	// CRC16CCITT is the CRC-16/CCITT-FALSE of many serial protocols,
+	// for use over MSB-first streams.
+	CRC16CCITT = CRCParams{16, 0x1021, 0xffff, 0, false}
+)
+
+// CRC computes a cyclic redundancy check incrementally,
+// one or more bits at a time.
+type CRC struct {
+	p CRCParams
+	mask uint64		// Mask of the register's Width bits
+	reg uint64		// Current register value
+}
+
+// NewCRC returns a CRC computing the check described by p.
+// Panics with ErrOutOfRange if p.Width is not from 1 to 64.
+func NewCRC(p CRCParams) *CRC {
+	if p.Width < 1 || p.Width > 64 {
+		panic(ErrOutOfRange)
+	}
+	c := &CRC{p: p, mask: ^uint64(0) >> (64 - p.Width)}
+	c.Reset()
+	return c
+}
+
+// Reset restores the CRC to its initial state.
+func (c *CRC) Reset() {
+	c.reg = c.p.Init & c.mask
+}
+
+// Update feeds the least-significant n bits of v, at most 64,
+// into the CRC, most-significant bit first.
+func (c *CRC) Update(n int, v uint64) {
+	if n > 64 {
+		n = 64
+	}
+	top := uint(c.p.Width - 1)
+	for i := n - 1; i >= 0; i-- {
+		fb := (c.reg >> top ^ v >> uint(i)) & 1
+		c.reg = c.reg << 1 & c.mask
+		if fb != 0 {
+			c.reg ^= c.p.Poly & c.mask
+		}
+	}
+}
+
+// UpdateBits feeds the w-bit field starting at offset xofs in x
+// into the CRC in big-endian bit order, first bit first.
+func (c *CRC) UpdateBits(x []byte, xofs, w int) {
+	beWords(x, xofs, w, func(v uint64, _ int) {
+		k := 64			// bits of this word in the field
+		if w < 64 {
+			k = w
+		}
+		c.Update(k, v >> (64 - k))
+		w -= k
+	})
+}
+
+// Sum returns the CRC of the bits fed in so far.
+func (c *CRC) Sum() uint64 {
+	r := c.reg
+	if c.p.RefOut {
+		r = bits.Reverse64(r) >> (64 - c.p.Width)
+	}
+	return (r ^ c.p.XorOut) & c.mask
+}
+
+
+// NewCRCReader returns a BitReader that reads from r,
+// feeding every bit successfully read into c, in stream order,
+// so that a decoder can validate a CRC without a second pass.
+// Order specifies the bit order of r, and its LSBFirst method
+// determines the order of the bits of each value read.
+func NewCRCReader(r BitReader, order BitOrder, c *CRC) BitReader {
+	return &crcReader{r, c, order.LSBFirst()}
+}
+
+type crcReader struct {
+	r BitReader
+	c *CRC
+	lsb bool
+}
+
+func (cr *crcReader) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if v, err = cr.r.ReadBits(n); err == nil {
+		crcUpdate(cr.c, n, v, cr.lsb)
+	}
+	return v, err
+}
+
+// NewCRCWriter returns a BitWriter that writes to w,
+// feeding every bit successfully written into c, in stream order,
+// so that an encoder can append a CRC without a second pass.
+// Order specifies the bit order of w, as for NewCRCReader.
+// If w is nil, the writer only feeds bits to c.
+func NewCRCWriter(w BitWriter, order BitOrder, c *CRC) BitWriter {
+	return &crcWriter{w, c, order.LSBFirst()}
+}
+
+type crcWriter struct {
+	w BitWriter
+	c *CRC
+	lsb bool
+}
+
+func (cw *crcWriter) WriteBits(n int, v uint64) error {
+	if n > 64 {
+		n = 64
+	}
+	if cw.w != nil {
+		if err := cw.w.WriteBits(n, v); err != nil {
+			return err
+		}
+	}
+	crcUpdate(cw.c, n, v, cw.lsb)
+	return nil
+}
+
+// crcUpdate feeds n bits of v into c in stream order,
+// reversing them first if the stream is LSB-first.
+func crcUpdate(c *CRC, n int, v uint64, lsb bool) {
+	if lsb && n > 0 {
+		v = bits.Reverse64(v) >> (64 - n)
+	}
+	c.Update(n, v)
+}
@@ -0,0 +1,116 @@
+package bytebits
+
+import (
+	"bytes"
+	"hash/adler32"
+	"hash/crc32"
+	"math/rand"
+	"testing"
+)
+
+
+func TestChecksums(t *testing.T) {
+	abcde := []byte("abcde")
+	if s := Fletcher16(abcde, 0, 40); s != 0xc8f0 {
+		t.Errorf("Fletcher16(abcde): got %x, want c8f0", s)
+	}
+	if s := Adler32([]byte("Wikipedia"), 0, 72); s != 0x11e60398 {
+		t.Errorf("Adler32(Wikipedia): got %x, want 11e60398", s)
+	}
+
+	// Checksums of unaligned fields match those of aligned copies
+	for _, w := range []int{0, 8, 13, 64, 72, 1000, 8 * 5803} {
+		x := make([]byte, (w + 7) >> 3 + 1)
+		rand.Read(x)
+		for _, ofs := range []int{0, 3, 8} {
+			if ofs + w > len(x) * 8 {
+				continue
+			}
+			a := BigEndian.Copy(nil, x, 0, ofs, w)
+			if got, want := Adler32(x, ofs, w), adler32.Checksum(a);
+					got != want {
+				t.Errorf("Adler32 w=%v ofs=%v: got %x, want %x",
+					w, ofs, got, want)
+			}
+
+			var s1, s2 uint32
+			for _, b := range a {
+				s1 = (s1 + uint32(b)) % 255
+				s2 = (s2 + s1) % 255
+			}
+			if got := Fletcher16(x, ofs, w); got != uint16(s2 << 8 | s1) {
+				t.Errorf("Fletcher16 w=%v ofs=%v: got %x, want %x",
+					w, ofs, got, s2 << 8 | s1)
+			}
+
+			s1, s2 = 0, 0
+			a = append(a, 0)
+			for i := 0; i + 1 < len(a); i += 2 {
+				s1 = (s1 + uint32(a[i]) << 8 + uint32(a[i+1])) % 65535
+				s2 = (s2 + s1) % 65535
+			}
+			if got := Fletcher32(x, ofs, w); got != s2 << 16 | s1 {
+				t.Errorf("Fletcher32 w=%v ofs=%v: got %x, want %x",
+					w, ofs, got, s2 << 16 | s1)
+			}
+		}
+	}
+}
+
+// wrappedOrder is a BitOrder implemented outside the package,
+// delegating to an underlying order.
+type wrappedOrder struct {
+	BitOrder
+}
+
+func TestCRC(t *testing.T) {
+	check := []byte("123456789")
+
+	// CRC-32 is defined over an LSB-first stream
+	c := NewCRC(CRC32)
+	r := NewCRCReader(LittleEndian.NewReader(bytes.NewReader(check)),
+			LittleEndian, c)
+	for i := 0; i < 9; i++ {
+		r.ReadBits(5)		// unaligned reads
+		r.ReadBits(3)
+	}
+	if s := c.Sum(); s != 0xcbf43926 || s != uint64(crc32.ChecksumIEEE(check)) {
+		t.Errorf("CRC-32: got %x, want cbf43926", s)
+	}
+
+	// The bit order is taken from the order itself, not its type
+	c.Reset()
+	w := NewCRCWriter(nil, wrappedOrder{LittleEndian}, c)
+	for _, b := range check {
+		w.WriteBits(8, uint64(b))
+	}
+	if s := c.Sum(); s != 0xcbf43926 {
+		t.Errorf("CRC-32 via wrapped order: got %x, want cbf43926", s)
+	}
+
+	// CRC-16/CCITT-FALSE over an MSB-first stream
+	c = NewCRC(CRC16CCITT)
+	w = NewCRCWriter(nil, BigEndian, c)
+	for _, b := range check {
+		w.WriteBits(8, uint64(b))
+	}
+	if s := c.Sum(); s != 0x29b1 {
+		t.Errorf("CRC-16/CCITT: got %x, want 29b1", s)
+	}
+
+	// UpdateBits agrees with Update at any offset
+	x := make([]byte, 40)
+	rand.Read(x)
+	for _, ofs := range []int{0, 3, 17} {
+		c.Reset()
+		c.UpdateBits(x, ofs, 250)
+		d := NewCRC(CRC16CCITT)
+		for i := 0; i < 250; i++ {
+			d.Update(1, uint64(BigEndian.Bit(x, ofs + i)))
+		}
+		if c.Sum() != d.Sum() {
+			t.Errorf("UpdateBits at %v: got %x, want %x",
+				ofs, c.Sum(), d.Sum())
+		}
+	}
+}
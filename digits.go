@@ -0,0 +1,84 @@
+package bytebits
+
+
+// DigitOrder indicates the order in which a DigitIterator
+// yields the digits of a bit field.
+type DigitOrder bool
+
+const MSDFirst DigitOrder = false	// Most-significant digit first
+const LSDFirst DigitOrder = true	// Least-significant digit first
+
+
+// DigitIterator yields the successive k-bit digits of a bit field,
+// as used for the keys in a radix sort.
+//
+// The digits of a field are defined relative to its least-significant end,
+// as in the base-2^k representation of the field's integer value:
+// digit 0 consists of the last k bits of the field,
+// digit 1 of the k bits before that, and so on.
+// If the field's width is not a multiple of k,
+// the most-significant digit is short, containing the leftover leading bits.
+// An LSD radix sort thus makes NumDigits passes from digit 0 upwards,
+// while an MSD radix sort starts from the most-significant digit.
+//
+type DigitIterator struct {
+	f field			// Field being iterated over
+	k int			// Digit width in bits
+	i int			// Index of next digit to yield
+	n int			// Total number of digits
+	ord DigitOrder		// Iteration order
+}
+
+// Digits returns a DigitIterator over the k-bit digits of field z,
+// where k must be between 1 and 64, in the specified order.
+// Panics with ErrOutOfRange if k is not within this range.
+// The iterator refers to the field's underlying slice but not to z itself,
+// so z may subsequently be modified or reused independently.
+func (z *BigEndianField) Digits(k int, ord DigitOrder) *DigitIterator {
+	if k < 1 || k > 64 {
+		panic(ErrOutOfRange)
+	}
+	return &DigitIterator{f: field(*z), k: k, n: (z.w + k - 1) / k,
+				ord: ord}
+}
+
+// NumDigits returns the total number of digits the iterator yields.
+func (it *DigitIterator) NumDigits() int {
+	return it.n
+}
+
+// Digit returns digit i of the field, where digit 0 is least significant,
+// regardless of the iterator's order or current position.
+// Panics with ErrOutOfRange if there is no digit i.
+func (it *DigitIterator) Digit(i int) uint64 {
+	if i < 0 || i >= it.n {
+		panic(ErrOutOfRange)
+	}
+	end := it.f.w - i*it.k		// bit offset just past digit i
+	start := end - it.k
+	if start < 0 {
+		start = 0
+	}
+	xb, xo := beNorm(it.f.b, it.f.o + start)
+	_, _, v := beGet(xb, xo, end - start)
+	return v
+}
+
+// Next returns the next digit in the iterator's order
+// and true, or zero and false if all digits have been yielded.
+func (it *DigitIterator) Next() (d uint64, ok bool) {
+	if it.i >= it.n {
+		return 0, false
+	}
+	i := it.i
+	if it.ord == MSDFirst {
+		i = it.n - 1 - i
+	}
+	it.i++
+	return it.Digit(i), true
+}
+
+// Reset rewinds the iterator to yield its digits again from the start.
+func (it *DigitIterator) Reset() {
+	it.i = 0
+}
@@ -0,0 +1,120 @@
+package bytebits
+
+import (
+	"math/bits"
+)
+
+
+// The Elias universal codes encode positive integers
+// without any parameter, in a number of bits growing
+// logarithmically with the value,
+// as used in index compression and succinct data structures.
+// They are read and written most-significant bit first,
+// and so require MSB-first bit streams.
+// Reading or writing a code for zero fails with ErrOutOfRange,
+// and decoding a code whose value would not fit in a uint64
+// fails with ErrInvalidCode.
+
+// WriteEliasGamma writes v, which must be positive,
+// to w as an Elias gamma code:
+// with v n bits long, n-1 zero bits followed by v in n bits.
+func WriteEliasGamma(w BitWriter, v uint64) error {
+	if v == 0 {
+		return ErrOutOfRange
+	}
+	n := bits.Len64(v)
+	if err := w.WriteBits(n - 1, 0); err != nil {
+		return err
+	}
+	return w.WriteBits(n, v)
+}
+
+// ReadEliasGamma reads an Elias gamma code from r.
+func ReadEliasGamma(r BitReader) (uint64, error) {
+	lz, err := readUnary(r)
+	if err != nil {
+		return 0, err
+	}
+	if lz > 63 {
+		return 0, ErrInvalidCode
+	}
+	x, err := readRest(r, int(lz))
+	if err != nil {
+		return 0, err
+	}
+	return 1 << lz | x, nil
+}
+
+// WriteEliasDelta writes v, which must be positive,
+// to w as an Elias delta code:
+// with v n bits long, n as an Elias gamma code
+// followed by the n-1 bits of v below its leading one bit.
+func WriteEliasDelta(w BitWriter, v uint64) error {
+	if v == 0 {
+		return ErrOutOfRange
+	}
+	n := bits.Len64(v)
+	if err := WriteEliasGamma(w, uint64(n)); err != nil {
+		return err
+	}
+	return w.WriteBits(n - 1, v)
+}
+
+// ReadEliasDelta reads an Elias delta code from r.
+func ReadEliasDelta(r BitReader) (uint64, error) {
+	n, err := ReadEliasGamma(r)
+	if err != nil {
+		return 0, err
+	}
+	if n > 64 {
+		return 0, ErrInvalidCode
+	}
+	x, err := readRest(r, int(n) - 1)
+	if err != nil {
+		return 0, err
+	}
+	return 1 << (n - 1) | x, nil
+}
+
+// WriteEliasOmega writes v, which must be positive,
+// to w as an Elias omega code:
+// a sequence of groups, each giving the length minus one of the next,
+// starting from a group of two bits and ending with v itself,
+// followed by a zero bit.
+func WriteEliasOmega(w BitWriter, v uint64) error {
+	if v == 0 {
+		return ErrOutOfRange
+	}
+	var groups [8]uint64		// at most 7 for 64-bit values
+	g := 0
+	for ; v > 1; v = uint64(bits.Len64(v) - 1) {
+		groups[g] = v
+		g++
+	}
+	for g--; g >= 0; g-- {
+		if err := w.WriteBits(bits.Len64(groups[g]), groups[g]); err != nil {
+			return err
+		}
+	}
+	return w.WriteBits(1, 0)
+}
+
+// ReadEliasOmega reads an Elias omega code from r.
+func ReadEliasOmega(r BitReader) (uint64, error) {
+	n := uint64(1)
+	b, err := r.ReadBits(1)
+	for ; err == nil && b != 0; b, err = readRest(r, 1) {
+		if n > 63 {
+			return 0, ErrInvalidCode
+		}
+		x, err := readRest(r, int(n))
+		if err != nil {
+			return 0, err
+		}
+		n = 1 << n | x
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
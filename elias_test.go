@@ -0,0 +1,64 @@
+package bytebits
+
+import (
+	"bytes"
+	"testing"
+)
+
+
+func TestElias(t *testing.T) {
+	codes := []struct {
+		v uint64
+		gamma, delta, omega string
+	}{
+		{1, "1", "1", "0"},
+		{2, "010", "0100", "100"},
+		{4, "00100", "01100", "101000"},
+		{10, "0001010", "00100010", "1110100"},
+		{17, "000010001", "001010001", "10100100010"},
+	}
+	for _, c := range codes {
+		for _, e := range []struct {
+			name string
+			fn func(BitWriter, uint64) error
+			want string
+		}{
+			{"gamma", WriteEliasGamma, c.gamma},
+			{"delta", WriteEliasDelta, c.delta},
+			{"omega", WriteEliasOmega, c.omega},
+		} {
+			w := NewAppendWriter(nil)
+			e.fn(w, c.v)
+			if s := BigEndian.Field(w.Bytes(), 0, w.Len()).String();
+					s != e.want {
+				t.Errorf("Elias %v(%v): got %v, want %v",
+					e.name, c.v, s, e.want)
+			}
+		}
+	}
+
+	// Round trips up to the largest values
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	vals := []uint64{1, 2, 3, 1000, 1 << 32, 1 << 63, ^uint64(0)}
+	for _, v := range vals {
+		WriteEliasGamma(w, v)
+		WriteEliasDelta(w, v)
+		WriteEliasOmega(w, v)
+	}
+	w.Flush()
+	r := NewReader(&out)
+	for _, v := range vals {
+		g, err1 := ReadEliasGamma(r)
+		d, err2 := ReadEliasDelta(r)
+		o, err3 := ReadEliasOmega(r)
+		if g != v || d != v || o != v ||
+				err1 != nil || err2 != nil || err3 != nil {
+			t.Errorf("Elias codes of %x: got %x, %x, %x", v, g, d, o)
+		}
+	}
+
+	if err := WriteEliasGamma(w, 0); err != ErrOutOfRange {
+		t.Errorf("WriteEliasGamma(0): got %v", err)
+	}
+}
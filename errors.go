@@ -0,0 +1,46 @@
+package bytebits
+
+import (
+	"errors"
+)
+
+
+// Errors reported by this package.
+//
+// Operations that can fail due to their input data,
+// such as reading from a bit stream, return these as error values.
+// Operations on byte slices and fields whose failures indicate
+// a programming error, such as an invalid bit value or mismatched
+// slice lengths, instead panic with one of these error values,
+// which callers may recover and test for programmatically.
+//
+var (
+	// ErrShortBuffer indicates a buffer too small to hold a bit-field.
+	ErrShortBuffer = errors.New("bytebits: buffer too short")
+
+	// ErrOutOfRange indicates a bit offset, width, or index
+	// outside the range an operation supports.
+	ErrOutOfRange = errors.New("bytebits: bit offset or width out of range")
+
+	// ErrLengthMismatch indicates operands that must be the same length
+	// but are not.
+	ErrLengthMismatch = errors.New("bytebits: operand lengths differ")
+
+	// ErrFieldType indicates operand fields of incompatible types,
+	// such as a BigEndianField combined with a LittleEndianField.
+	ErrFieldType = errors.New("bytebits: incompatible field types")
+
+	// ErrBadBitValue indicates a bit value other than 0 or 1.
+	ErrBadBitValue = errors.New("bytebits: invalid bit value")
+
+	// ErrClosed indicates a write to a stream Writer after Close.
+	ErrClosed = errors.New("bytebits: write to closed writer")
+
+	// ErrNotSeeker indicates a seek on a stream whose underlying
+	// reader does not implement io.Seeker.
+	ErrNotSeeker = errors.New("bytebits: underlying reader cannot seek")
+
+	// ErrInvalidCode indicates a malformed variable-length code
+	// or encoding in data being decoded.
+	ErrInvalidCode = errors.New("bytebits: invalid variable-length code")
+)
@@ -1,5 +1,10 @@
 package bytebits
 
+import (
+	"iter"
+	"math/bits"
+)
+
 
 // Field is an interface to an object representing a bit-field
 // providing bit-stream I/O and bit-manipulation operations.
@@ -16,11 +21,55 @@ type field struct{
 	b []byte	// Underlying byte slice
 	o int		// Bit offset within current byte, 0-7
 	w int		// Total width of the field in bits
+	p Padding	// Trailing partial byte policy for byte I/O
+}
+
+
+// Padding specifies how a field's byte-oriented I/O methods,
+// such as WriteTo and ReadFrom,
+// treat a trailing partial byte when the field's width
+// is not a multiple of 8,
+// and how a Writer completes the final partial byte of its stream.
+// PadZeros and PadOnes complete the partial byte
+// with zero or one bits, respectively, when writing,
+// and consume and discard the extra bits of the last byte when reading.
+// More generally, any Padding from 0x00 to 0xff is a byte pattern
+// whose bits at the unused positions of the partial byte fill them,
+// so for example Padding(0x55) pads with alternating zeros and ones.
+// Truncate instead omits the partial byte,
+// leaving the leftover bits in the field.
+type Padding int
+
+const (
+	PadZeros Padding = 0x00		// Pad with zero bits (the default)
+	PadOnes Padding = 0xff		// Pad with one bits
+	Truncate Padding = -1		// Omit a trailing partial byte
+)
+
+// bits returns the 8-o bits of pattern p that complete a byte
+// whose first o bits are in use, in the order that
+// an MSB-first or LSB-first stream writes them.
+func (p Padding) bits(o int, lsb bool) uint64 {
+	v := uint64(p & 0xff)
+	if lsb {
+		return v >> o
+	}
+	return v & (1 << (8 - o) - 1)
+}
+
+// sameStart reports whether fields with normalized slices and offsets
+// xb, xo and zb, zo start at the same bit of the same buffer,
+// as when an operation is applied to a field in place.
+func sameStart(xb, zb []byte, xo, zo int) bool {
+	return xo == zo && len(xb) > 0 && len(zb) > 0 && &xb[0] == &zb[0]
 }
 
 // Field is an interface to a bit-field
 // providing common bit manipulation operations.
 type Field interface {
+	Len() int			// Width in bits
+	Slice(ofs, w int) Field		// Sub-field view
+	Reader() BitReader		// Non-destructive read cursor
 	Set(x Field) Field		// Set to x
 	And(x, y Field) Field		// Set to x & y
 	AndNot(x, y Field) Field	// Set to x &^ y
@@ -28,7 +77,188 @@ type Field interface {
 	Xor(x, y Field) Field		// Set to x ^ y
 	Not(x Field) Field		// Set to ^x
 	Count(b uint) int		// Count bits with value b
+	FindFirst(b uint) int		// Offset of first bit b, or -1
+	Next(b uint, from int) int	// Offset of next bit b, or -1
+	Ones() iter.Seq[int]		// Offsets of bits with value 1
+	Zeros() iter.Seq[int]		// Offsets of bits with value 0
+	Words() iter.Seq2[int, uint64]	// Contents 64 bits at a time
+	Equal(x Field) bool		// Same width and contents as x
+	Compare(x Field) int		// Compare contents as integers
 	Fill(b uint)			// Fill with bit value b
+	FillPattern(pattern uint64, n int) // Fill with repeating pattern
 	RotateLeft(x Field, rot int) Field
-	// XXX ShiftLeft, ...
+	ShiftLeft(x Field, s int) Field
+	ShiftRight(x Field, s int) Field
+	Reverse(x Field) Field		// Set to x in reverse bit order
+	BinaryToGray(x Field) Field	// Set to Gray code of x
+	GrayToBinary(x Field) Field	// Set to binary value of Gray x
+	Swap(x Field)			// Exchange contents with x
+	InsertBits(ofs, n int)		// Insert n zero bits, growing
+	DeleteBits(ofs, n int)		// Delete n bits, shrinking
+	Hash(seed uint64) uint64	// Alignment-independent hash
+	Canonical() []byte		// Copy into a fresh aligned buffer
+	Bytes(align Align) []byte	// Copy with chosen alignment
+	AppendBytes(dst []byte, align Align) []byte
+	Key() string			// Comparable map key
+	String() string			// Binary digits
+}
+
+
+// CheckOperands reports whether fields x may be used as operands
+// of a bitwise operation such as And or Xor into destination field z.
+// Returns ErrFieldType if any operand is not of the same type as z,
+// ErrLengthMismatch if any operand's width differs from z's,
+// or nil if the operation can proceed.
+// The methods of Field panic instead of returning these errors;
+// the Checked* functions wrap them with these checks.
+func CheckOperands(z Field, x ...Field) error {
+	for _, xf := range x {
+		if !sameFieldType(z, xf) {
+			return ErrFieldType
+		}
+		if xf.Len() != z.Len() {
+			return ErrLengthMismatch
+		}
+	}
+	return nil
+}
+
+// sameFieldType reports whether fields z and x have the same concrete type.
+func sameFieldType(z, x Field) bool {
+	switch z.(type) {
+	case *BigEndianField:
+		_, ok := x.(*BigEndianField)
+		return ok
+	case *LittleEndianField:
+		_, ok := x.(*LittleEndianField)
+		return ok
+	}
+	return false
+}
+
+// CheckedSet sets field z to x as z.Set(x) does,
+// but returns ErrLengthMismatch instead of misbehaving
+// if the widths of z and x differ.
+// Fields of different bit orders are converted as in Set.
+func CheckedSet(z, x Field) error {
+	_, zok := z.(fieldDigits)
+	_, xok := x.(fieldDigits)
+	switch {
+	case !zok || !xok:
+		return ErrFieldType
+	case x.Len() != z.Len():
+		return ErrLengthMismatch
+	}
+	z.Set(x)
+	return nil
+}
+
+// CheckedAnd sets z to x & y, returning an error instead of panicking
+// if the operands are incompatible as reported by CheckOperands.
+func CheckedAnd(z, x, y Field) error {
+	if err := CheckOperands(z, x, y); err != nil {
+		return err
+	}
+	z.And(x, y)
+	return nil
+}
+
+// CheckedAndNot sets z to x &^ y, returning an error instead of panicking
+// if the operands are incompatible as reported by CheckOperands.
+func CheckedAndNot(z, x, y Field) error {
+	if err := CheckOperands(z, x, y); err != nil {
+		return err
+	}
+	z.AndNot(x, y)
+	return nil
+}
+
+// CheckedOr sets z to x | y, returning an error instead of panicking
+// if the operands are incompatible as reported by CheckOperands.
+func CheckedOr(z, x, y Field) error {
+	if err := CheckOperands(z, x, y); err != nil {
+		return err
+	}
+	z.Or(x, y)
+	return nil
+}
+
+// CheckedXor sets z to x ^ y, returning an error instead of panicking
+// if the operands are incompatible as reported by CheckOperands.
+func CheckedXor(z, x, y Field) error {
+	if err := CheckOperands(z, x, y); err != nil {
+		return err
+	}
+	z.Xor(x, y)
+	return nil
+}
+
+// CheckedNot sets z to ^x, returning an error instead of panicking
+// if the operands are incompatible as reported by CheckOperands.
+func CheckedNot(z, x Field) error {
+	if err := CheckOperands(z, x); err != nil {
+		return err
+	}
+	z.Not(x)
+	return nil
+}
+
+// Multiplicative constants for the wyhash-style mixing used by Field.Hash.
+const (
+	hashK0 = 0xa0761d6478bd642f
+	hashK1 = 0xe7037ed1a0b428db
+)
+
+// hashMix folds a 64-bit word v into a running hash state h.
+// The state is folded back into the result so that a word
+// whose product term vanishes, such as hashK1, cannot erase it.
+func hashMix(h, v uint64) uint64 {
+	hi, lo := bits.Mul64(h ^ hashK0, v ^ hashK1)
+	return hi ^ lo ^ h
+}
+
+
+// fieldDigits is implemented by the bit-order-specific field types
+// to support formatting their contents as digits.
+type fieldDigits interface {
+	Len() int
+	top(ofs, n int) uint64	// n bits after the ofs most-significant
+}
+
+// Digit characters for binary and hexadecimal formatting.
+const lowerDigits = "0123456789abcdef"
+const upperDigits = "0123456789ABCDEF"
+
+// appendDigits appends to dst the contents of field z
+// as base-2^k digits in most-significant-first order,
+// where k is 1 for binary or 4 for hexadecimal.
+// If the field's width is not a multiple of k,
+// the leading digit holds only the leftover most-significant bits.
+func appendDigits(dst []byte, z fieldDigits, k int, digits string) []byte {
+	w := z.Len()
+	n := w % k
+	if n == 0 {
+		n = k
+	}
+	for ofs := 0; ofs < w; ofs += n {
+		if ofs > 0 {
+			n = k
+		}
+		dst = append(dst, digits[z.top(ofs, n)])
+	}
+	return dst
+}
+
+// convertField sets field z to the integer value of field x
+// of the same width, 64 bits at a time,
+// converting between bit orders.
+func convertField(z interface{ putTop(ofs, n int, v uint64) }, x fieldDigits) {
+	w := x.Len()
+	for ofs := 0; ofs < w; ofs += 64 {
+		n := w - ofs
+		if n > 64 {
+			n = 64
+		}
+		z.putTop(ofs, n, x.top(ofs, n))
+	}
 }
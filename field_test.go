@@ -0,0 +1,738 @@
+package bytebits
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+
+// testBits is an arbitrary bit pattern used as source data in field tests.
+var testBits = []byte{
+	0xde, 0xad, 0xbe, 0xef, 0x01, 0x23, 0x45, 0x67,
+	0x89, 0xab, 0xcd, 0xef, 0xfe, 0xdc, 0xba, 0x98,
+	0x76, 0x54, 0x32, 0x10, 0x5a, 0xa5, 0x0f, 0xf0,
+}
+
+// beFieldAt copies the first w bits of src into a fresh buffer at offset ofs,
+// filling the surrounding bits with ones, and returns a field referring to it.
+func beFieldAt(src []byte, ofs, w int) *BigEndianField {
+	buf := make([]byte, (ofs+w+7)/8 + 1)
+	for i := range buf {
+		buf[i] = 0xff
+	}
+	BigEndian.Copy(buf, src, ofs, 0, w)
+	return BigEndian.Field(buf, ofs, w).(*BigEndianField)
+}
+
+func TestFieldHash(t *testing.T) {
+	for _, w := range []int{0, 1, 7, 8, 13, 64, 65, 130, 191} {
+		h := beFieldAt(testBits, 0, w).Hash(1)
+		for ofs := 1; ofs < 16; ofs++ {
+			if hx := beFieldAt(testBits, ofs, w).Hash(1); hx != h {
+				t.Errorf("Hash of %v-bit field at %v: %x != %x",
+					w, ofs, hx, h)
+			}
+		}
+		if hs := beFieldAt(testBits, 0, w).Hash(2); hs == h {
+			t.Errorf("Hash of %v-bit field ignores seed", w)
+		}
+	}
+
+	// Fields with the same bits but different widths should differ
+	zero := make([]byte, 1)
+	if beFieldAt(zero, 0, 1).Hash(0) == beFieldAt(zero, 0, 2).Hash(0) {
+		t.Errorf("Hash of 1-bit and 2-bit zero fields collide")
+	}
+
+	// A word equal to hashK1 must not erase the seed or the prefix
+	x := make([]byte, 24)
+	binary.BigEndian.PutUint64(x[8:], hashK1)
+	y := append([]byte(nil), x...)
+	y[0] = 1
+	xf, yf := BigEndian.Field(x, 0, 192), BigEndian.Field(y, 0, 192)
+	if xf.Hash(1) == xf.Hash(2) {
+		t.Errorf("Hash seed erased by a word equal to hashK1")
+	}
+	if xf.Hash(1) == yf.Hash(1) {
+		t.Errorf("Hash prefix erased by a word equal to hashK1")
+	}
+}
+
+func TestFieldCanonical(t *testing.T) {
+	for _, w := range []int{0, 1, 7, 8, 13, 64, 65, 130, 191} {
+		want := make([]byte, (w+7)/8)
+		copy(want, testBits)
+		if w&7 != 0 {
+			want[len(want)-1] &= byte(0xff) << (8 - w&7)
+		}
+		for ofs := 0; ofs < 16; ofs++ {
+			got := beFieldAt(testBits, ofs, w).Canonical()
+			if !bytes.Equal(got, want) {
+				t.Errorf("Canonical of %v-bit field at %v: %x != %x",
+					w, ofs, got, want)
+			}
+		}
+	}
+}
+
+func TestFieldKey(t *testing.T) {
+	keys := make(map[string]int)
+	for _, w := range []int{0, 1, 2, 7, 8, 13, 64, 65, 130} {
+		k := beFieldAt(testBits, 0, w).Key()
+		for ofs := 1; ofs < 16; ofs++ {
+			if kx := beFieldAt(testBits, ofs, w).Key(); kx != k {
+				t.Errorf("Key of %v-bit field at %v differs", w, ofs)
+			}
+		}
+		if ks := MakeBitString(testBits, 0, w).Key(); ks != k {
+			t.Errorf("BitString and Field keys differ for width %v", w)
+		}
+		if w0, dup := keys[k]; dup {
+			t.Errorf("Key of %v-bit field collides with width %v",
+				w, w0)
+		}
+		keys[k] = w
+	}
+
+	zero := make([]byte, 1)
+	if MakeBitString(zero, 0, 1).Key() == MakeBitString(zero, 0, 2).Key() {
+		t.Errorf("Keys of bit strings 0 and 00 collide")
+	}
+}
+
+func TestFieldDigits(t *testing.T) {
+	f := beFieldAt(testBits, 5, 13)		// 1 1011 1101 0101
+	want := []uint64{0x5, 0xd, 0xb, 0x1}
+
+	it := f.Digits(4, LSDFirst)
+	for i := 0; ; i++ {
+		d, ok := it.Next()
+		if !ok {
+			if i != len(want) {
+				t.Errorf("LSDFirst yielded %v digits, want %v",
+					i, len(want))
+			}
+			break
+		}
+		if d != want[i] {
+			t.Errorf("LSDFirst digit %v = %x, want %x", i, d, want[i])
+		}
+	}
+
+	it = f.Digits(4, MSDFirst)
+	for i := len(want)-1; i >= 0; i-- {
+		if d, ok := it.Next(); !ok || d != want[i] {
+			t.Errorf("MSDFirst digit %v = %x,%v, want %x",
+				i, d, ok, want[i])
+		}
+	}
+	if _, ok := it.Next(); ok {
+		t.Errorf("MSDFirst yielded too many digits")
+	}
+
+	if d := beFieldAt(testBits, 3, 64).Digits(64, LSDFirst).Digit(0);
+			d != 0xdeadbeef01234567 {
+		t.Errorf("64-bit digit = %x", d)
+	}
+}
+
+func TestFieldSwap(t *testing.T) {
+	for _, w := range []int{0, 5, 64, 100, 190} {
+		a, b := testBits[:(w+7)/8], make([]byte, (w+7)/8)
+		for i := range b {
+			b[i] = ^a[i]
+		}
+		x, y := beFieldAt(a, 3, w), beFieldAt(b, 6, w)
+		x.Swap(y)
+		if !bytes.Equal(x.Canonical(), MakeBitString(b, 0, w).Bytes()) ||
+				!bytes.Equal(y.Canonical(),
+					MakeBitString(a, 0, w).Bytes()) {
+			t.Errorf("Swap of %v-bit fields failed", w)
+		}
+		if x.b[0] >> 5 != 7 || y.b[0] >> 2 != 0x3f {
+			t.Errorf("Swap of %v-bit fields clobbered leading bits", w)
+		}
+	}
+	if v := panicValue(func() {
+		beFieldAt(testBits, 0, 8).Swap(beFieldAt(testBits, 0, 9))
+	}); v != ErrLengthMismatch {
+		t.Errorf("Swap of mismatched fields: got %v", v)
+	}
+}
+
+func TestFieldWide(t *testing.T) {
+	// Operations on fields wider than 64 bits must process every word.
+	for _, w := range []int{64, 65, 130, 191} {
+		x := beFieldAt(testBits, 3, w)
+		if n := x.Count(1); n != Count(x.Canonical(), 1) {
+			t.Errorf("Count(1) of %v-bit field: got %v", w, n)
+		}
+		if n := x.Count(0) + x.Count(1); n != w {
+			t.Errorf("Count(0)+Count(1) of %v-bit field: got %v", w, n)
+		}
+		z := beFieldAt(make([]byte, 25), 5, w)
+		z.Fill(1)
+		if n := z.Count(1); n != w {
+			t.Errorf("Fill(1) of %v-bit field set %v bits", w, n)
+		}
+		z.And(x, x)
+		if !bytes.Equal(z.Canonical(), x.Canonical()) {
+			t.Errorf("And of %v-bit fields: got %x, want %x",
+				w, z.Canonical(), x.Canonical())
+		}
+	}
+	if n := BigEndian.Leading([]byte{0xff, 0xff, 0x0f}, 1); n != 16 {
+		t.Errorf("Leading(ffff0f, 1): got %v, want 16", n)
+	}
+}
+
+func TestFieldShift(t *testing.T) {
+	for _, w := range []int{0, 1, 13, 64, 100, 180} {
+		for _, s := range []int{0, 1, 3, 7, 8, 9, 63, 64, 70, 99, 200,
+				-1, -3, -5, -9, -70} {
+			for _, order := range []BitOrder{BigEndian, LittleEndian} {
+				x := order.Field(testBits, 3, w)
+				orig := bytes.Repeat([]byte{0xa5}, 26)
+				zbuf := append([]byte(nil), orig...)
+				z := order.Field(zbuf, 5, w)
+
+				// Source bit for destination bit i:
+				// a left shift moves bits toward offset 0 in both orders
+				d := s
+				check := func(op string) {
+					for i := -5; i < len(zbuf)*8 - 5; i++ {
+						want := order.Bit(orig, 5 + i)
+						if j := i + d; i >= 0 && i < w {
+							want = 0
+							if j >= 0 && j < w {
+								want = order.Bit(testBits, 3 + j)
+							}
+						}
+						if order.Bit(zbuf, 5 + i) != want {
+							t.Fatalf("%v %s(%v) of %v-bit field: "+
+								"bit %v wrong", order, op, s, w, i)
+						}
+					}
+				}
+				z.ShiftLeft(x, s)
+				check("ShiftLeft")
+				copy(zbuf, orig)
+				z.ShiftRight(x, -s)
+				check("ShiftRight")
+
+				// In place, in both directions
+				want := append([]byte(nil), zbuf...)
+				for _, right := range []bool{false, true} {
+					copy(zbuf, orig)
+					zbuf = order.Copy(zbuf, testBits, 5, 3, w)
+					if right {
+						z.ShiftRight(z, -s)
+					} else {
+						z.ShiftLeft(z, s)
+					}
+					if !bytes.Equal(zbuf, want) {
+						t.Fatalf("%v in-place shift by %v of %v-bit "+
+							"field (right %v): got %x, want %x",
+							order, s, w, right, zbuf, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFieldCompare(t *testing.T) {
+	// Reference comparison one bit at a time, most-significant first
+	cmp := func(order BitOrder, x, y []byte, w int) int {
+		for n := 0; n < w; n++ {
+			i := n
+			if order == LittleEndian {
+				i = w - 1 - n
+			}
+			if xb, yb := order.Bit(x, i), order.Bit(y, i); xb != yb {
+				return int(xb) - int(yb)
+			}
+		}
+		return 0
+	}
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 13, 64, 100, 180} {
+			x := order.Field(testBits, 3, w)
+			ybuf := order.Copy(nil, testBits, 6, 3, w)
+			y := order.Field(ybuf, 6, w)
+			if x.Len() != w || !x.Equal(y) || x.Compare(y) != 0 {
+				t.Errorf("%v %v-bit fields at different offsets differ",
+					order, w)
+			}
+
+			// Flip single bits and compare against the reference
+			for trial := 0; trial < 20 && w > 0; trial++ {
+				i := rand.Intn(w)
+				order.PutBit(ybuf, 6+i, 1-order.Bit(ybuf, 6+i))
+				want := cmp(order, x.Canonical(), y.Canonical(), w)
+				if x.Equal(y) != (want == 0) || x.Compare(y) != want ||
+						y.Compare(x) != -want {
+					t.Errorf("%v %v-bit Compare: got %v, want %v",
+						order, w, x.Compare(y), want)
+				}
+			}
+		}
+	}
+	if BigEndian.Field(testBits, 0, 8).Equal(BigEndian.Field(testBits, 0, 9)) {
+		t.Errorf("Equal of fields with different widths")
+	}
+}
+
+func TestFieldSlice(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		buf := order.Copy(nil, testBits, 5, 0, 180)
+		f := order.Field(buf, 5, 180)
+		hdr := f.Slice(11, 100)
+		sub := hdr.Slice(70, 20)	// bits 81-100 of f
+		want := order.Field(order.Copy(nil, testBits, 1, 81, 20), 1, 20)
+		if sub.Len() != 20 || !sub.Equal(want) {
+			t.Errorf("%v nested Slice: got %x, want %x",
+				order, sub.Canonical(), want.Canonical())
+		}
+		sub.Fill(1)
+		if n := order.LeadingAt(buf, 5 + 81, 20, 1); n != 20 {
+			t.Errorf("%v write through Slice not visible: %v bits", order, n)
+		}
+		if f.Slice(180, 0).Len() != 0 {
+			t.Errorf("%v empty Slice at end has nonzero width", order)
+		}
+	}
+
+	defer func() {
+		if recover() != ErrOutOfRange {
+			t.Errorf("Slice past end of field did not panic")
+		}
+	}()
+	BigEndian.Field(testBits, 0, 16).Slice(10, 7)
+}
+
+func TestFieldBytes(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 13, 64, 100} {
+			f := order.Field(testBits, 3, w)
+			for _, align := range []Align{Left, Right} {
+				want := order.Bits(nil, testBits, 3, w, align)
+				if got := f.Bytes(align); !bytes.Equal(got, want) {
+					t.Errorf("%v Bytes(%v) of %v-bit field: got %x, want %x",
+						order, align, w, got, want)
+				}
+				got := f.AppendBytes([]byte("hdr"), align)
+				if string(got[:3]) != "hdr" || !bytes.Equal(got[3:], want) {
+					t.Errorf("%v AppendBytes(%v) of %v-bit field: got %x",
+						order, align, w, got)
+				}
+			}
+			if !bytes.Equal(f.Bytes(Left), f.Canonical()) {
+				t.Errorf("%v left-aligned Bytes differ from Canonical", order)
+			}
+		}
+	}
+	if b := BigEndian.Field([]byte{0xab, 0xc0}, 0, 12).Bytes(Right); !bytes.Equal(b, []byte{0x0a, 0xbc}) {
+		t.Errorf("right-aligned Bytes: got %x, want 0abc", b)
+	}
+}
+
+func TestFieldReader(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		f := order.Field(testBits, 3, 100)
+		r1, r2 := f.Reader(), f.Reader()
+		for _, n := range []int{7, 64, 29} {
+			v1, err1 := r1.ReadBits(n)
+			v2, err2 := r2.ReadBits(n)
+			if err1 != nil || err2 != nil || v1 != v2 {
+				t.Fatalf("%v independent readers differ", order)
+			}
+		}
+		if _, err := r1.ReadBits(1); err != EOF {
+			t.Errorf("%v Reader past end: got %v, want EOF", order, err)
+		}
+		if f.Len() != 100 {
+			t.Errorf("%v Reader consumed the field", order)
+		}
+		v, _ := f.Reader().ReadBits(13)
+		if v != order.Uint(testBits, 3, 13) {
+			t.Errorf("%v Reader: first bits %x wrong", order, v)
+		}
+	}
+}
+
+func TestFieldConvert(t *testing.T) {
+	for _, w := range []int{0, 1, 13, 64, 100, 180} {
+		be := BigEndian.Field(testBits, 3, w)
+		le := LittleEndian.Field(make([]byte, 25), 5, w)
+		le.Set(be)
+		for i := 0; i < w; i++ {
+			if LittleEndian.Bit(le.Canonical(), w-1-i) !=
+					BigEndian.Bit(testBits, 3+i) {
+				t.Fatalf("%v-bit conversion to LittleEndian: bit %v wrong",
+					w, i)
+			}
+		}
+		if w <= 64 && LittleEndian.Uint(le.Canonical(), 0, w) !=
+				BigEndian.Uint(testBits, 3, w) {
+			t.Errorf("%v-bit conversion changed the integer value", w)
+		}
+		back := BigEndian.Field(make([]byte, 25), 7, w)
+		if !back.Set(le).Equal(be) {
+			t.Errorf("%v-bit round trip conversion: got %v, want %v",
+				w, back, be)
+		}
+	}
+}
+
+func TestCheckedOps(t *testing.T) {
+	a := BigEndian.Field(make([]byte, 4), 0, 20)
+	b := BigEndian.Field(testBits, 3, 20)
+	c := BigEndian.Field(testBits, 3, 21)
+	l := LittleEndian.Field(testBits, 3, 20)
+	if err := CheckedXor(a, b, b); err != nil || a.Count(1) != 0 {
+		t.Errorf("CheckedXor of compatible fields: %v", err)
+	}
+	if err := CheckedOr(a, b, c); err != ErrLengthMismatch {
+		t.Errorf("CheckedOr of mismatched widths: got %v", err)
+	}
+	if err := CheckedAnd(a, b, l); err != ErrFieldType {
+		t.Errorf("CheckedAnd of mixed bit orders: got %v", err)
+	}
+	if err := CheckedNot(a, l); err != ErrFieldType {
+		t.Errorf("CheckedNot of mixed bit orders: got %v", err)
+	}
+	if err := CheckedSet(a, c); err != ErrLengthMismatch {
+		t.Errorf("CheckedSet of mismatched widths: got %v", err)
+	}
+	if err := CheckedSet(a, l); err != nil {
+		t.Errorf("CheckedSet across bit orders: got %v", err)
+	}
+}
+
+func TestFieldInsertDelete(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for trial := 0; trial < 100; trial++ {
+			w := rand.Intn(150)
+			ofs, n := rand.Intn(w+1), rand.Intn(100)
+			buf := order.Copy(make([]byte, 40), testBits, 5, 0, w)
+			orig := append([]byte(nil), buf...)
+			f := order.Field(buf, 5, w)
+
+			f.InsertBits(ofs, n)
+			if f.Len() != w + n {
+				t.Fatalf("%v InsertBits: width %v, want %v",
+					order, f.Len(), w + n)
+			}
+			for i := 0; i < w + n; i++ {
+				want := uint(0)
+				if i < ofs {
+					want = order.Bit(orig, 5 + i)
+				} else if i >= ofs + n {
+					want = order.Bit(orig, 5 + i - n)
+				}
+				if order.Bit(buf, 5 + i) != want {
+					t.Fatalf("%v InsertBits(%v, %v) in %v-bit field: "+
+						"bit %v wrong", order, ofs, n, w, i)
+				}
+			}
+			if order.Uint(buf, 0, 5) != order.Uint(orig, 0, 5) {
+				t.Fatalf("%v InsertBits clobbered preceding bits", order)
+			}
+
+			f.DeleteBits(ofs, n)
+			if f.Len() != w ||
+					!f.Equal(order.Field(orig, 5, w)) {
+				t.Fatalf("%v DeleteBits(%v, %v) did not restore %v-bit field",
+					order, ofs, n, w)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() != ErrShortBuffer {
+			t.Errorf("InsertBits past end of buffer did not panic")
+		}
+	}()
+	BigEndian.Field(make([]byte, 2), 0, 10).InsertBits(3, 7)
+}
+
+func TestFieldReverse(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 2, 13, 64, 65, 127, 128, 129, 180} {
+			x := order.Field(testBits, 3, w)
+			zbuf := bytes.Repeat([]byte{0x5a}, 25)
+			z := order.Field(zbuf, 6, w)
+			z.Reverse(x)
+			for i := 0; i < w; i++ {
+				if order.Bit(zbuf, 6 + i) != order.Bit(testBits, 3 + w-1-i) {
+					t.Fatalf("%v Reverse of %v-bit field: bit %v wrong",
+						order, w, i)
+				}
+			}
+			if order.Uint(zbuf, 0, 6) != order.Uint([]byte{0x5a}, 0, 6) {
+				t.Errorf("%v Reverse clobbered preceding bits", order)
+			}
+
+			// Reversing in place again restores the original
+			if !z.Reverse(z).Equal(x) {
+				t.Errorf("%v in-place Reverse of %v-bit field: got %v, want %v",
+					order, w, z, x)
+			}
+		}
+	}
+	if b := BigEndian.Field([]byte{0x81, 0x00}, 0, 9).Reverse(
+			BigEndian.Field([]byte{0xc0, 0x00}, 0, 9)).String(); b != "000000011" {
+		t.Errorf("Reverse: got %v", b)
+	}
+}
+
+func TestFieldGray(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		// msb returns the offset of the i'th most-significant bit
+		msb := func(w, i int) int {
+			if order == BigEndian {
+				return i
+			}
+			return w-1-i
+		}
+		for _, w := range []int{0, 1, 2, 13, 63, 64, 65, 127, 128, 129, 180} {
+			x := order.Field(testBits, 3, w)
+			zbuf := bytes.Repeat([]byte{0x5a}, 25)
+			z := order.Field(zbuf, 6, w)
+			z.BinaryToGray(x)
+			prev := uint(0)
+			for i := 0; i < w; i++ {
+				b := order.Bit(testBits, 3 + msb(w, i))
+				if order.Bit(zbuf, 6 + msb(w, i)) != b ^ prev {
+					t.Fatalf("%v BinaryToGray of %v-bit field: bit %v wrong",
+						order, w, i)
+				}
+				prev = b
+			}
+			if order.Uint(zbuf, 0, 6) != order.Uint([]byte{0x5a}, 0, 6) {
+				t.Errorf("%v BinaryToGray clobbered preceding bits", order)
+			}
+
+			// Converting back in place restores the original
+			if !z.GrayToBinary(z).Equal(x) {
+				t.Errorf("%v in-place GrayToBinary of %v-bit field: got %v, want %v",
+					order, w, z, x)
+			}
+		}
+	}
+
+	// Whole slices behave as single integers
+	x := testBits[:8]
+	if g := BigEndian.BinaryToGray(nil, x); binary.BigEndian.Uint64(g) !=
+			binary.BigEndian.Uint64(x) ^ binary.BigEndian.Uint64(x) >> 1 {
+		t.Errorf("BigEndian.BinaryToGray: got %x", g)
+	} else if b := BigEndian.GrayToBinary(g, g); !bytes.Equal(b, x) {
+		t.Errorf("BigEndian.GrayToBinary: got %x, want %x", b, x)
+	}
+	if g := LittleEndian.BinaryToGray(nil, x); binary.LittleEndian.Uint64(g) !=
+			binary.LittleEndian.Uint64(x) ^ binary.LittleEndian.Uint64(x) >> 1 {
+		t.Errorf("LittleEndian.BinaryToGray: got %x", g)
+	} else if b := LittleEndian.GrayToBinary(g, g); !bytes.Equal(b, x) {
+		t.Errorf("LittleEndian.GrayToBinary: got %x, want %x", b, x)
+	}
+	if g := BigEndian.BinaryToGray(nil, []byte{0x01, 0x80}); g[0] != 0x01 || g[1] != 0x40 {
+		t.Errorf("BinaryToGray carry across bytes: got %x, want 0140", g)
+	}
+}
+
+func TestFieldOnesZeros(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 13, 64, 100, 180} {
+			f := order.Field(testBits, 3, w)
+			var ones, zeros []int
+			for i := range f.Ones() {
+				ones = append(ones, i)
+			}
+			for i := range f.Zeros() {
+				zeros = append(zeros, i)
+			}
+			if len(ones) != f.Count(1) || len(zeros) != f.Count(0) {
+				t.Fatalf("%v %v-bit field: %v ones and %v zeros",
+					order, w, len(ones), len(zeros))
+			}
+			for _, i := range ones {
+				if order.Bit(testBits, 3 + i) != 1 {
+					t.Errorf("%v Ones yielded zero bit %v", order, i)
+				}
+			}
+			for k := 1; k < len(zeros); k++ {
+				if zeros[k] <= zeros[k-1] {
+					t.Errorf("%v Zeros out of order", order)
+				}
+			}
+		}
+	}
+
+	// Stopping early must not yield further positions
+	n := 0
+	for range BigEndian.Field(testBits, 0, 192).Ones() {
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("early break: %v iterations", n)
+	}
+}
+
+func TestFieldWords(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{0, 1, 64, 100, 180} {
+			f := order.Field(testBits, 3, w)
+			ofs := 0
+			for n, v := range f.Words() {
+				if n != 64 && ofs + n != w {
+					t.Errorf("%v short word of %v bits before end", order, n)
+				}
+				if want := order.Uint(testBits, 3 + ofs, n); v != want {
+					t.Errorf("%v %v-bit field word at %v: got %x, want %x",
+						order, w, ofs, v, want)
+				}
+				ofs += n
+			}
+			if ofs != w {
+				t.Errorf("%v Words covered %v of %v bits", order, ofs, w)
+			}
+		}
+	}
+}
+
+func TestFieldFillPattern(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, n := range []int{1, 2, 3, 7, 24, 63, 64} {
+			pat := uint64(0x9e3779b97f4a7c15)
+			for _, w := range []int{0, 5, 64, 100, 180} {
+				buf := bytes.Repeat([]byte{0xa5}, 25)
+				order.Field(buf, 5, w).FillPattern(pat, n)
+				for i := 0; i < w; i++ {
+					// Bit i%n of the pattern, in the order's sense
+					j := i % n
+					if order == BigEndian {
+						j = n - 1 - j
+					}
+					if want := uint(pat >> j & 1); order.Bit(buf, 5 + i) != want {
+						t.Fatalf("%v FillPattern(%x, %v) of %v-bit field: "+
+							"bit %v wrong", order, pat, n, w, i)
+					}
+				}
+				if order.Bit(buf, 5 + w) != order.Bit([]byte{0xa5}, (5 + w) & 7) {
+					t.Fatalf("%v FillPattern clobbered following bits", order)
+				}
+			}
+		}
+	}
+	f := BigEndian.Field(make([]byte, 2), 0, 11)
+	if f.FillPattern(0b10, 2); f.String() != "10101010101" {
+		t.Errorf("FillPattern(0b10, 2): got %v", f)
+	}
+}
+
+func TestFieldFind(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		buf := make([]byte, 25)
+		f := order.Field(buf, 3, 180)
+		if i := f.FindFirst(1); i != -1 {
+			t.Errorf("%v FindFirst(1) of zero field: got %v", order, i)
+		}
+		for _, i := range []int{0, 5, 70, 150, 179} {
+			order.PutBit(buf, 3 + i, 1)
+		}
+		var got []int
+		for i := f.FindFirst(1); i >= 0; i = f.Next(1, i+1) {
+			got = append(got, i)
+		}
+		if len(got) != 5 || got[1] != 5 || got[3] != 150 || got[4] != 179 {
+			t.Errorf("%v Next(1) sequence: got %v", order, got)
+		}
+		if i := f.FindFirst(0); i != 1 {
+			t.Errorf("%v FindFirst(0): got %v, want 1", order, i)
+		}
+		if i := f.Next(1, 180); i != -1 {
+			t.Errorf("%v Next past end: got %v", order, i)
+		}
+	}
+}
+
+func TestFieldReadBitsInto(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, n := range []int{0, 5, 64, 130} {
+			f := order.Field(testBits, 3, 180).(interface {
+				Field
+				ReadBits(int) (uint64, error)
+				ReadBitsInto([]byte, int) ([]byte, error)
+			})
+			f.ReadBits(1)
+			b, err := f.ReadBitsInto(make([]byte, 4), n)
+			if want := order.Bits(nil, testBits, 4, n, Left); err != nil ||
+					!bytes.Equal(b, want) {
+				t.Errorf("%v ReadBitsInto(%v): got %x, %v, want %x",
+					order, n, b, err, want)
+			}
+			if f.Len() != 179 - n {
+				t.Errorf("%v ReadBitsInto(%v) left %v bits", order, n, f.Len())
+			}
+			v, _ := f.ReadBits(3)
+			if v != order.Uint(testBits, 4 + n, 3) {
+				t.Errorf("%v ReadBits after ReadBitsInto(%v) misaligned",
+					order, n)
+			}
+			if _, err := f.ReadBitsInto(nil, 200); err != EOF {
+				t.Errorf("%v ReadBitsInto past end: got %v", order, err)
+			}
+		}
+	}
+}
+
+func TestFieldWriteToReadFrom(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, pad := range []Padding{PadZeros, PadOnes, Truncate} {
+			f := order.Field(testBits, 3, 20)
+			f.(interface{ SetPadding(Padding) }).SetPadding(pad)
+			var buf bytes.Buffer
+			n, err := f.(io.WriterTo).WriteTo(&buf)
+			want := order.Bits(nil, testBits, 3, 20, Left)
+			switch pad {
+			case PadOnes:
+				order.SetBits(want, 20, 4, 1)
+			case Truncate:
+				want = want[:2]
+			}
+			if err != nil || n != int64(len(want)) ||
+					!bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("%v WriteTo with padding %v: got %x, %v, want %x",
+					order, pad, buf.Bytes(), err, want)
+			}
+			if left := f.Len(); (pad == Truncate) != (left == 4) ||
+					(pad != Truncate && left != 0) {
+				t.Errorf("%v WriteTo with padding %v left %v bits",
+					order, pad, left)
+			}
+
+			// Read the bytes back into a fresh field
+			zbuf := make([]byte, 5)
+			z := order.Field(zbuf, 5, 20)
+			z.(interface{ SetPadding(Padding) }).SetPadding(pad)
+			src := bytes.NewReader(append(buf.Bytes(), 0x77))
+			n, err = z.(io.ReaderFrom).ReadFrom(src)
+			wantBits := 20
+			if pad == Truncate {
+				wantBits = 16
+			}
+			if err != nil || n != int64((wantBits+7)/8) ||
+					!bytes.Equal(order.Bits(nil, zbuf, 5, wantBits, Left),
+						order.Bits(nil, testBits, 3, wantBits, Left)) {
+				t.Errorf("%v ReadFrom with padding %v: got %v, %v",
+					order, pad, n, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+//go:build !tinygo && !bytebits_small
+// +build !tinygo,!bytebits_small
+
+package bytebits
+
+import (
+	"fmt"
+	"strconv"
+)
+
+
+// The fmt.Formatter implementations are omitted
+// in reduced-footprint builds to avoid depending on package fmt,
+// in which case fields format via their String methods.
+
+// Format implements fmt.Formatter, formatting the contents of field z
+// in binary for the %b, %s, and %v verbs,
+// in hexadecimal for the %x and %X verbs,
+// or as an unsigned decimal integer for the %d verb
+// if the field is at most 64 bits wide.
+// The # flag adds a 0b or 0x prefix,
+// and the width, - and 0 flags pad the result as for integers.
+func (z *BigEndianField) Format(f fmt.State, verb rune) {
+	formatField(f, verb, z)
+}
+
+// Format implements fmt.Formatter, formatting the contents of field z
+// most-significant (last) bit first,
+// in binary for the %b, %s, and %v verbs,
+// in hexadecimal for the %x and %X verbs,
+// or as an unsigned decimal integer for the %d verb
+// if the field is at most 64 bits wide.
+// The # flag adds a 0b or 0x prefix,
+// and the width, - and 0 flags pad the result as for integers.
+func (z *LittleEndianField) Format(f fmt.State, verb rune) {
+	formatField(f, verb, z)
+}
+
+func formatField(f fmt.State, verb rune, z fieldDigits) {
+	var prefix string
+	var buf []byte
+	switch verb {
+	case 'b', 's', 'v':
+		if verb == 'b' && f.Flag('#') {
+			prefix = "0b"
+		}
+		buf = appendDigits(nil, z, 1, lowerDigits)
+	case 'x':
+		if f.Flag('#') {
+			prefix = "0x"
+		}
+		buf = appendDigits(nil, z, 4, lowerDigits)
+	case 'X':
+		if f.Flag('#') {
+			prefix = "0X"
+		}
+		buf = appendDigits(nil, z, 4, upperDigits)
+	case 'd':
+		if z.Len() > 64 {
+			fmt.Fprintf(f, "%%!d(%d-bit field)", z.Len())
+			return
+		}
+		buf = strconv.AppendUint(nil, z.top(0, z.Len()), 10)
+	default:
+		fmt.Fprintf(f, "%%!%c(%T=%s)", verb, z,
+			appendDigits(nil, z, 1, lowerDigits))
+		return
+	}
+
+	// Pad to the requested width, with zeros after any prefix
+	// or with spaces on the left or right
+	pad := 0
+	if w, ok := f.Width(); ok {
+		pad = w - len(prefix) - len(buf)
+	}
+	switch {
+	case pad <= 0:
+		fmt.Fprint(f, prefix, string(buf))
+	case f.Flag('-'):
+		fmt.Fprint(f, prefix, string(buf), padding(pad, ' '))
+	case f.Flag('0') && verb != 's' && verb != 'v':
+		fmt.Fprint(f, prefix, padding(pad, '0'), string(buf))
+	default:
+		fmt.Fprint(f, padding(pad, ' '), prefix, string(buf))
+	}
+}
+
+// padding returns a string of n copies of padding character c.
+func padding(n int, c byte) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = c
+	}
+	return string(b)
+}
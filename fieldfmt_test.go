@@ -0,0 +1,52 @@
+//go:build !tinygo && !bytebits_small
+// +build !tinygo,!bytebits_small
+
+package bytebits
+
+import (
+	"fmt"
+	"testing"
+)
+
+
+func TestFieldFormat(t *testing.T) {
+	be := beFieldAt(testBits, 5, 13)		// 1 1011 1101 0101
+	le := LittleEndian.Field([]byte{0xd5, 0x1b}, 0, 13)
+	for _, test := range []struct{ format, want string }{
+		{"%v", "1101111010101"},
+		{"%s", "1101111010101"},
+		{"%b", "1101111010101"},
+		{"%#b", "0b1101111010101"},
+		{"%x", "1bd5"},
+		{"%X", "1BD5"},
+		{"%#x", "0x1bd5"},
+		{"%d", "7125"},
+		{"%6d", "  7125"},
+		{"%-6d|", "7125  |"},
+		{"%#08x", "0x001bd5"},
+		{"%q", "%!q(*bytebits.BigEndianField=1101111010101)"},
+	} {
+		if got := fmt.Sprintf(test.format, be); got != test.want {
+			t.Errorf("Sprintf(%q) of BigEndianField: got %q, want %q",
+				test.format, got, test.want)
+		}
+		if test.format == "%q" {
+			continue
+		}
+		if got := fmt.Sprintf(test.format, le); got != test.want {
+			t.Errorf("Sprintf(%q) of LittleEndianField: got %q, want %q",
+				test.format, got, test.want)
+		}
+	}
+
+	wide := BigEndian.Field(testBits, 0, 66)
+	if got := fmt.Sprintf("%x", wide); got != "37ab6fbbc048d159e" {
+		t.Errorf("Sprintf(%%x) of 66-bit field: got %q", got)
+	}
+	if got := fmt.Sprintf("%d", wide); got != "%!d(66-bit field)" {
+		t.Errorf("Sprintf(%%d) of 66-bit field: got %q", got)
+	}
+	if s := BigEndian.Field(testBits, 0, 0).String(); s != "" {
+		t.Errorf("String of empty field: got %q", s)
+	}
+}
@@ -0,0 +1,143 @@
+package bytebits
+
+import (
+	"math"
+)
+
+
+// Fixed describes a binary fixed-point format Qm.n,
+// holding values scaled by 2^n as integers m+n bits wide,
+// as common in DSP register maps and sensor outputs.
+// For a signed format, IntBits counts the sign bit,
+// so that the 16-bit Q15 format is Fixed{1, 15, true}
+// and values range from -1 to 1-2^-15.
+// The total width must be from 1 to 64 bits,
+// or at most 63 bits for an unsigned format,
+// so that every scaled value fits an int64.
+//
+type Fixed struct {
+	IntBits int		// Integer bits, including any sign bit
+	FracBits int		// Fraction bits
+	Signed bool		// Two's complement rather than unsigned
+}
+
+// Width returns the total width in bits of values in format q.
+// Panics with ErrOutOfRange if the format is invalid.
+func (q Fixed) Width() int {
+	w := q.IntBits + q.FracBits
+	if q.IntBits < 0 || q.FracBits < 0 || w < 1 || w > 64 ||
+			(w == 64 && !q.Signed) {
+		panic(ErrOutOfRange)
+	}
+	return w
+}
+
+// limits returns the smallest and largest scaled values of format q.
+func (q Fixed) limits() (min, max int64) {
+	w := q.Width()
+	if q.Signed {
+		return -1 << (w-1), 1 << (w-1) - 1
+	}
+	return 0, 1 << w - 1
+}
+
+// Scaled returns the scaled integer value of the fixed-point value
+// encoded in the least-significant q.Width() bits of v,
+// sign-extending it if q is signed.
+func (q Fixed) Scaled(v uint64) int64 {
+	w := q.Width()
+	if q.Signed {
+		return signExtend(v, w)
+	}
+	return int64(v & (1 << w - 1))
+}
+
+// Decode returns the value of the fixed-point value
+// encoded in the least-significant q.Width() bits of v.
+func (q Fixed) Decode(v uint64) float64 {
+	return math.Ldexp(float64(q.Scaled(v)), -q.FracBits)
+}
+
+// EncodeScaled returns the encoding of scaled integer value v
+// in the least-significant q.Width() bits,
+// saturating v to the range of the format.
+func (q Fixed) EncodeScaled(v int64) uint64 {
+	min, max := q.limits()
+	v = clamp(v, min, max)
+	return uint64(v) & (1 << q.Width() - 1)
+}
+
+// Encode returns the encoding of x in the least-significant q.Width() bits,
+// rounding it to the nearest multiple of 2^-q.FracBits with ties to even
+// and saturating it to the range of the format.
+// A NaN encodes as zero.
+func (q Fixed) Encode(x float64) uint64 {
+	min, max := q.limits()
+	s := math.RoundToEven(math.Ldexp(x, q.FracBits))
+	var v int64
+	switch {
+	case s != s:
+		v = 0
+	case s >= float64(max):
+		v = max
+	case s <= float64(min):
+		v = min
+	default:
+		v = int64(s)
+	}
+	return q.EncodeScaled(v)
+}
+
+// clamp returns v limited to the range [min, max].
+func clamp(v, min, max int64) int64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// Get returns the value of the fixed-point value in format q
+// at bit offset xofs in slice x, using bit order order.
+func (q Fixed) Get(order BitOrder, x []byte, xofs int) float64 {
+	return q.Decode(order.Uint(x, xofs, q.Width()))
+}
+
+// GetScaled returns the scaled integer value of the fixed-point value
+// in format q at bit offset xofs in slice x, using bit order order.
+func (q Fixed) GetScaled(order BitOrder, x []byte, xofs int) int64 {
+	return q.Scaled(order.Uint(x, xofs, q.Width()))
+}
+
+// Put writes x as a fixed-point value in format q
+// at bit offset zofs in slice z, using bit order order,
+// rounding and saturating it as Encode does.
+// Copies z and returns a new slice if z is nil or not large enough.
+func (q Fixed) Put(order BitOrder, z []byte, zofs int, x float64) []byte {
+	return order.PutUint(z, zofs, q.Width(), q.Encode(x))
+}
+
+// PutScaled writes the scaled integer value v as a fixed-point value
+// in format q at bit offset zofs in slice z, using bit order order,
+// saturating it as EncodeScaled does.
+// Copies z and returns a new slice if z is nil or not large enough.
+func (q Fixed) PutScaled(order BitOrder, z []byte, zofs int, v int64) []byte {
+	return order.PutUint(z, zofs, q.Width(), q.EncodeScaled(v))
+}
+
+// Read reads a fixed-point value in format q from bit stream r.
+func (q Fixed) Read(r BitReader) (float64, error) {
+	v, err := r.ReadBits(q.Width())
+	if err != nil {
+		return 0, err
+	}
+	return q.Decode(v), nil
+}
+
+// Write writes x as a fixed-point value in format q to bit stream w,
+// rounding and saturating it as Encode does.
+func (q Fixed) Write(w BitWriter, x float64) error {
+	return w.WriteBits(q.Width(), q.Encode(x))
+}
@@ -0,0 +1,62 @@
+package bytebits
+
+import (
+	"math"
+	"testing"
+)
+
+
+func TestFixed(t *testing.T) {
+	q15 := Fixed{1, 15, true}
+	for _, c := range []struct {
+		x float64
+		v uint64
+	}{
+		{0, 0x0000}, {0.5, 0x4000}, {-1, 0x8000}, {-0.5, 0xc000},
+		{1, 0x7fff}, {-2, 0x8000}, {math.Ldexp(1, -15), 0x0001},
+		{math.Ldexp(1, -16), 0x0000}, {math.Ldexp(3, -16), 0x0002},
+		{math.NaN(), 0}, {math.Inf(-1), 0x8000},
+	} {
+		if v := q15.Encode(c.x); v != c.v {
+			t.Errorf("Q15 Encode(%v): got %04x, want %04x", c.x, v, c.v)
+		}
+	}
+	if x := q15.Decode(0xe000); x != -0.25 {
+		t.Errorf("Q15 Decode(e000): got %v", x)
+	}
+
+	// Unsigned Q4.4 at an unaligned offset in either bit order
+	uq := Fixed{IntBits: 4, FracBits: 4}
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		z := uq.Put(order, nil, 3, 9.1875)
+		if x := uq.Get(order, z, 3); x != 9.1875 {
+			t.Errorf("%v UQ4.4 Put and Get: got %v", order, x)
+		}
+		if s := uq.GetScaled(order, z, 3); s != 147 {
+			t.Errorf("%v UQ4.4 GetScaled: got %v", order, s)
+		}
+		z = uq.PutScaled(order, z, 3, 1000)
+		if x := uq.Get(order, z, 3); x != 15.9375 {
+			t.Errorf("%v UQ4.4 saturated PutScaled: got %v", order, x)
+		}
+		z = uq.Put(order, z, 3, -3)
+		if x := uq.Get(order, z, 3); x != 0 {
+			t.Errorf("%v UQ4.4 Put of negative value: got %v", order, x)
+		}
+	}
+
+	// Signed 64-bit formats saturate without overflow
+	q := Fixed{32, 32, true}
+	if s := q.Scaled(q.Encode(1e30)); s != math.MaxInt64 {
+		t.Errorf("Q32.32 saturation: got %x", s)
+	}
+	w := NewAppendWriter(nil)
+	q.Write(w, -12345.625)
+	if x, err := q.Read(BigEndian.Field(w.Bytes(), 0, 64).Reader());
+			x != -12345.625 || err != nil {
+		t.Errorf("Q32.32 Read: got %v, %v", x, err)
+	}
+	if panicValue(func() { Fixed{IntBits: 64}.Width() }) != ErrOutOfRange {
+		t.Errorf("Width of 64-bit unsigned format did not panic")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build tinygo || bytebits_small
+// +build tinygo bytebits_small
+
+package bytebits
+
+// smallFootprint selects compact implementations over faster but larger ones.
+// It is true when building with TinyGo or with the bytebits_small build tag.
+const smallFootprint = true
@@ -0,0 +1,33 @@
+package bytebits
+
+import (
+	"math/bits"
+)
+
+
+// Unsigned is a constraint permitting any unsigned integer type,
+// equivalent to golang.org/x/exp/constraints.Unsigned.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// widthOf returns the width in bits of unsigned integer type T.
+func widthOf[T Unsigned]() int {
+	return bits.Len64(uint64(^T(0)))
+}
+
+// GetUint extracts an unsigned integer of type T
+// starting at bit position xofs in x, using the bit order given by order.
+// The width of the extracted field is inferred from the type T;
+// for example, GetUint[uint16] extracts a 16-bit field.
+func GetUint[T Unsigned](order BitOrder, x []byte, xofs int) T {
+	return T(order.Uint(x, xofs, widthOf[T]()))
+}
+
+// PutUint sets the unsigned integer of type T starting at bit position zofs
+// in z to v, using the bit order given by order, and returns z.
+// The width of the field set is inferred from the type of v.
+// Copies z and returns a new slice if z is nil or not large enough.
+func PutUint[T Unsigned](order BitOrder, z []byte, zofs int, v T) []byte {
+	return order.PutUint(z, zofs, widthOf[T](), uint64(v))
+}
@@ -0,0 +1,275 @@
+package bytebits
+
+import (
+	"io"
+	"math/bits"
+)
+
+
+// The Golomb and Rice codes encode a value v with a parameter m
+// as a quotient q = v / m in unary, as q zero bits followed by a one bit,
+// followed by the remainder v % m in truncated binary,
+// as in FLAC and Shorten residual coding.
+// Rice codes are the special case in which m is a power of two, 2^k,
+// so that the remainder is simply the low k bits of v.
+// The codes are read and written most-significant bit first,
+// and so require MSB-first bit streams.
+
+// writeUnary writes q zero bits followed by a one bit to w.
+func writeUnary(w BitWriter, q uint64) error {
+	for ; q >= 64; q -= 64 {
+		if err := w.WriteBits(64, 0); err != nil {
+			return err
+		}
+	}
+	return w.WriteBits(int(q) + 1, 1)
+}
+
+// readUnary reads zero bits up to and including a one bit from r,
+// and returns the number of zero bits.
+// Returns io.ErrUnexpectedEOF if the stream ends within the code
+// and more than zero bits have been read.
+func readUnary(r BitReader) (q uint64, err error) {
+	for {
+		b, err := r.ReadBits(1)
+		if err != nil {
+			if err == EOF && q > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if b != 0 {
+			return q, nil
+		}
+		q++
+	}
+}
+
+// readRest reads the n bits completing a code already begun,
+// reporting an early end of stream as io.ErrUnexpectedEOF.
+func readRest(r BitReader, n int) (uint64, error) {
+	v, err := r.ReadBits(n)
+	if err == EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// WriteTruncated writes v to w in truncated binary encoding
+// for the range [0, n), which uses b-1 bits for the first 2^b-n values
+// and b bits for the rest, where b = ceil(log2(n)),
+// so that no codes are wasted when n is not a power of two.
+// Writes nothing if n is 1.
+// Returns ErrOutOfRange if v is not less than n.
+func WriteTruncated(w BitWriter, v, n uint64) error {
+	if v >= n {
+		return ErrOutOfRange
+	}
+	b := bits.Len64(n - 1)		// ceil(log2(n)) bits
+	cut := uint64(1) << b - n	// values encoded in b-1 bits
+	if v < cut {
+		return w.WriteBits(b - 1, v)
+	}
+	return w.WriteBits(b, v + cut)
+}
+
+// ReadTruncated reads a value in the range [0, n)
+// in truncated binary encoding from r, as written by WriteTruncated.
+// Returns ErrOutOfRange if n is zero,
+// and io.ErrUnexpectedEOF if the stream ends within the code.
+func ReadTruncated(r BitReader, n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, ErrOutOfRange
+	}
+	b := bits.Len64(n - 1)
+	if b == 0 {
+		return 0, nil
+	}
+	cut := uint64(1) << b - n
+	x, err := r.ReadBits(b - 1)
+	if err != nil {
+		return 0, err
+	}
+	if x >= cut {
+		var lsb uint64
+		if b > 1 {
+			lsb, err = readRest(r, 1)
+		} else {
+			lsb, err = r.ReadBits(1)
+		}
+		if err != nil {
+			return 0, err
+		}
+		x = (x << 1 | lsb) - cut
+	}
+	return x, nil
+}
+
+// WriteGolomb writes v to w as a Golomb code with parameter m.
+// Returns ErrOutOfRange if m is zero.
+func WriteGolomb(w BitWriter, v, m uint64) error {
+	if m == 0 {
+		return ErrOutOfRange
+	}
+	if err := writeUnary(w, v / m); err != nil {
+		return err
+	}
+	return WriteTruncated(w, v % m, m)
+}
+
+// ReadGolomb reads a Golomb code with parameter m from r.
+// Returns ErrOutOfRange if m is zero,
+// ErrInvalidCode if the decoded value would overflow a uint64,
+// and io.ErrUnexpectedEOF if the stream ends within the code.
+func ReadGolomb(r BitReader, m uint64) (uint64, error) {
+	if m == 0 {
+		return 0, ErrOutOfRange
+	}
+	q, err := readUnary(r)
+	if err != nil {
+		return 0, err
+	}
+	x, err := ReadTruncated(r, m)
+	if err == EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, err
+	}
+	hi, lo := bits.Mul64(q, m)
+	v := lo + x
+	if hi != 0 || v < lo {
+		return 0, ErrInvalidCode
+	}
+	return v, nil
+}
+
+// WriteRice writes v to w as a Rice code with parameter k,
+// the Golomb code with parameter 2^k.
+// Returns ErrOutOfRange if k is greater than 63.
+func WriteRice(w BitWriter, v uint64, k int) error {
+	if k < 0 || k > 63 {
+		return ErrOutOfRange
+	}
+	if err := writeUnary(w, v >> k); err != nil {
+		return err
+	}
+	return w.WriteBits(k, v)
+}
+
+// ReadRice reads a Rice code with parameter k from r.
+// Returns ErrOutOfRange if k is greater than 63,
+// ErrInvalidCode if the decoded value would overflow a uint64,
+// and io.ErrUnexpectedEOF if the stream ends within the code.
+func ReadRice(r BitReader, k int) (uint64, error) {
+	if k < 0 || k > 63 {
+		return 0, ErrOutOfRange
+	}
+	q, err := readUnary(r)
+	if err != nil {
+		return 0, err
+	}
+	if q > ^uint64(0) >> k {
+		return 0, ErrInvalidCode
+	}
+	x, err := readRest(r, k)
+	if err != nil {
+		return 0, err
+	}
+	return q << k | x, nil
+}
+
+// WriteRiceSigned writes a signed value v to w as a Rice code
+// with parameter k, after folding it to an unsigned value
+// by zigzag encoding, 0, -1, 1, -2, ... mapping to 0, 1, 2, 3, ...,
+// as for FLAC residuals.
+func WriteRiceSigned(w BitWriter, v int64, k int) error {
+	return WriteRice(w, uint64(v << 1 ^ v >> 63), k)
+}
+
+// ReadRiceSigned reads a zigzag-folded signed Rice code
+// with parameter k from r, as written by WriteRiceSigned.
+func ReadRiceSigned(r BitReader, k int) (int64, error) {
+	u, err := ReadRice(r, k)
+	return int64(u >> 1) ^ -int64(u & 1), err
+}
+
+
+// WriteExpGolomb writes v to w as a k-th order exponential-Golomb code,
+// as used for H.264 and H.265 syntax elements:
+// with x = v + 2^k of n bits, n-1-k zero bits followed by x in n bits.
+// Returns ErrOutOfRange if k is greater than 63
+// or if v + 2^k does not fit in 64 bits.
+func WriteExpGolomb(w BitWriter, v uint64, k int) error {
+	if k < 0 || k > 63 {
+		return ErrOutOfRange
+	}
+	x := v + 1 << k
+	if x < v {
+		return ErrOutOfRange
+	}
+	n := bits.Len64(x)
+	if err := w.WriteBits(n - 1 - k, 0); err != nil {
+		return err
+	}
+	return w.WriteBits(n, x)
+}
+
+// ReadExpGolomb reads a k-th order exponential-Golomb code from r.
+// Returns ErrOutOfRange if k is greater than 63,
+// ErrInvalidCode if the code is too long to decode into a uint64,
+// and io.ErrUnexpectedEOF if the stream ends within the code.
+func ReadExpGolomb(r BitReader, k int) (uint64, error) {
+	if k < 0 || k > 63 {
+		return 0, ErrOutOfRange
+	}
+	lz, err := readUnary(r)
+	if err != nil {
+		return 0, err
+	}
+	if lz > uint64(63 - k) {
+		return 0, ErrInvalidCode
+	}
+	n := int(lz) + k		// bits following the marker bit
+	x, err := readRest(r, n)
+	if err != nil {
+		return 0, err
+	}
+	return (1 << n | x) - 1 << k, nil
+}
+
+// WriteUE writes v to w as an unsigned Exp-Golomb code, ue(v),
+// the zeroth-order exponential-Golomb code.
+func WriteUE(w BitWriter, v uint64) error {
+	return WriteExpGolomb(w, v, 0)
+}
+
+// ReadUE reads an unsigned Exp-Golomb code, ue(v), from r.
+func ReadUE(r BitReader) (uint64, error) {
+	return ReadExpGolomb(r, 0)
+}
+
+// WriteSE writes v to w as a signed Exp-Golomb code, se(v),
+// mapping positive values v to 2v-1 and others to -2v
+// before encoding them as for WriteUE.
+// Returns ErrOutOfRange for the most negative int64,
+// whose mapping does not fit in 64 bits.
+func WriteSE(w BitWriter, v int64) error {
+	switch {
+	case v > 0:
+		return WriteUE(w, uint64(v) << 1 - 1)
+	case v == -1 << 63:
+		return ErrOutOfRange
+	default:
+		return WriteUE(w, uint64(-v) << 1)
+	}
+}
+
+// ReadSE reads a signed Exp-Golomb code, se(v), from r.
+func ReadSE(r BitReader) (int64, error) {
+	u, err := ReadUE(r)
+	if u & 1 != 0 {
+		return int64(u >> 1) + 1, err
+	}
+	return -int64(u >> 1), err
+}
@@ -0,0 +1,174 @@
+package bytebits
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+
+func TestGolomb(t *testing.T) {
+	// Golomb(5) codes from the standard table
+	for _, c := range []struct {
+		v uint64
+		code string
+	}{
+		{0, "100"}, {1, "101"}, {2, "110"}, {3, "1110"}, {4, "1111"},
+		{5, "0100"}, {9, "01111"}, {10, "00100"},
+	} {
+		w := NewAppendWriter(nil)
+		WriteGolomb(w, c.v, 5)
+		f := BigEndian.Field(w.Bytes(), 0, w.Len())
+		if s := f.String(); s != c.code {
+			t.Errorf("Golomb(5) of %v: got %v, want %v", c.v, s, c.code)
+		}
+	}
+
+	// Round trips for a range of parameters and values
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	var vals []uint64
+	for i := 0; i < 1000; i++ {
+		m := uint64(1 + i % 37)
+		v := uint64(rand.Intn(1000))
+		vals = append(vals, v)
+		WriteGolomb(w, v, m)
+		WriteRice(w, v, i % 9)
+		WriteRiceSigned(w, int64(v) - 500, i % 9)
+	}
+	WriteRice(w, ^uint64(0), 63)
+	w.Flush()
+	r := NewReader(&out)
+	for i, want := range vals {
+		if v, err := ReadGolomb(r, uint64(1 + i % 37)); err != nil ||
+				v != want {
+			t.Fatalf("ReadGolomb %v: got %v, %v, want %v",
+				i, v, err, want)
+		}
+		if v, err := ReadRice(r, i % 9); err != nil || v != want {
+			t.Fatalf("ReadRice %v: got %v, %v, want %v",
+				i, v, err, want)
+		}
+		if v, err := ReadRiceSigned(r, i % 9); err != nil ||
+				v != int64(want) - 500 {
+			t.Fatalf("ReadRiceSigned %v: got %v, %v", i, v, err)
+		}
+	}
+	if v, err := ReadRice(r, 63); err != nil || v != ^uint64(0) {
+		t.Errorf("ReadRice of max value: got %x, %v", v, err)
+	}
+
+	// Truncated codes and bad parameters
+	if _, err := ReadRice(NewReader(bytes.NewReader([]byte{0x00})), 2);
+			err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadRice of truncated code: got %v", err)
+	}
+	if err := WriteGolomb(w, 1, 0); err != ErrOutOfRange {
+		t.Errorf("WriteGolomb with m=0: got %v", err)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	for _, c := range []struct {
+		v, n uint64
+		code string
+	}{
+		{0, 1, ""}, {0, 2, "0"}, {1, 2, "1"},
+		{0, 5, "00"}, {2, 5, "10"}, {3, 5, "110"}, {4, 5, "111"},
+		{5, 8, "101"}, {0, 10, "000"}, {5, 10, "101"}, {9, 10, "1111"},
+	} {
+		w := NewAppendWriter(nil)
+		WriteTruncated(w, c.v, c.n)
+		f := BigEndian.Field(w.Bytes(), 0, w.Len())
+		if s := f.String(); s != c.code {
+			t.Errorf("truncated %v of %v: got %q, want %q",
+				c.v, c.n, s, c.code)
+		}
+		if v, err := ReadTruncated(f.Reader(), c.n); v != c.v || err != nil {
+			t.Errorf("ReadTruncated %q of %v: got %v, %v",
+				c.code, c.n, v, err)
+		}
+	}
+
+	// Extreme ranges and invalid values
+	w := NewAppendWriter(nil)
+	WriteTruncated(w, 1 << 63, ^uint64(0))
+	WriteTruncated(w, ^uint64(0) - 1, ^uint64(0))
+	r := BigEndian.Field(w.Bytes(), 0, w.Len()).Reader()
+	if v, err := ReadTruncated(r, ^uint64(0)); v != 1 << 63 || err != nil {
+		t.Errorf("ReadTruncated of 2^63: got %x, %v", v, err)
+	}
+	if v, err := ReadTruncated(r, ^uint64(0)); v != ^uint64(0) - 1 || err != nil {
+		t.Errorf("ReadTruncated of max value: got %x, %v", v, err)
+	}
+	if err := WriteTruncated(w, 5, 5); err != ErrOutOfRange {
+		t.Errorf("WriteTruncated out of range: got %v", err)
+	}
+	r = BigEndian.Field([]byte{0xff}, 0, 2).Reader()
+	if _, err := ReadTruncated(r, 5); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadTruncated of short code: got %v", err)
+	}
+}
+
+func TestExpGolomb(t *testing.T) {
+	for _, c := range []struct {
+		v int64
+		ue, se string
+	}{
+		{0, "1", "1"},
+		{1, "010", "010"},
+		{2, "011", "00100"},
+		{3, "00100", "00110"},
+		{-3, "", "00111"},
+		{8, "0001001", "000010000"},
+	} {
+		if c.ue != "" {
+			w := NewAppendWriter(nil)
+			WriteUE(w, uint64(c.v))
+			if s := BigEndian.Field(w.Bytes(), 0, w.Len()).String();
+					s != c.ue {
+				t.Errorf("ue(%v): got %v, want %v", c.v, s, c.ue)
+			}
+		}
+		w := NewAppendWriter(nil)
+		WriteSE(w, c.v)
+		if s := BigEndian.Field(w.Bytes(), 0, w.Len()).String(); s != c.se {
+			t.Errorf("se(%v): got %v, want %v", c.v, s, c.se)
+		}
+	}
+
+	// Round trips, including k-th order codes and extreme values
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	vals := []uint64{0, 1, 2, 1000, 1 << 40, 1 << 63, ^uint64(0) - 1}
+	for _, v := range vals {
+		WriteUE(w, v)
+		WriteExpGolomb(w, v >> 3, 3)
+		WriteSE(w, int64(v >> 1))
+	}
+	w.Flush()
+	r := NewReader(&out)
+	for _, v := range vals {
+		if u, err := ReadUE(r); err != nil || u != v {
+			t.Errorf("ReadUE: got %x, %v, want %x", u, err, v)
+		}
+		if u, err := ReadExpGolomb(r, 3); err != nil || u != v >> 3 {
+			t.Errorf("ReadExpGolomb: got %x, %v, want %x", u, err, v >> 3)
+		}
+		if s, err := ReadSE(r); err != nil || s != int64(v >> 1) {
+			t.Errorf("ReadSE: got %v, %v, want %v", s, err, v >> 1)
+		}
+	}
+
+	if err := WriteUE(w, ^uint64(0)); err != ErrOutOfRange {
+		t.Errorf("WriteUE of max value: got %v", err)
+	}
+	if err := WriteSE(w, -1 << 63); err != ErrOutOfRange {
+		t.Errorf("WriteSE of min value: got %v", err)
+	}
+	long := NewReader(bytes.NewReader(append(make([]byte, 8), 0x80)))
+	if _, err := ReadUE(long); err != ErrInvalidCode {
+		t.Errorf("ReadUE of overlong code: got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package bytebits
+
+
+// Groups splits the bit-field of width w bits starting at offset xofs in x
+// into successive k-bit groups, where k must be between 1 and 64,
+// and stores the value of each group into dst, first group first,
+// as in base32 or base64 encoding with 5-bit or 6-bit groups.
+// The bit order determines how each group's bits form its value:
+// in BigEndian order the first bit of a group is most significant,
+// as base32 and base64 require, and in LittleEndian order least significant.
+// If w is not a multiple of k, the last group is completed
+// with zero bits following the end of the field.
+// Returns dst[:(w+k-1)/k], or a new slice if dst is not large enough.
+// Panics with ErrOutOfRange if k is not within range.
+func Groups(order BitOrder, x []byte, xofs, w, k int,
+		dst []uint64) []uint64 {
+	if k < 1 || k > 64 {
+		panic(ErrOutOfRange)
+	}
+	n := (w + k - 1) / k
+	if len(dst) < n {
+		dst = make([]uint64, n)
+	}
+	dst = dst[:n]
+	lsb := order.LSBFirst()
+	for i := range dst {
+		m := min(k, w - i*k)
+		v := order.Uint(x, xofs + i*k, m)
+		if !lsb {
+			v <<= k - m	// zero bits follow in the low end
+		}
+		dst[i] = v
+	}
+	return dst
+}
+
+// PutGroups sets the bit-field of width w bits starting at zofs in slice z
+// from successive k-bit groups in v, in the layout that Groups produces,
+// where k must be between 1 and 64.
+// If w is not a multiple of k, the bits of the last group
+// that would extend beyond the end of the field are ignored.
+// Copies z and returns a new slice if z is nil or not large enough.
+// Panics with ErrOutOfRange if k is not within range,
+// or with ErrLengthMismatch if v does not have exactly (w+k-1)/k groups.
+func PutGroups(order BitOrder, z []byte, zofs, w, k int,
+		v []uint64) []byte {
+	if k < 1 || k > 64 {
+		panic(ErrOutOfRange)
+	}
+	if len(v) != (w + k - 1) / k {
+		panic(ErrLengthMismatch)
+	}
+	lsb := order.LSBFirst()
+	for i, g := range v {
+		m := min(k, w - i*k)
+		if !lsb {
+			g >>= k - m
+		}
+		z = order.PutUint(z, zofs + i*k, m, g)
+	}
+	return z
+}
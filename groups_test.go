@@ -0,0 +1,79 @@
+package bytebits
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"testing"
+)
+
+
+func TestGroupsBase64(t *testing.T) {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	const alphabet32 = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	for l := 0; l <= len(testBits); l++ {
+		x := testBits[:l]
+		var s []byte
+		for _, g := range Groups(BigEndian, x, 0, l * 8, 6, nil) {
+			s = append(s, alphabet[g])
+		}
+		if want := base64.RawStdEncoding.EncodeToString(x); string(s) != want {
+			t.Errorf("base64 of %v bytes: got %s, want %s", l, s, want)
+		}
+		s = s[:0]
+		for _, g := range Groups(BigEndian, x, 0, l * 8, 5, nil) {
+			s = append(s, alphabet32[g])
+		}
+		want := base32.StdEncoding.WithPadding(base32.NoPadding).
+			EncodeToString(x)
+		if string(s) != want {
+			t.Errorf("base32 of %v bytes: got %s, want %s", l, s, want)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian,
+			wrappedOrder{BigEndian}, wrappedOrder{LittleEndian}} {
+		for _, k := range []int{1, 5, 6, 11, 63, 64} {
+			for _, w := range []int{0, 1, 13, 64, 100, 180} {
+				g := Groups(order, testBits, 3, w, k, nil)
+				if len(g) != (w + k - 1) / k {
+					t.Fatalf("%v Groups(%v) of %v bits: got %v groups",
+						order, k, w, len(g))
+				}
+
+				// The groups read back as a stream, zero-padded
+				padded := order.Copy(make([]byte, 32), testBits, 0, 3, w)
+				r := order.NewReader(bytes.NewReader(padded))
+				for i, v := range g {
+					if want, _ := r.ReadBits(k); v != want {
+						t.Fatalf("%v Groups(%v) of %v bits: group %v is %x, want %x",
+							order, k, w, i, v, want)
+					}
+				}
+
+				z := PutGroups(order, bytes.Repeat([]byte{0xa5}, 25),
+					5, w, k, g)
+				if order.Uint(z, 0, 5) != order.Uint([]byte{0xa5}, 0, 5) ||
+						!order.Field(z, 5, w).Equal(
+							order.Field(testBits, 3, w)) {
+					t.Errorf("%v PutGroups(%v) of %v bits: wrong result",
+						order, k, w)
+				}
+				if order.Bit(z, 5 + w) != order.Bit([]byte{0xa5}, (5 + w) & 7) {
+					t.Errorf("%v PutGroups(%v) of %v bits: wrote past end",
+						order, k, w)
+				}
+			}
+		}
+	}
+	if panicValue(func() { Groups(BigEndian, testBits, 0, 8, 65, nil) }) !=
+			ErrOutOfRange {
+		t.Errorf("Groups with k=65 did not panic")
+	}
+	if panicValue(func() { PutGroups(BigEndian, nil, 0, 8, 3, nil) }) !=
+			ErrLengthMismatch {
+		t.Errorf("PutGroups with too few groups did not panic")
+	}
+}
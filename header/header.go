@@ -0,0 +1,69 @@
+// Package header describes the fields of packed binary headers,
+// such as network packet and spacecraft telemetry headers,
+// as tables of bit-field descriptors.
+//
+// Each Field gives a bit offset and width within a header,
+// in network (big-endian) bit order,
+// and its Get and Put methods are built on the bit-field operations
+// of the bytebits package.
+// Since a Field is an integer, header layouts can be declared as constants,
+// as the netbits and ccsds packages do.
+//
+package header
+
+import (
+	"github.com/bford/bytebits"
+)
+
+
+// Field describes a bit-field of a header,
+// starting Ofs bits from the beginning of the header and Width bits wide.
+// A Field packs the offset and width into a single integer,
+// Ofs << 8 | Width, so that header layouts can be declared as constants.
+type Field uint32
+
+// MakeField returns a Field starting ofs bits from the beginning
+// of the header and w bits wide, where w is at most 64.
+func MakeField(ofs, w int) Field {
+	if ofs < 0 || w < 0 || w > 64 {
+		panic(bytebits.ErrOutOfRange)
+	}
+	return Field(ofs << 8 | w)
+}
+
+// Ofs returns the bit offset of field f from the beginning of the header.
+func (f Field) Ofs() int {
+	return int(f >> 8)
+}
+
+// Width returns the width of field f in bits.
+func (f Field) Width() int {
+	return int(f & 0xff)
+}
+
+// Get returns the value of field f in header h,
+// which must be long enough to hold the field.
+func (f Field) Get(h []byte) uint64 {
+	return bytebits.BigEndian.Uint(h, f.Ofs(), f.Width())
+}
+
+// Put sets field f in header h to the least-significant bits of v.
+// Panics with bytebits.ErrShortBuffer if h is too short to hold the field.
+func (f Field) Put(h []byte, v uint64) {
+	if f.Ofs() + f.Width() > len(h) * 8 {
+		panic(bytebits.ErrShortBuffer)
+	}
+	bytebits.BigEndian.PutUint(h, f.Ofs(), f.Width(), v)
+}
+
+// Max returns the maximum value field f can hold.
+func (f Field) Max() uint64 {
+	return 1 << f.Width() - 1
+}
+
+// Next returns the successor of counter value v in field f,
+// wrapping around to zero after the maximum value the field can hold,
+// as sequence and frame counters do.
+func (f Field) Next(v uint64) uint64 {
+	return (v + 1) & f.Max()
+}
@@ -0,0 +1,50 @@
+package header
+
+import (
+	"testing"
+
+	"github.com/bford/bytebits"
+)
+
+
+func TestField(t *testing.T) {
+	f := MakeField(13, 11)
+	if f != 13 << 8 | 11 || f.Ofs() != 13 || f.Width() != 11 {
+		t.Errorf("MakeField(13, 11) = %v/%v", f.Ofs(), f.Width())
+	}
+	h := make([]byte, 3)
+	f.Put(h, 0xfff)			// high bit is dropped
+	if h[0] != 0x00 || h[1] != 0x07 || h[2] != 0xff || f.Get(h) != 0x7ff {
+		t.Errorf("Put 0xfff: got %x, Get %x", h, f.Get(h))
+	}
+	if f.Max() != 0x7ff || f.Next(0x7fe) != 0x7ff || f.Next(0x7ff) != 0 {
+		t.Errorf("Max/Next: got %x %x %x",
+			f.Max(), f.Next(0x7fe), f.Next(0x7ff))
+	}
+	if w := MakeField(0, 64); w.Max() != ^uint64(0) {
+		t.Errorf("64-bit Max: got %x", w.Max())
+	}
+}
+
+func TestMakeFieldRange(t *testing.T) {
+	for _, c := range []struct{ ofs, w int }{ {-1, 8}, {0, -1}, {0, 65} } {
+		func() {
+			defer func() {
+				if v := recover(); v != bytebits.ErrOutOfRange {
+					t.Errorf("MakeField(%v, %v) panicked with %v",
+						c.ofs, c.w, v)
+				}
+			}()
+			MakeField(c.ofs, c.w)
+		}()
+	}
+}
+
+func TestShortBuffer(t *testing.T) {
+	defer func() {
+		if v := recover(); v != bytebits.ErrShortBuffer {
+			t.Errorf("short header put panicked with %v", v)
+		}
+	}()
+	MakeField(13, 11).Put(make([]byte, 2), 1)
+}
@@ -0,0 +1,350 @@
+package bytebits
+
+import (
+	"math/bits"
+	"sort"
+)
+
+
+const maxHuffmanLen = 31	// Longest supported Huffman code
+const huffmanTableBits = 10	// Bits decoded by one table lookup
+
+
+// HuffmanDecoder decodes symbols encoded with a canonical Huffman code,
+// as in DEFLATE and JPEG, from a bit stream.
+// In a canonical code, codes of each length are consecutive integers
+// assigned to symbols in increasing order,
+// with all codes of one length preceding those of the next,
+// so that the code is fully described by the length of each symbol's code.
+// Codes are read first bit first, with the first bit
+// as the most-significant bit of the code,
+// regardless of the bit order of the stream.
+//
+// When reading from a Reader, Decode uses Peek and Consume
+// to decode most symbols with a single table lookup;
+// other BitReaders, and codes longer than the table covers,
+// are decoded a bit at a time.
+//
+type HuffmanDecoder struct {
+	count [maxHuffmanLen+1]int	// Number of codes of each length
+	syms []int			// Symbols ordered by code
+	maxLen int			// Longest code length in use
+	tbits int			// Index bits of table
+	table []uint32			// Symbol << 8 | length, 0 if long
+}
+
+// NewHuffmanDecoder returns a decoder for the canonical Huffman code
+// in which symbol i has a code lengths[i] bits long,
+// or no code if lengths[i] is zero.
+// The code may be incomplete, as DEFLATE permits,
+// in which case decoding an unassigned code fails with ErrInvalidCode.
+// Returns ErrInvalidCode if the lengths are oversubscribed,
+// describing more codes than there are bit patterns,
+// and ErrOutOfRange if any length is negative or greater than 31.
+func NewHuffmanDecoder(lengths []int) (*HuffmanDecoder, error) {
+	d := &HuffmanDecoder{}
+	for _, l := range lengths {
+		if l < 0 || l > maxHuffmanLen {
+			return nil, ErrOutOfRange
+		}
+		d.count[l]++
+		if l > d.maxLen {
+			d.maxLen = l
+		}
+	}
+	d.count[0] = 0
+	left := 1			// bit patterns not yet assigned
+	for l := 1; l <= d.maxLen; l++ {
+		if left = left << 1 - d.count[l]; left < 0 {
+			return nil, ErrInvalidCode
+		}
+	}
+
+	// Order symbols by code length, then by symbol
+	var offs [maxHuffmanLen+2]int
+	for l := 1; l <= d.maxLen; l++ {
+		offs[l+1] = offs[l] + d.count[l]
+	}
+	d.syms = make([]int, offs[d.maxLen+1])
+	for s, l := range lengths {
+		if l > 0 {
+			d.syms[offs[l]] = s
+			offs[l]++
+		}
+	}
+
+	// Fill the lookup table with codes no longer than its index
+	d.tbits = d.maxLen
+	if d.tbits > huffmanTableBits {
+		d.tbits = huffmanTableBits
+	}
+	d.table = make([]uint32, 1 << d.tbits)
+	code, i := 0, 0
+	for l := 1; l <= d.tbits; l++ {
+		for n := d.count[l]; n > 0; n-- {
+			fill := 1 << (d.tbits - l)
+			for j := code * fill; j < (code + 1) * fill; j++ {
+				d.table[j] = uint32(d.syms[i]) << 8 | uint32(l)
+			}
+			code++
+			i++
+		}
+		code <<= 1
+	}
+	return d, nil
+}
+
+// Decode reads one code from r and returns its symbol.
+// Returns ErrInvalidCode if the bits read are not a code,
+// EOF if r is at its end,
+// and io.ErrUnexpectedEOF if r ends within a code.
+func (d *HuffmanDecoder) Decode(r BitReader) (int, error) {
+	if br, ok := r.(*Reader); ok && d.tbits > 0 {
+		v, err := br.Peek(d.tbits)
+		if err == nil {
+			if br.lsb {
+				v = uint64(bits.Reverse32(uint32(v))) >> (32 - d.tbits)
+			}
+			if e := d.table[v]; e != 0 {
+				br.Consume(int(e & 0xff))
+				return int(e >> 8), nil
+			}
+		}
+	}
+	return d.decodeSlow(r)
+}
+
+// decodeSlow decodes a code a bit at a time,
+// taking advantage of the consecutive codes of each length.
+func (d *HuffmanDecoder) decodeSlow(r BitReader) (int, error) {
+	code, first, index := 0, 0, 0
+	for l := 1; l <= d.maxLen; l++ {
+		var b uint64
+		var err error
+		if l == 1 {
+			b, err = r.ReadBits(1)
+		} else {
+			b, err = readRest(r, 1)
+		}
+		if err != nil {
+			return 0, err
+		}
+		code |= int(b)
+		n := d.count[l]
+		if code - first < n {
+			return d.syms[index + code - first], nil
+		}
+		index += n
+		first = (first + n) << 1
+		code <<= 1
+	}
+	return 0, ErrInvalidCode
+}
+
+
+// HuffmanCode is the code assigned to one symbol of a Huffman code:
+// the Len-bit integer Code, with its most-significant bit sent first.
+// A Len of zero means the symbol has no code.
+type HuffmanCode struct {
+	Code uint32
+	Len int
+}
+
+// CanonicalHuffmanCodes returns the canonical Huffman code
+// in which symbol i has a code lengths[i] bits long,
+// as decoded by NewHuffmanDecoder given the same lengths.
+// Returns ErrInvalidCode if the lengths are oversubscribed,
+// and ErrOutOfRange if any length is negative or greater than 31.
+func CanonicalHuffmanCodes(lengths []int) ([]HuffmanCode, error) {
+	var count [maxHuffmanLen+1]int
+	for _, l := range lengths {
+		if l < 0 || l > maxHuffmanLen {
+			return nil, ErrOutOfRange
+		}
+		count[l]++
+	}
+	count[0] = 0
+	var next [maxHuffmanLen+1]uint32	// next code of each length
+	code, left := uint32(0), 1
+	for l := 1; l <= maxHuffmanLen; l++ {
+		code = (code + uint32(count[l-1])) << 1
+		next[l] = code
+		if left = left << 1 - count[l]; left < 0 {
+			return nil, ErrInvalidCode
+		}
+	}
+	codes := make([]HuffmanCode, len(lengths))
+	for s, l := range lengths {
+		if l > 0 {
+			codes[s] = HuffmanCode{next[l], l}
+			next[l]++
+		}
+	}
+	return codes, nil
+}
+
+// HuffmanLengths returns optimal code lengths for symbols
+// occurring with the given frequencies,
+// limited to maxLen bits as formats such as DEFLATE (15) require,
+// for use with CanonicalHuffmanCodes.
+// Symbols of zero frequency get no code,
+// and a lone symbol gets a code of one bit.
+// Where the limit binds, the lengths are adjusted as zlib does,
+// yielding a good though not necessarily optimal limited code.
+// Returns ErrOutOfRange if maxLen is greater than 31 or too small
+// to give each symbol a code, or if any frequency is negative.
+func HuffmanLengths(freqs []int, maxLen int) ([]int, error) {
+	if maxLen < 1 || maxLen > maxHuffmanLen {
+		return nil, ErrOutOfRange
+	}
+	var syms []int			// used symbols by increasing frequency
+	for s, f := range freqs {
+		if f < 0 {
+			return nil, ErrOutOfRange
+		} else if f > 0 {
+			syms = append(syms, s)
+		}
+	}
+	n := len(syms)
+	if n > 1 << maxLen {
+		return nil, ErrOutOfRange
+	}
+	lengths := make([]int, len(freqs))
+	switch n {
+	case 0:
+		return lengths, nil
+	case 1:
+		lengths[syms[0]] = 1
+		return lengths, nil
+	}
+	sort.SliceStable(syms, func(i, j int) bool {
+		return freqs[syms[i]] < freqs[syms[j]]
+	})
+
+	// Build the tree with the two-queue method:
+	// nodes 0..n-1 are leaves, in syms order, n.. are internal nodes,
+	// created in order of nondecreasing weight.
+	weight := make([]int, 2*n - 1)
+	parent := make([]int, 2*n - 1)
+	for i, s := range syms {
+		weight[i] = freqs[s]
+	}
+	leaf, inner := 0, n		// heads of the two queues
+	pick := func(next int) int {
+		if leaf < n && (inner >= next || weight[leaf] <= weight[inner]) {
+			leaf++
+			return leaf - 1
+		}
+		inner++
+		return inner - 1
+	}
+	for next := n; next < 2*n - 1; next++ {
+		a := pick(next)
+		b := pick(next)
+		weight[next] = weight[a] + weight[b]
+		parent[a], parent[b] = next, next
+	}
+
+	// Count the leaves at each depth, clamping overlong nodes to maxLen
+	// and counting them, leaves and internal nodes alike, as zlib does
+	depth := make([]int, 2*n - 1)
+	var count [maxHuffmanLen+1]int
+	overflow := 0
+	for i := 2*n - 3; i >= 0; i-- {		// parents precede children
+		if depth[i] = depth[parent[i]] + 1; depth[i] > maxLen {
+			depth[i] = maxLen
+			overflow++
+		}
+		if i < n {
+			count[depth[i]]++
+		}
+	}
+
+	// Restore the Kraft equality by pushing leaves deeper:
+	// each step moves a leaf from depth l < maxLen down a level,
+	// making room for the leaf beside it and one clamped leaf.
+	for overflow > 0 {
+		l := maxLen - 1
+		for count[l] == 0 {
+			l--
+		}
+		count[l]--
+		count[l+1] += 2
+		count[maxLen]--
+		overflow -= 2
+	}
+
+	// Give the longest codes to the least frequent symbols
+	i := 0
+	for l := maxLen; l > 0; l-- {
+		for k := count[l]; k > 0; k-- {
+			lengths[syms[i]] = l
+			i++
+		}
+	}
+	return lengths, nil
+}
+
+
+// HuffmanEncoder writes symbols to a bit stream using a Huffman code.
+type HuffmanEncoder struct {
+	codes []HuffmanCode	// Codes, most-significant bit first
+	rev []uint32		// Codes bit-reversed for LSB-first streams
+}
+
+// NewHuffmanEncoder returns an encoder for the given code table,
+// as returned by CanonicalHuffmanCodes or from any other source.
+func NewHuffmanEncoder(codes []HuffmanCode) *HuffmanEncoder {
+	e := &HuffmanEncoder{codes: codes, rev: make([]uint32, len(codes))}
+	for s, c := range codes {
+		if c.Len > 0 {
+			e.rev[s] = bits.Reverse32(c.Code) >> (32 - c.Len)
+		}
+	}
+	return e
+}
+
+// Encode writes the code for symbol sym to w, first bit first,
+// with the first bit being the code's most-significant bit.
+// Codes are written with a single WriteBits call to Writers,
+// AppendWriters, and fields, whose bit order is known;
+// to any other BitWriter, such as a CountingBitWriter
+// wrapping a Writer, they are written one bit at a time,
+// which is correct whichever bit order it uses.
+// Returns ErrOutOfRange if sym has no code.
+func (e *HuffmanEncoder) Encode(w BitWriter, sym int) error {
+	if sym < 0 || sym >= len(e.codes) || e.codes[sym].Len == 0 {
+		return ErrOutOfRange
+	}
+	c := e.codes[sym]
+	lsb, known := writerOrder(w)
+	switch {
+	case !known:
+		for i := c.Len - 1; i >= 0; i-- {
+			if err := w.WriteBits(1, uint64(c.Code >> i & 1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case lsb:
+		return w.WriteBits(c.Len, uint64(e.rev[sym]))
+	}
+	return w.WriteBits(c.Len, uint64(c.Code))
+}
+
+// writerOrder reports whether w is known to take the first bit
+// of each value it writes from the least-significant position,
+// and whether its bit order is known at all.
+func writerOrder(w BitWriter) (lsb, known bool) {
+	switch w := w.(type) {
+	case *Writer:
+		return w.lsb, true
+	case *AppendWriter:
+		return w.lsb, true
+	case *BigEndianField:
+		return false, true
+	case *LittleEndianField:
+		return true, true
+	}
+	return false, false
+}
@@ -0,0 +1,220 @@
+package bytebits
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+
+// huffmanCodes returns the canonical codes for the given code lengths,
+// computed directly from the definition in RFC 1951.
+func huffmanCodes(lengths []int) []uint64 {
+	var count [32]int
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [33]uint64
+	code := uint64(0)
+	for l := 1; l < 32; l++ {
+		code = (code + uint64(count[l-1])) << 1
+		next[l] = code
+	}
+	codes := make([]uint64, len(lengths))
+	for s, l := range lengths {
+		if l > 0 {
+			codes[s] = next[l]
+			next[l]++
+		}
+	}
+	return codes
+}
+
+func TestHuffmanDecoder(t *testing.T) {
+	// RFC 1951's example: lengths (3, 3, 3, 3, 3, 2, 4, 4)
+	// give codes 010 011 100 101 110 00 1110 1111
+	lengths := []int{3, 3, 3, 3, 3, 2, 4, 4}
+	if c := huffmanCodes(lengths); c[0] != 2 || c[5] != 0 || c[7] != 15 {
+		t.Fatalf("huffmanCodes: got %v", c)
+	}
+
+	// A complete code with many symbols longer than the lookup table
+	long := make([]int, 22)
+	for i := range long {
+		long[i] = 1 + i
+	}
+	long[20] = 0
+	long[21] = 20
+
+	for _, lens := range [][]int{lengths, long} {
+		d, err := NewHuffmanDecoder(lens)
+		if err != nil {
+			t.Fatalf("NewHuffmanDecoder: %v", err)
+		}
+		codes := huffmanCodes(lens)
+		var syms []int
+		for i := 0; i < 2000; i++ {
+			if s := rand.Intn(len(lens)); lens[s] > 0 {
+				syms = append(syms, s)
+			}
+		}
+		for _, order := range []BitOrder{BigEndian, LittleEndian} {
+			var out bytes.Buffer
+			w := order.NewWriter(&out)
+			for _, s := range syms {
+				for i := lens[s] - 1; i >= 0; i-- {
+					w.WriteBits(1, codes[s] >> i & 1)
+				}
+			}
+			w.Flush()
+			data := out.Bytes()
+
+			// Decode via the table, and bit by bit
+			readers := []BitReader{
+				order.NewReader(bytes.NewReader(data)),
+				order.Field(data, 0, len(data) * 8).Reader(),
+			}
+			for _, r := range readers {
+				for i, want := range syms {
+					s, err := d.Decode(r)
+					if err != nil || s != want {
+						t.Fatalf("%v %T Decode %v: "+
+							"got %v, %v, want %v",
+							order, r, i, s, err, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHuffmanDecoderErrors(t *testing.T) {
+	if _, err := NewHuffmanDecoder([]int{1, 1, 1}); err != ErrInvalidCode {
+		t.Errorf("oversubscribed code: got %v", err)
+	}
+	if _, err := NewHuffmanDecoder([]int{1, 32}); err != ErrOutOfRange {
+		t.Errorf("overlong code: got %v", err)
+	}
+
+	// An incomplete code with one symbol
+	d, _ := NewHuffmanDecoder([]int{0, 1})
+	r := NewReader(bytes.NewReader([]byte{0x40}))
+	if s, err := d.Decode(r); s != 1 || err != nil {
+		t.Errorf("Decode: got %v, %v, want 1", s, err)
+	}
+	if _, err := d.Decode(r); err != ErrInvalidCode {
+		t.Errorf("Decode of unassigned code: got %v", err)
+	}
+
+	d, _ = NewHuffmanDecoder([]int{2, 2, 2, 2})
+	r = NewReader(bytes.NewReader([]byte{0x00}))
+	for i := 0; i < 4; i++ {
+		d.Decode(r)
+	}
+	if _, err := d.Decode(r); err != EOF {
+		t.Errorf("Decode at end: got %v, want EOF", err)
+	}
+	f := BigEndian.Field([]byte{0x00}, 0, 1)
+	if _, err := d.Decode(f.Reader()); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode of truncated code: got %v", err)
+	}
+}
+
+func TestHuffmanEncoder(t *testing.T) {
+	lengths, err := HuffmanLengths([]int{1, 1, 2, 4, 0}, 15)
+	if err != nil || fmt.Sprint(lengths) != "[3 3 2 1 0]" {
+		t.Errorf("HuffmanLengths: got %v, %v", lengths, err)
+	}
+
+	// Random frequencies, with and without a binding length limit
+	for _, maxLen := range []int{15, 7} {
+		freqs := make([]int, 100)
+		for i := range freqs {
+			freqs[i] = rand.Intn(1 << uint(rand.Intn(20)))
+		}
+		lengths, err := HuffmanLengths(freqs, maxLen)
+		if err != nil {
+			t.Fatalf("HuffmanLengths: %v", err)
+		}
+		kraft := 0.0
+		for s, l := range lengths {
+			if (l == 0) != (freqs[s] == 0) || l > maxLen {
+				t.Errorf("symbol %v with frequency %v: length %v",
+					s, freqs[s], l)
+			}
+			if l > 0 {
+				kraft += 1 / float64(uint64(1) << l)
+			}
+		}
+		if kraft != 1 {
+			t.Errorf("HuffmanLengths limit %v: Kraft sum %v", maxLen, kraft)
+		}
+
+		codes, err := CanonicalHuffmanCodes(lengths)
+		if err != nil {
+			t.Fatalf("CanonicalHuffmanCodes: %v", err)
+		}
+		for s, c := range huffmanCodes(lengths) {
+			if codes[s].Len != lengths[s] || uint64(codes[s].Code) != c {
+				t.Errorf("symbol %v: got code %v, want %x", s, codes[s], c)
+			}
+		}
+
+		// Round trip through the decoder in both bit orders
+		e := NewHuffmanEncoder(codes)
+		d, _ := NewHuffmanDecoder(lengths)
+		var syms []int
+		for len(syms) < 1000 {
+			if s := rand.Intn(100); freqs[s] > 0 {
+				syms = append(syms, s)
+			}
+		}
+		for _, order := range []BitOrder{BigEndian, LittleEndian} {
+			w := order.NewAppendWriter(nil)
+			for _, s := range syms {
+				if err := e.Encode(w, s); err != nil {
+					t.Fatalf("Encode: %v", err)
+				}
+			}
+			r := order.NewReader(bytes.NewReader(w.Bytes()))
+			for i, want := range syms {
+				if s, err := d.Decode(r); s != want || err != nil {
+					t.Fatalf("%v Decode %v: got %v, %v, want %v",
+						order, i, s, err, want)
+				}
+			}
+
+			// A wrapper hides the bit order of the underlying Writer
+			var out bytes.Buffer
+			bw := order.NewWriter(&out)
+			cw := NewCountingBitWriter(bw)
+			for _, s := range syms {
+				if err := e.Encode(cw, s); err != nil {
+					t.Fatalf("Encode to wrapped writer: %v", err)
+				}
+			}
+			bw.Flush()
+			if !bytes.Equal(out.Bytes(), w.Bytes()) {
+				t.Errorf("%v Encode to wrapped writer: wrong output",
+					order)
+			}
+		}
+	}
+
+	if _, err := HuffmanLengths(make([]int, 5), 0); err != ErrOutOfRange {
+		t.Errorf("HuffmanLengths with no bits: got %v", err)
+	}
+	if _, err := HuffmanLengths([]int{1, 1, 1}, 1); err != ErrOutOfRange {
+		t.Errorf("HuffmanLengths with too few bits: got %v", err)
+	}
+	if l, _ := HuffmanLengths([]int{0, 5}, 15); l[1] != 1 {
+		t.Errorf("HuffmanLengths of lone symbol: got %v", l)
+	}
+	e := NewHuffmanEncoder([]HuffmanCode{{0, 1}, {}})
+	if err := e.Encode(NewAppendWriter(nil), 1); err != ErrOutOfRange {
+		t.Errorf("Encode of symbol without code: got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package bytebits
+
+import (
+	"encoding/binary"
+)
+
+
+// Overflow selects the behavior of packed-lane arithmetic
+// when a lane's result does not fit in the lane.
+type Overflow bool
+
+const Wrap Overflow = false		// Wrap around modulo 2^n
+const Saturate Overflow = true		// Clamp to the lane's range
+
+
+// The packed-lane functions treat a byte slice as a vector
+// of unsigned n-bit integer lanes in big-endian bit order,
+// where n is a power of two from 1 to 64:
+// lane 0 occupies the first n bits of the slice, lane 1 the next n, etc.
+// They operate on 64 bits at a time using SWAR
+// (SIMD within a register) techniques,
+// so that arrays of small counters or histogram bins
+// may be processed without unpacking them.
+
+// laneMasks returns the masks for n-bit lanes within a 64-bit word
+// with the least-significant and most-significant bit of each lane set,
+// respectively.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64.
+func laneMasks(n int) (lo, hi uint64) {
+	if n < 1 || n > 64 || n & (n-1) != 0 {
+		panic(ErrOutOfRange)
+	}
+	if n == 64 {
+		return 1, 1 << 63
+	}
+	lo = ^uint64(0) / (1 << n - 1)
+	return lo, lo << (n-1)
+}
+
+// laneSpread expands a word in which only the most-significant bit
+// of some n-bit lanes is set into a mask with all bits of those lanes set.
+func laneSpread(c uint64, n int) uint64 {
+	return c | (c - (c >> (n-1)))
+}
+
+// laneLen checks that slices x and y are of the same length
+// and consist of a whole number of n-bit lanes, and returns that length.
+func laneLen(x, y []byte, n int) int {
+	l := len2(x, y)
+	if (l * 8) & (n-1) != 0 {
+		panic(ErrOutOfRange)
+	}
+	return l
+}
+
+// laneOp sets z to the result of applying op to each 64-bit word
+// of slices x and y, and returns z,
+// padding any final partial word with zero bytes.
+func laneOp(z, x, y []byte, op func(x, y uint64) uint64) []byte {
+	l := len(x)
+	z = Grow(z, l)
+	i := 0
+	for ; i + 8 <= l; i += 8 {
+		binary.BigEndian.PutUint64(z[i:], op(
+			binary.BigEndian.Uint64(x[i:]),
+			binary.BigEndian.Uint64(y[i:])))
+	}
+	if i < l {
+		var xw, yw [8]byte
+		copy(xw[:], x[i:])
+		copy(yw[:], y[i:])
+		binary.BigEndian.PutUint64(xw[:], op(
+			binary.BigEndian.Uint64(xw[:]),
+			binary.BigEndian.Uint64(yw[:])))
+		copy(z[i:l], xw[:])
+	}
+	return z
+}
+
+// AddLanes sets z to the lane-wise sum of slices x and y,
+// treated as vectors of unsigned n-bit lanes, and returns z.
+// Sums that overflow a lane either wrap around or saturate
+// at the lane's maximum value 2^n-1, as selected by ovf.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// or if the slices do not hold a whole number of lanes.
+// Allocates and returns a new destination slice if z is not long enough.
+func AddLanes(z, x, y []byte, n int, ovf Overflow) []byte {
+	_, h := laneMasks(n)
+	laneLen(x, y, n)
+	return laneOp(z, x, y, func(x, y uint64) uint64 {
+		s := ((x &^ h) + (y &^ h)) ^ ((x ^ y) & h)
+		if ovf == Saturate {
+			c := ((x & y) | ((x | y) &^ s)) & h	// carry-outs
+			s |= laneSpread(c, n)
+		}
+		return s
+	})
+}
+
+// SubLanes sets z to the lane-wise difference of slices x and y,
+// treated as vectors of unsigned n-bit lanes, and returns z.
+// Differences that underflow a lane either wrap around
+// or saturate at zero, as selected by ovf.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// or if the slices do not hold a whole number of lanes.
+// Allocates and returns a new destination slice if z is not long enough.
+func SubLanes(z, x, y []byte, n int, ovf Overflow) []byte {
+	_, h := laneMasks(n)
+	laneLen(x, y, n)
+	return laneOp(z, x, y, func(x, y uint64) uint64 {
+		d := ((x | h) - (y &^ h)) ^ ((x ^ ^y) & h)
+		if ovf == Saturate {
+			d &^= laneSpread(laneBorrow(x, y, h), n)
+		}
+		return d
+	})
+}
+
+
+// LaneCmp specifies a comparison to be performed between packed lanes.
+type LaneCmp int
+
+const (
+	LaneEq LaneCmp = iota	// Equal
+	LaneNe			// Not equal
+	LaneLt			// Less than
+	LaneLe			// Less than or equal
+	LaneGt			// Greater than
+	LaneGe			// Greater than or equal
+)
+
+// laneRep returns a mask with the low w bits of every p-bit period set,
+// where w <= p and p is a power of two from 1 to 64.
+func laneRep(w, p int) uint64 {
+	return (^uint64(0) >> (64 - w)) * (^uint64(0) / (^uint64(0) >> (64 - p)))
+}
+
+// laneBorrow returns a word with the most-significant bit set
+// of each n-bit lane in which x is less than y, given the high-bit mask h.
+func laneBorrow(x, y, h uint64) uint64 {
+	d := ((x | h) - (y &^ h)) ^ ((x ^ ^y) & h)
+	return ((^x & y) | (^(x ^ y) & d)) & h
+}
+
+// laneNonzero returns a word with the most-significant bit set
+// of each n-bit lane of x that is nonzero, given the lane masks lo and hi.
+func laneNonzero(x, lo, hi uint64) uint64 {
+	return (((x &^ hi) + (hi - lo)) | x) & hi
+}
+
+// laneCmp returns a word with the most-significant bit set
+// of each n-bit lane for which the comparison cmp of x to y holds.
+func laneCmp(x, y, lo, hi uint64, cmp LaneCmp) uint64 {
+	switch cmp {
+	case LaneEq:
+		return ^laneNonzero(x ^ y, lo, hi) & hi
+	case LaneNe:
+		return laneNonzero(x ^ y, lo, hi)
+	case LaneLt:
+		return laneBorrow(x, y, hi)
+	case LaneLe:
+		return ^laneBorrow(y, x, hi) & hi
+	case LaneGt:
+		return laneBorrow(y, x, hi)
+	case LaneGe:
+		return ^laneBorrow(x, y, hi) & hi
+	}
+	panic(ErrOutOfRange)
+}
+
+// laneGather packs the most-significant bits of the n-bit lanes in word w,
+// which must have no other bits set, into the least-significant 64/n bits
+// of the result, preserving their order.
+func laneGather(w uint64, n int) uint64 {
+	w >>= n-1
+	for g, d := 1, n; d < 64; g, d = g*2, d*2 {
+		w = (w | w >> (d - g)) & laneRep(2*g, 2*d)
+	}
+	return w
+}
+
+// CmpLanes compares slices x and y lane by lane,
+// treated as vectors of unsigned n-bit lanes,
+// and sets z to a bit vector in big-endian bit order
+// holding one bit per lane, which is 1 if comparison cmp holds
+// between the corresponding lanes of x and y, or 0 otherwise.
+// The mask occupies one byte of z for every eight lanes, rounded up,
+// with any remaining bits in its last byte set to zero.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// if the slices do not hold a whole number of lanes,
+// or if cmp is not a valid comparison.
+// Allocates and returns a new destination slice if z is not long enough.
+func CmpLanes(z, x, y []byte, n int, cmp LaneCmp) []byte {
+	lo, hi := laneMasks(n)
+	l := laneLen(x, y, n)
+	nl := l * 8 / n		// total number of lanes
+	nb := (nl + 7) >> 3
+	z = Grow(z, nb)
+	if nb > 0 {
+		z[nb-1] = 0
+	}
+
+	m := 64 / n		// lanes per word
+	zb, zo := z, 0
+	for i := 0; i < l; i += 8 {
+		var xw, yw uint64
+		if i + 8 <= l {
+			xw = binary.BigEndian.Uint64(x[i:])
+			yw = binary.BigEndian.Uint64(y[i:])
+		} else {
+			var xt, yt [8]byte
+			copy(xt[:], x[i:])
+			copy(yt[:], y[i:])
+			xw = binary.BigEndian.Uint64(xt[:])
+			yw = binary.BigEndian.Uint64(yt[:])
+		}
+		v := laneGather(laneCmp(xw, yw, lo, hi, cmp), n)
+		if r := nl - i * 8 / n; r < m {	// partial final word
+			v >>= m - r
+			m = r
+		}
+		zb, zo = bePut(zb, zo, m, v)
+	}
+	return z
+}
+
+// MinLanes sets z to the lane-wise minimum of slices x and y,
+// treated as vectors of unsigned n-bit lanes, and returns z.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// or if the slices do not hold a whole number of lanes.
+// Allocates and returns a new destination slice if z is not long enough.
+func MinLanes(z, x, y []byte, n int) []byte {
+	_, h := laneMasks(n)
+	laneLen(x, y, n)
+	return laneOp(z, x, y, func(x, y uint64) uint64 {
+		lt := laneSpread(laneBorrow(x, y, h), n)
+		return (x & lt) | (y &^ lt)
+	})
+}
+
+// MaxLanes sets z to the lane-wise maximum of slices x and y,
+// treated as vectors of unsigned n-bit lanes, and returns z.
+// The source slices x and y must be of the same length;
+// otherwise panics with ErrLengthMismatch.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// or if the slices do not hold a whole number of lanes.
+// Allocates and returns a new destination slice if z is not long enough.
+func MaxLanes(z, x, y []byte, n int) []byte {
+	_, h := laneMasks(n)
+	laneLen(x, y, n)
+	return laneOp(z, x, y, func(x, y uint64) uint64 {
+		lt := laneSpread(laneBorrow(x, y, h), n)
+		return (y & lt) | (x &^ lt)
+	})
+}
+
+// LanePopcount sets each n-bit lane of z to the number of one bits
+// in the corresponding lane of slice x, and returns z.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// or if x does not hold a whole number of lanes.
+// Allocates and returns a new destination slice if z is not long enough.
+func LanePopcount(z, x []byte, n int) []byte {
+	laneMasks(n)
+	laneLen(x, x, n)
+	return laneOp(z, x, x, func(x, _ uint64) uint64 {
+		for k := 1; k < n; k *= 2 {
+			m := laneRep(k, 2*k)
+			x = (x & m) + ((x >> k) & m)
+		}
+		return x
+	})
+}
+
+// HorizontalSum returns the sum of all the n-bit lanes of slice x,
+// treated as unsigned integers, modulo 2^64.
+// Panics with ErrOutOfRange if n is not a power of two from 1 to 64,
+// or if x does not hold a whole number of lanes.
+func HorizontalSum(x []byte, n int) (sum uint64) {
+	laneMasks(n)
+	l := laneLen(x, x, n)
+	for i := 0; i < l; i += 8 {
+		var v uint64
+		if i + 8 <= l {
+			v = binary.BigEndian.Uint64(x[i:])
+		} else {
+			var t [8]byte
+			copy(t[:], x[i:])
+			v = binary.BigEndian.Uint64(t[:])
+		}
+		for k := n; k < 64; k *= 2 {	// sum adjacent pairs of lanes
+			m := laneRep(k, 2*k)
+			v = (v & m) + ((v >> k) & m)
+		}
+		sum += v
+	}
+	return sum
+}
@@ -0,0 +1,175 @@
+package bytebits
+
+import (
+	"bytes"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+
+var laneWidths = []int{1, 2, 4, 8, 16, 32, 64}
+
+// laneMax returns the maximum value of an n-bit lane.
+func laneMax(n int) uint64 {
+	return ^uint64(0) >> (64 - n)
+}
+
+// testLanes checks a packed-lane operation on random vectors
+// against a reference function applied to each lane individually.
+func testLanes(t *testing.T, name string,
+		op func(z, x, y []byte, n int) []byte,
+		ref func(x, y uint64, n int) uint64) {
+
+	for _, n := range laneWidths {
+		for _, l := range []int{8, 24, 104} {
+			x := make([]byte, l)
+			y := make([]byte, l)
+			rand.Read(x)
+			rand.Read(y)
+			x[0], y[0] = 0xff, 0xff	// exercise extreme lanes
+			x[l-1], y[l-1] = 0x00, 0xff
+			for _, tl := range []int{l, l - 4, l - 7} {	// tails
+				if (tl * 8) % n != 0 {
+					continue
+				}
+				z := op(nil, x[:tl], y[:tl], n)
+				want := make([]byte, tl)
+				for o := 0; o < tl * 8; o += n {
+					v := ref(BigEndian.Uint(x, o, n),
+						BigEndian.Uint(y, o, n), n)
+					BigEndian.PutUint(want, o, n, v)
+				}
+				if !bytes.Equal(z, want) {
+					t.Errorf("%s n=%v len=%v: got %x, want %x",
+						name, n, tl, z, want)
+				}
+			}
+		}
+	}
+}
+
+func TestAddSubLanes(t *testing.T) {
+	testLanes(t, "AddLanes/Wrap",
+		func(z, x, y []byte, n int) []byte {
+			return AddLanes(z, x, y, n, Wrap)
+		},
+		func(x, y uint64, n int) uint64 {
+			return (x + y) & laneMax(n)
+		})
+	testLanes(t, "AddLanes/Saturate",
+		func(z, x, y []byte, n int) []byte {
+			return AddLanes(z, x, y, n, Saturate)
+		},
+		func(x, y uint64, n int) uint64 {
+			if x > laneMax(n) - y {
+				return laneMax(n)
+			}
+			return x + y
+		})
+	testLanes(t, "SubLanes/Wrap",
+		func(z, x, y []byte, n int) []byte {
+			return SubLanes(z, x, y, n, Wrap)
+		},
+		func(x, y uint64, n int) uint64 {
+			return (x - y) & laneMax(n)
+		})
+	testLanes(t, "SubLanes/Saturate",
+		func(z, x, y []byte, n int) []byte {
+			return SubLanes(z, x, y, n, Saturate)
+		},
+		func(x, y uint64, n int) uint64 {
+			if x < y {
+				return 0
+			}
+			return x - y
+		})
+}
+
+func TestMinMaxLanes(t *testing.T) {
+	testLanes(t, "MinLanes", MinLanes,
+		func(x, y uint64, n int) uint64 {
+			if x < y {
+				return x
+			}
+			return y
+		})
+	testLanes(t, "MaxLanes", MaxLanes,
+		func(x, y uint64, n int) uint64 {
+			if x > y {
+				return x
+			}
+			return y
+		})
+}
+
+func TestCmpLanes(t *testing.T) {
+	cmps := []func(x, y uint64) bool{
+		LaneEq: func(x, y uint64) bool { return x == y },
+		LaneNe: func(x, y uint64) bool { return x != y },
+		LaneLt: func(x, y uint64) bool { return x < y },
+		LaneLe: func(x, y uint64) bool { return x <= y },
+		LaneGt: func(x, y uint64) bool { return x > y },
+		LaneGe: func(x, y uint64) bool { return x >= y },
+	}
+	for _, n := range laneWidths {
+		for _, l := range []int{8, 20, 37} {
+			if (l * 8) % n != 0 {
+				continue
+			}
+			x := make([]byte, l)
+			y := make([]byte, l)
+			rand.Read(x)
+			copy(y, x)
+			for i := 0; i < l; i += 3 {	// keep some lanes equal
+				y[i] = byte(rand.Intn(256))
+			}
+			for cmp, ref := range cmps {
+				z := CmpLanes(nil, x, y, n, LaneCmp(cmp))
+				for i := 0; i < l * 8 / n; i++ {
+					want := uint(0)
+					if ref(BigEndian.Uint(x, i*n, n),
+							BigEndian.Uint(y, i*n, n)) {
+						want = 1
+					}
+					if got := BigEndian.Bit(z, i); got != want {
+						t.Errorf("CmpLanes n=%v cmp=%v "+
+							"lane %v: got %v, want %v",
+							n, cmp, i, got, want)
+					}
+				}
+				if pad := len(z)*8 - l*8/n; BigEndian.Trailing(z, 0) < pad {
+					t.Errorf("CmpLanes n=%v: nonzero padding", n)
+				}
+			}
+		}
+	}
+}
+
+func TestLanePopcountSum(t *testing.T) {
+	testLanes(t, "LanePopcount",
+		func(z, x, _ []byte, n int) []byte {
+			return LanePopcount(z, x, n)
+		},
+		func(x, _ uint64, n int) uint64 {
+			return uint64(bits.OnesCount64(x))
+		})
+
+	for _, n := range laneWidths {
+		for _, l := range []int{0, 8, 20, 96} {
+			if (l * 8) % n != 0 {
+				continue
+			}
+			x := make([]byte, l)
+			rand.Read(x)
+			var want uint64
+			for o := 0; o < l * 8; o += n {
+				want += BigEndian.Uint(x, o, n)
+			}
+			if got := HorizontalSum(x, n); got != want {
+				t.Errorf("HorizontalSum n=%v len=%v: got %v, want %v",
+					n, l, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,905 @@
+package bytebits
+
+import (
+	"math/bits"
+	"encoding/binary"
+)
+
+
+// LittleEndianOrder provides bitwise operations that treat the bits in each
+// byte as having little-endian bit ordering.
+//
+// You normally invoke its methods via the standard LittleEndian instance.
+// Bit offset 0 refers to the least-significant bit of the first byte,
+// and multi-bit values are read and written least-significant bit first,
+// as in DEFLATE, Bluetooth, and many sensor formats.
+// Bit fields thus have the same significance as the bits
+// of an integer loaded from the slice in little-endian byte order,
+// so that byte-aligned Uint16 through Uint64 values
+// match the standard binary.LittleEndian encoding.
+//
+// Shifts and rotations are defined in terms of bit offsets,
+// as for BigEndianOrder: ShiftLeft and RotateLeft move bits
+// toward bit 0 of byte 0, and ShiftRight toward higher offsets.
+// Since bit 0 is least significant in this order,
+// a left shift of the slice is a right shift (>>)
+// of the integer loaded from it in little-endian byte order.
+// Leading and Trailing treat the whole slice as one little-endian integer,
+// so that Leading counts from the most-significant bit of the last byte.
+//
+// LittleEndianOrder implements the BitOrder interface.
+//
+type LittleEndianOrder struct{}
+
+// LittleEndian instantiates the BitOrder interface for little-endian bit order.
+var LittleEndian = LittleEndianOrder{}
+
+var _ BitOrder = LittleEndian
+
+
+// Normalize a byte-slice and arbitrary bit offset so the offset is 0-7.
+func leNorm(b []byte, o int) ([]byte, int) {
+	return b[o >> 3:], o & 7
+}
+
+// Normalize and grow a slice if needed to hold an entire bit field.
+// Returns the full byte slice after growing it if needed,
+// and the normalized slice and offset of the field within the full size.
+func leGrow(b []byte, o, w int) ([]byte, []byte, int) {
+	b = Grow(b, (o + w + 7) >> 3)
+	xb, xo := leNorm(b, o)
+	return b, xb, xo
+}
+
+// Get the next 64 bits from slice b at bit offset o (0-7).
+// Returns the byte slice and bit offset just past the returned bits.
+// The byte-aligned case is kept inlinable as in beGet64.
+func leGet64(b []byte, o int) ([]byte, int, uint64) {
+	if o != 0 || smallFootprint {
+		return leGet64u(b, o)
+	}
+	return b[8:], 0, binary.LittleEndian.Uint64(b)	// touches only 8 bytes
+}
+
+// Get the next 64 bits from slice b at a bit offset o that may be unaligned.
+func leGet64u(b []byte, o int) ([]byte, int, uint64) {
+
+	if smallFootprint {	// use the compact general-purpose loop
+		return leGetN(b, o, 64)
+	}
+
+	// not byte-aligned: touches 9 bytes total
+	v := binary.LittleEndian.Uint64(b)
+	b = b[8:]
+	return b, o, (v >> o) | uint64(b[0]) << (64-o)
+}
+
+// Get n or a maximum of 64 bits from slice b at bit offset o (0-7).
+// Returns the byte slice and bit offset just past the returned bits,
+// and the read data in the least-significant bits of a uint64,
+// with the first bit read in the least-significant position.
+func leGet(b []byte, o, n int) ([]byte, int, uint64) {
+
+	if n >= 64 {			// get a full uint64
+		return leGet64(b, o)
+	}
+	return leGetN(b, o, n)
+}
+
+// Get n bits, where n is at most 64, from slice b at bit offset o (0-7),
+// a few bits at a time.
+func leGetN(b []byte, o, n int) ([]byte, int, uint64) {
+	v := uint64(0)
+	s := 0				// number of bits gotten so far
+	for n > 0 {
+		r := 8-o		// remaining bits in the current byte
+		if n >= r {		// get the rest of the current byte
+			v |= uint64(b[0] >> o) << s
+			b = b[1:]
+			o = 0
+			s += r
+			n -= r
+		} else {		// get only part of the current byte
+			v |= uint64((b[0] >> o) & (1 << n - 1)) << s
+			o += n
+			n = 0
+		}
+	}
+	return b, o, v
+}
+
+// Put 64 bits into slice b at offset o, which must be in the range 0-7.
+// The common byte-aligned case is a single 8-byte store,
+// with the unaligned case kept out of line in lePut64u
+// so that this leaf stays within the compiler's inlining budget.
+func lePut64(b []byte, o int, v uint64) ([]byte, int) {
+	if o != 0 || smallFootprint {
+		return lePut64u(b, o, v), o
+	}
+	binary.LittleEndian.PutUint64(b, v)	// touches only 8 bytes
+	return b[8:], 0
+}
+
+// Put 64 bits into slice b at a bit offset o that may be unaligned.
+// Returns the byte slice just past the put bits; the bit offset is unchanged.
+//go:noinline
+func lePut64u(b []byte, o int, v uint64) []byte {
+
+	if smallFootprint {	// use the compact general-purpose loop
+		b, _ = lePutN(b, o, 64, v)
+		return b
+	}
+
+	// not byte-aligned: touches 9 bytes total
+	m := byte(1) << o - 1	// bits preceding the put bits
+	t := b[8] &^ m		// save the bits following the put bits
+	b[0] = (b[0] & m) | byte(v << o)
+	binary.LittleEndian.PutUint64(b[1:], v >> (8-o))
+	b[8] |= t
+	return b[8:]
+}
+
+// Put n or a maximum of 64 bits into slice b at bit offset o (0-7).
+// The bits to put are passed in the least-significant bits of v,
+// with the first bit to put in the least-significant position.
+// Returns the byte slice and bit offset just past the put bits.
+func lePut(b []byte, o, n int, v uint64) ([]byte, int) {
+
+	if n >= 64 {			// put a full uint64
+		return lePut64(b, o, v)
+	}
+	return lePutN(b, o, n, v)
+}
+
+// Put n bits, where n is at most 64, into slice b at bit offset o (0-7),
+// a few bits at a time.
+func lePutN(b []byte, o, n int, v uint64) ([]byte, int) {
+	for n > 0 {
+		r := 8-o		// remaining bits in the current byte
+		if n >= r {		// put the rest of the current byte
+			m := byte(0xff) << o
+			b[0] = (b[0] &^ m) | (byte(v << o) & m)
+			b = b[1:]
+			o = 0
+			v >>= r
+			n -= r
+		} else {		// put only part of the current byte
+			m := byte(1 << n - 1) << o
+			b[0] = (b[0] &^ m) | (byte(v << o) & m)
+			o += n
+			n = 0
+		}
+	}
+	return b, o
+}
+
+
+// leCopy copies w bits from slice xb at bit offset xo (0-7)
+// into slice zb at bit offset zo (0-7),
+// and returns the slices and offsets just past the copied bits.
+func leCopy(zb, xb []byte, zo, xo, w int) ([]byte, []byte, int, int) {
+	var v uint64
+	if zo == xo && w >= 64 {
+		// Same alignment: complete the current byte,
+		// then copy the whole bytes with the builtin copy
+		if h := 8 - zo; zo != 0 {
+			xb, xo, v = leGet(xb, xo, h)
+			zb, zo = lePut(zb, zo, h, v)
+			w -= h
+		}
+		n := w >> 3
+		copy(zb[:n], xb[:n])
+		zb, xb = zb[n:], xb[n:]
+		w &= 7
+	}
+	for w >= 64 {
+		xb, xo, v = leGet64(xb, xo)
+		zb, zo = lePut64(zb, zo, v)
+		w -= 64
+	}
+	xb, xo, v = leGet(xb, xo, w)
+	zb, zo = lePut(zb, zo, w, v)
+	return zb, xb, zo, xo
+}
+
+// leMove moves w bits in b from offset src to offset dst,
+// 64 bits at a time, correctly handling overlapping ranges
+// by copying from the end when moving toward higher offsets.
+func leMove(b []byte, dst, src, w int) {
+	var v uint64
+	for w > 0 {
+		n := w
+		if n > 64 {
+			n = 64
+		}
+		s, d := src, dst
+		if dst > src {		// move the last n bits first
+			s, d = src + w - n, dst + w - n
+		} else {
+			src, dst = src + n, dst + n
+		}
+		sb, so := leNorm(b, s)
+		_, _, v = leGet(sb, so, n)
+		db, do := leNorm(b, d)
+		lePut(db, do, n, v)
+		w -= n
+	}
+}
+
+// leFill sets w bits starting at offset o in b to the bits of v,
+// which must be all zeros or all ones.
+func leFill(b []byte, o, w int, v uint64) ([]byte, int) {
+	for w >= 64 {
+		b, o = lePut64(b, o, v)
+		w -= 64
+	}
+	return lePut(b, o, w, v)
+}
+
+
+// Copy copies a bit-field of width bits starting at offset xofs in x
+// into a field of the same width starting at offset zofs in z,
+// then returns z.
+// Copies z and returns a new slice if z is null or not large enough.
+// All other bits within z are left unmodified.
+//
+func (le LittleEndianOrder) Copy(z []byte, x []byte, zofs, xofs, w int) []byte {
+	xb, xo := leNorm(x, xofs)
+	z, zb, zo := leGrow(z, zofs, w)
+	leCopy(zb, xb, zo, xo, w)
+	return z
+}
+
+// Get up to w bits from slice xb at bit offset xo.
+func (_ LittleEndianOrder) get(xb []byte, xo, w int) (v uint64) {
+	xb, xo = leNorm(xb, xo)
+	xb, xo, v = leGet(xb, xo, w)
+	return v
+}
+
+// Bit returns the value of the bit at position xofs in x,
+// counting from the least-significant bit of the first byte.
+func (le LittleEndianOrder) Bit(x []byte, xofs int) uint {
+	return uint(x[xofs >> 3] >> (xofs & 7)) & 1
+}
+
+// Uint8 extracts a uint8 starting at bit position xofs in x.
+func (le LittleEndianOrder) Uint8(x []byte, xofs int) uint8 {
+	return uint8(le.get(x, xofs, 8))
+}
+
+// Uint16 extracts a uint16 starting at bit position xofs in x.
+func (le LittleEndianOrder) Uint16(x []byte, xofs int) uint16 {
+	return uint16(le.get(x, xofs, 16))
+}
+
+// Uint32 extracts a uint32 starting at bit position xofs in x.
+func (le LittleEndianOrder) Uint32(x []byte, xofs int) uint32 {
+	return uint32(le.get(x, xofs, 32))
+}
+
+// Uint64 extracts a uint64 starting at bit position xofs in x.
+func (le LittleEndianOrder) Uint64(x []byte, xofs int) uint64 {
+	if xofs & 7 != 0 || smallFootprint {
+		return le.get(x, xofs, 64)
+	}
+	return binary.LittleEndian.Uint64(x[xofs >> 3:])	// byte-aligned fast path
+}
+
+// Uint extracts an unsigned integer w bits wide, where w is at most 64,
+// starting at bit position xofs in x.
+// Panics with ErrOutOfRange if w exceeds 64.
+func (le LittleEndianOrder) Uint(x []byte, xofs, w int) uint64 {
+	if w > 64 {
+		panic(ErrOutOfRange)
+	}
+	return le.get(x, xofs, w)
+}
+
+// Uint128 extracts a 128-bit unsigned integer
+// starting at bit position xofs in x,
+// returning its most- and least-significant 64 bits.
+func (le LittleEndianOrder) Uint128(x []byte, xofs int) (hi, lo uint64) {
+	xb, xo := leNorm(x, xofs)
+	xb, xo, lo = leGet64(xb, xo)
+	_, _, hi = leGet64(xb, xo)
+	return hi, lo
+}
+
+// Words extracts an unsigned integer w bits wide, of any width,
+// starting at bit position xofs in x,
+// into the slice dst as (w+63)/64 words, most-significant word first.
+// The value is right-aligned, so the last word holds the first 64 bits
+// of the field, and the first word holds its final w mod 64 bits,
+// or 64 bits if w is a multiple of 64.
+// Copies dst and returns a new slice if dst is nil or not large enough.
+func (le LittleEndianOrder) Words(x []byte, xofs, w int, dst []uint64) []uint64 {
+	n := (w + 63) >> 6
+	if len(dst) < n {
+		dst = make([]uint64, n)
+	}
+	if n == 0 {
+		return dst
+	}
+	xb, xo := leNorm(x, xofs)
+	for i := n-1; i > 0; i-- {
+		xb, xo, dst[i] = leGet64(xb, xo)
+	}
+	xb, xo, dst[0] = leGet(xb, xo, w - (n-1) * 64)
+	return dst
+}
+
+// Bits extracts a bit-field of width w bits starting at offset xofs in x
+// into a destination slice just large enough to hold it,
+// left- or right-aligned according to align,
+// with all bits in the destination outside the field cleared to zero.
+// Returns z[:(w+7)/8], or a new slice if z is not large enough.
+// The slices x and z must not overlap.
+//
+func (le LittleEndianOrder) Bits(z, x []byte, xofs, w int, align Align) []byte {
+	n := (w + 7) >> 3
+	z = Grow(z, n)[:n]
+	for i := range z {
+		z[i] = 0
+	}
+	return le.Copy(z, x, alignOfs(n, w, align), xofs, w)
+}
+
+
+// SetBits sets all bits in a bit-field of width w bits
+// starting at offset zofs in z to the same bit value b (0 or 1),
+// and returns z.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+//
+func (_ LittleEndianOrder) SetBits(z []byte, zofs, w int, b uint) []byte {
+	if b > 1 {
+		panic(ErrBadBitValue)
+	}
+	z, zb, zo := leGrow(z, zofs, w)
+	leFill(zb, zo, w, -uint64(b))	// all zero bits or all one bits
+	return z
+}
+
+// Put up to w bits into slice zb at bit offset zofs.
+func (_ LittleEndianOrder) put(z []byte, zofs, w int, v uint64) []byte {
+	z, zb, zo := leGrow(z, zofs, w)
+	zb, zo = lePut(zb, zo, w, v)
+	return z
+}
+
+// PutBit sets the bit at zofs in slice z to bit value v.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (le LittleEndianOrder) PutBit(z []byte, zofs int, v uint) []byte {
+	return le.put(z, zofs, 1, uint64(v))
+}
+
+// PutUint8 sets the uint8 starting at zofs in slice z to value v.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (le LittleEndianOrder) PutUint8(z []byte, zofs int, v uint8) []byte {
+	return le.put(z, zofs, 8, uint64(v))
+}
+
+// PutUint16 sets the uint16 starting at zofs in slice z to value v.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (le LittleEndianOrder) PutUint16(z []byte, zofs int, v uint16) []byte {
+	return le.put(z, zofs, 16, uint64(v))
+}
+
+// PutUint32 sets the uint32 starting at zofs in slice z to value v.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (le LittleEndianOrder) PutUint32(z []byte, zofs int, v uint32) []byte {
+	return le.put(z, zofs, 32, uint64(v))
+}
+
+// PutUint64 sets the uint64 starting at zofs in slice z to value v.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (le LittleEndianOrder) PutUint64(z []byte, zofs int, v uint64) []byte {
+	if zofs & 7 != 0 || zofs >> 3 > len(z) - 8 || smallFootprint {
+		return le.put(z, zofs, 64, v)
+	}
+	binary.LittleEndian.PutUint64(z[zofs >> 3:], v)	// byte-aligned fast path
+	return z
+}
+
+// PutUint sets the unsigned integer w bits wide starting at zofs in slice z
+// to the least-significant w bits of v, where w is at most 64.
+// Copies z and returns a new slice if z is null or not large enough.
+// Panics with ErrOutOfRange if w exceeds 64.
+//
+func (le LittleEndianOrder) PutUint(z []byte, zofs, w int, v uint64) []byte {
+	if w > 64 {
+		panic(ErrOutOfRange)
+	}
+	return le.put(z, zofs, w, v)
+}
+
+// PutUint128 sets the 128-bit unsigned integer starting at zofs in slice z
+// to the value with most- and least-significant 64 bits hi and lo.
+// Copies z and returns a new slice if z is null or not large enough.
+//
+func (le LittleEndianOrder) PutUint128(z []byte, zofs int, hi, lo uint64) []byte {
+	z, zb, zo := leGrow(z, zofs, 128)
+	zb, zo = lePut64(zb, zo, lo)
+	lePut64(zb, zo, hi)
+	return z
+}
+
+// PutWords sets the unsigned integer w bits wide, of any width,
+// starting at zofs in slice z to the value held in words v,
+// most-significant word first, in the layout that Words produces.
+// Bits of the first word beyond its least-significant w mod 64 bits
+// are ignored.
+// Copies z and returns a new slice if z is null or not large enough.
+// Panics with ErrLengthMismatch if v does not have exactly (w+63)/64 words.
+//
+func (le LittleEndianOrder) PutWords(z []byte, zofs, w int, v []uint64) []byte {
+	n := (w + 63) >> 6
+	if len(v) != n {
+		panic(ErrLengthMismatch)
+	}
+	if n == 0 {
+		return z
+	}
+	z, zb, zo := leGrow(z, zofs, w)
+	for i := n-1; i > 0; i-- {
+		zb, zo = lePut64(zb, zo, v[i])
+	}
+	zb, zo = lePut(zb, zo, w - (n-1) * 64, v[0])
+	return z
+}
+
+// PutBytes writes the contents of byte b slice into slice z at bit offset zofs.
+// Copies z and returns a new slice if z is nil or not large enough.
+//
+func (le LittleEndianOrder) PutBytes(z []byte, zofs int, b []byte) []byte {
+	z, zb, zo := leGrow(z, zofs, len(b) * 8)
+	for len(b) >= 8 {	// put 8 bytes at a time
+		v := binary.LittleEndian.Uint64(b)
+		zb, zo = lePut64(zb, zo, v)
+		b = b[8:]
+	}
+	for len(b) > 0 {	// put last few bytes one at a time
+		zb, zo = lePut(zb, zo, 8, uint64(b[0]))
+		b = b[1:]
+	}
+	return z
+}
+
+// PutBits inserts a bit-field of width w bits, left- or right-aligned
+// in slice x according to align, into slice z at bit offset zofs.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+// Panics with ErrShortBuffer if x is too short to hold w bits.
+//
+func (le LittleEndianOrder) PutBits(z []byte, zofs int, x []byte, w int,
+		align Align) []byte {
+	return le.Copy(z, x, zofs, alignOfs(len(x), w, align), w)
+}
+
+
+// AppendBit appends bit value v to the bit string of n bits in b,
+// and returns the grown slice and its new length n+1 in bits.
+// The slice b holds the bit string in its first (n+7)/8 bytes,
+// as a previous Append method returns it;
+// any bytes beyond these are discarded.
+// Bits following the end of the bit string in its last byte
+// are kept zero, so the slice may be used directly as padded output.
+// Grows the slice as needed, as Grow does.
+//
+func (le LittleEndianOrder) AppendBit(b []byte, n int, v uint) ([]byte, int) {
+	return le.AppendUint(b, n, 1, uint64(v))
+}
+
+// AppendUint8 appends the uint8 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (le LittleEndianOrder) AppendUint8(b []byte, n int, v uint8) ([]byte, int) {
+	return le.AppendUint(b, n, 8, uint64(v))
+}
+
+// AppendUint16 appends the uint16 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (le LittleEndianOrder) AppendUint16(b []byte, n int, v uint16) ([]byte, int) {
+	return le.AppendUint(b, n, 16, uint64(v))
+}
+
+// AppendUint32 appends the uint32 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (le LittleEndianOrder) AppendUint32(b []byte, n int, v uint32) ([]byte, int) {
+	return le.AppendUint(b, n, 32, uint64(v))
+}
+
+// AppendUint64 appends the uint64 v to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+//
+func (le LittleEndianOrder) AppendUint64(b []byte, n int, v uint64) ([]byte, int) {
+	return le.AppendUint(b, n, 64, v)
+}
+
+// AppendUint appends the least-significant w bits of v,
+// where w is at most 64, to the bit string of n bits in b,
+// as AppendBit does, and returns the grown slice and its length in bits.
+// Panics with ErrOutOfRange if w exceeds 64.
+//
+func (le LittleEndianOrder) AppendUint(b []byte, n, w int, v uint64) ([]byte, int) {
+	if w > 64 {
+		panic(ErrOutOfRange)
+	}
+	b = appendGrow(b, n, w)
+	zb, zo := leNorm(b, n)
+	lePut(zb, zo, w, v)
+	return b, n + w
+}
+
+// AppendBits appends the bit-field of width w bits starting at offset xofs
+// in x to the bit string of n bits in b, as AppendBit does,
+// and returns the grown slice and its length in bits.
+// The slices x and b must not overlap.
+//
+func (le LittleEndianOrder) AppendBits(b []byte, n int, x []byte, xofs, w int) ([]byte, int) {
+	b = appendGrow(b, n, w)
+	zb, zo := leNorm(b, n)
+	xb, xo := leNorm(x, xofs)
+	leCopy(zb, xb, zo, xo, w)
+	return b, n + w
+}
+
+
+// RotateLeft sets slice z to the contents of x rotated left by rot bits,
+// toward bit 0 of byte 0 and less-significant positions,
+// with the bits rotated out of byte 0 reentering at the end of the slice.
+// To rotate right, toward higher offsets, pass a negative value for rot.
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z must not overlap, except if -8 <= rot <= 8,
+// in which case x and z may be identical for small in-place bit rotations.
+func (le LittleEndianOrder) RotateLeft(z, x []byte, rot int) []byte {
+
+	// Ensure destination z is large enough.
+	z = Grow(z, len(x))
+
+	if rot == 0 || len(x) == 0 {	// Special case: no rotation
+		copy(z, x)
+		return z
+	} else if rot > 0 && rot <= 8 {	// Special case: small left rotation
+		l := len(x)
+		r := 8-rot
+		c := x[0] << r
+		for i := l-1; i >= 0; i-- {
+			v := x[i]
+			z[i] = (v >> rot) | c
+			c = v << r
+		}
+		return z
+	} else if rot < 0 && rot >= -8 { // Special case: small right rotation
+		rot = -rot
+		l := len(x)
+		r := 8-rot
+		c := x[l-1] >> r
+		for i := range x {
+			v := x[i]
+			z[i] = (v << rot) | c
+			c = v >> r
+		}
+		return z
+	}
+
+	// Determine the starting bit position to copy from source field x
+	w := len(x) * 8
+	p := rot % w
+	if p < 0 {
+		p += w
+	}
+
+	// Copy bits until the end of the source field
+	xb, xo := leNorm(x, p)
+	zb, xb, zo, xo := leCopy(z, xb, 0, xo, w - p)
+
+	// Then copy the rest of the bits from the beginning of the source
+	zb, xb, zo, xo = leCopy(zb, x, zo, 0, p)
+	return z
+}
+
+
+// ShiftLeft sets slice z to the contents of x shifted left by s bits,
+// toward bit 0 of byte 0 and less-significant positions,
+// filling the vacated bits at the end of the slice with zeros,
+// and returns z[:len(x)].
+// To shift right, pass a negative value for s.
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place shifts,
+// but must not otherwise overlap.
+//
+func (le LittleEndianOrder) ShiftLeft(z, x []byte, s int) []byte {
+	if s < 0 {
+		return le.ShiftRight(z, x, -s)
+	}
+	l := len(x)
+	z = Grow(z, l)[:l]
+	q, r := s >> 3, uint(s & 7)
+	if q >= l {
+		q = l
+	}
+
+	// Shift whole bytes, then the remaining bits within bytes
+	copy(z, x[q:])
+	for i := l-q; i < l; i++ {
+		z[i] = 0
+	}
+	if r != 0 && l > 0 {
+		for i := 0; i < l-1; i++ {
+			z[i] = z[i] >> r | z[i+1] << (8-r)
+		}
+		z[l-1] >>= r
+	}
+	return z
+}
+
+// ShiftRight sets slice z to the contents of x shifted right by s bits,
+// toward higher offsets and more-significant positions,
+// filling the vacated bits at the start of the slice with zeros,
+// and returns z[:len(x)].
+// To shift left, pass a negative value for s.
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place shifts,
+// but must not otherwise overlap.
+//
+func (le LittleEndianOrder) ShiftRight(z, x []byte, s int) []byte {
+	if s < 0 {
+		return le.ShiftLeft(z, x, -s)
+	}
+	l := len(x)
+	z = Grow(z, l)[:l]
+	q, r := s >> 3, uint(s & 7)
+	if q >= l {
+		q = l
+	}
+
+	// Shift whole bytes, then the remaining bits within bytes
+	copy(z[q:], x[:l-q])
+	for i := 0; i < q; i++ {
+		z[i] = 0
+	}
+	if r != 0 && l > 0 {
+		for i := l-1; i > 0; i-- {
+			z[i] = z[i] << r | z[i-1] >> (8-r)
+		}
+		z[0] <<= r
+	}
+	return z
+}
+
+
+// BinaryToGray sets slice z to the reflected binary Gray code of x,
+// treating the whole slice as one unsigned integer with the last bit most significant,
+// and returns z[:len(x)].
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place conversion,
+// but must not otherwise overlap.
+func (le LittleEndianOrder) BinaryToGray(z, x []byte) []byte {
+	l := len(x)
+	z = Grow(z, l)[:l]
+	le.Field(z, 0, l * 8).BinaryToGray(le.Field(x, 0, l * 8))
+	return z
+}
+
+// GrayToBinary sets slice z to the binary value of the Gray code in x,
+// inverting BinaryToGray, and returns z[:len(x)].
+// Copies z and returns a new slice if z is nil or not large enough.
+// The slices x and z may be identical for in-place conversion,
+// but must not otherwise overlap.
+func (le LittleEndianOrder) GrayToBinary(z, x []byte) []byte {
+	l := len(x)
+	z = Grow(z, l)[:l]
+	le.Field(z, 0, l * 8).GrayToBinary(le.Field(x, 0, l * 8))
+	return z
+}
+
+
+// SwapRanges exchanges the contents of the two non-overlapping ranges
+// of w bits starting at offsets ofs1 and ofs2 in z, in place,
+// 64 bits at a time.
+// All other bits within z are left unmodified.
+// Panics with ErrOutOfRange if the ranges overlap,
+// or with ErrShortBuffer if either range extends past the end of z.
+func (le LittleEndianOrder) SwapRanges(z []byte, ofs1, ofs2, w int) {
+	if ofs1 < 0 || ofs2 < 0 || w < 0 ||
+			(w > 0 && ofs1 < ofs2 + w && ofs2 < ofs1 + w && ofs1 != ofs2) {
+		panic(ErrOutOfRange)
+	}
+	if ofs1 + w > len(z) * 8 || ofs2 + w > len(z) * 8 {
+		panic(ErrShortBuffer)
+	}
+	if ofs1 == ofs2 {
+		return
+	}
+
+	ab, ao := leNorm(z, ofs1)
+	bb, bo := leNorm(z, ofs2)
+	var av, bv uint64
+	for w >= 64 {
+		_, _, av = leGet64(ab, ao)
+		_, _, bv = leGet64(bb, bo)
+		ab, ao = lePut64(ab, ao, bv)
+		bb, bo = lePut64(bb, bo, av)
+		w -= 64
+	}
+	_, _, av = leGet(ab, ao, w)
+	_, _, bv = leGet(bb, bo, w)
+	lePut(ab, ao, w, bv)
+	lePut(bb, bo, w, av)
+}
+
+// Leading counts the number of consecutive leading bits with value b
+// in slice z starting from the most-significant bit of the last byte.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func (le LittleEndianOrder) Leading(z []byte, b uint) (n int) {
+	switch b {
+	case 0:
+		for i := len(z)-1; i >= 0; i-- {
+			v := z[i]
+			if v != 0 {
+				return n + bits.LeadingZeros8(v)
+			}
+			n += 8
+		}
+	case 1:
+		for i := len(z)-1; i >= 0; i-- {
+			v := z[i]
+			if v != 0xff {
+				return n + bits.LeadingZeros8(^v)
+			}
+			n += 8
+		}
+	default:
+		panic(ErrBadBitValue)
+	}
+	return n
+}
+
+// Trailing counts the number of consecutive trailing bits with value b
+// in slice z starting from the least-significant bit of the first byte.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func (le LittleEndianOrder) Trailing(z []byte, b uint) (n int) {
+	switch b {
+	case 0:
+		for _, v := range(z) {
+			if v != 0 {
+				return n + bits.TrailingZeros8(v)
+			}
+			n += 8
+		}
+	case 1:
+		for _, v := range(z) {
+			if v != 0xff {
+				return n + bits.TrailingZeros8(^v)
+			}
+			n += 8
+		}
+	default:
+		panic(ErrBadBitValue)
+	}
+	return n
+}
+
+// LeadingAt counts the number of consecutive leading bits with value b
+// in the w-bit range starting at offset ofs in z,
+// scanning toward lower offsets from the most-significant bit at ofs+w-1.
+// Returns w if all bits in the range have value b.
+// Panics with ErrBadBitValue if b is not 0 or 1,
+// or with ErrOutOfRange or ErrShortBuffer if the range is not within z.
+func (le LittleEndianOrder) LeadingAt(z []byte, ofs, w int, b uint) (n int) {
+	checkRange(len(z), ofs, w)
+	m := bitMask(b)
+	var v uint64
+	for w - n >= 64 {
+		zb, zo := leNorm(z, ofs + w - n - 64)
+		_, _, v = leGet64(zb, zo)
+		if v ^= m; v != 0 {
+			return n + bits.LeadingZeros64(v)
+		}
+		n += 64
+	}
+	if r := w - n; r > 0 {
+		zb, zo := leNorm(z, ofs)
+		_, _, v = leGet(zb, zo, r)
+		if v ^= m & (1 << r - 1); v != 0 {
+			return n + bits.LeadingZeros64(v) - (64 - r)
+		}
+	}
+	return w
+}
+
+// TrailingAt counts the number of consecutive trailing bits with value b
+// in the w-bit range starting at offset ofs in z,
+// scanning toward higher offsets from the least-significant bit at ofs.
+// Returns w if all bits in the range have value b.
+// Panics with ErrBadBitValue if b is not 0 or 1,
+// or with ErrOutOfRange or ErrShortBuffer if the range is not within z.
+func (le LittleEndianOrder) TrailingAt(z []byte, ofs, w int, b uint) (n int) {
+	checkRange(len(z), ofs, w)
+	m := bitMask(b)
+	zb, zo := leNorm(z, ofs)
+	var v uint64
+	for w - n >= 64 {
+		zb, zo, v = leGet64(zb, zo)
+		if v ^= m; v != 0 {
+			return n + bits.TrailingZeros64(v)
+		}
+		n += 64
+	}
+	if r := w - n; r > 0 {
+		zb, zo, v = leGet(zb, zo, r)
+		if v ^= m & (1 << r - 1); v != 0 {
+			return n + bits.TrailingZeros64(v)
+		}
+	}
+	return w
+}
+
+// CommonPrefixLen returns the number of initial bits that are equal
+// in the bit strings starting at offset xofs in x and offset yofs in y,
+// comparing at most maxBits bits in order of increasing offset.
+// Both x and y must contain at least maxBits bits past their offsets.
+// The comparison proceeds 64 bits at a time,
+// locating the first difference by XOR and trailing-zero count.
+func (le LittleEndianOrder) CommonPrefixLen(x, y []byte, xofs, yofs, maxBits int) int {
+	xb, xo := leNorm(x, xofs)
+	yb, yo := leNorm(y, yofs)
+	var xv, yv uint64
+	n := 0
+	for maxBits - n >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		yb, yo, yv = leGet64(yb, yo)
+		if d := xv ^ yv; d != 0 {
+			return n + bits.TrailingZeros64(d)
+		}
+		n += 64
+	}
+	r := maxBits - n	// remaining bits to compare, 0-63
+	xb, xo, xv = leGet(xb, xo, r)
+	yb, yo, yv = leGet(yb, yo, r)
+	if d := xv ^ yv; d != 0 {
+		return n + bits.TrailingZeros64(d)
+	}
+	return maxBits
+}
+
+// String returns "LittleEndian", like the standard binary.ByteOrder values.
+func (_ LittleEndianOrder) String() string {
+	return "LittleEndian"
+}
+
+// LSBFirst reports whether bits are numbered from the least-significant end,
+// which for LittleEndianOrder is true: the first bit of each byte is its least significant.
+// Code that consumes values bit by bit in stream order,
+// such as CRC computation, uses it to decide whether to reverse values.
+func (_ LittleEndianOrder) LSBFirst() bool {
+	return true
+}
+
+// ByteOrder returns the standard binary.ByteOrder with the same byte order,
+// for use in byte-aligned encoding code from the standard library.
+func (_ LittleEndianOrder) ByteOrder() binary.ByteOrder {
+	return binary.LittleEndian
+}
+
+// AppendByteOrder returns the standard binary.AppendByteOrder
+// with the same byte order.
+func (_ LittleEndianOrder) AppendByteOrder() binary.AppendByteOrder {
+	return binary.LittleEndian
+}
+
+// Field returns a Field referring to the little-endian bit field
+// starting at bit offset ofs in buf and extending for width bits.
+func (le LittleEndianOrder) Field(buf []byte, ofs, width int) Field {
+	return (&LittleEndianField{}).Init(buf, ofs, width)
+}
@@ -0,0 +1,301 @@
+package bytebits
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+
+// leRef extracts w bits at offset ofs in x in little-endian bit order,
+// one bit at a time.
+func leRef(x []byte, ofs, w int) (v uint64) {
+	for j := 0; j < w; j++ {
+		i := ofs + j
+		v |= uint64(x[i >> 3] >> (i & 7) & 1) << j
+	}
+	return v
+}
+
+func TestLittleEndianUint(t *testing.T) {
+	x := make([]byte, 32)
+	rand.Read(x)
+	for ofs := 0; ofs < 64; ofs += 3 {
+		for _, w := range []int{0, 1, 5, 8, 13, 32, 57, 63, 64} {
+			want := leRef(x, ofs, w)
+			if v := LittleEndian.Uint(x, ofs, w); v != want {
+				t.Errorf("Uint(%v, %v): got %x, want %x",
+					ofs, w, v, want)
+			}
+
+			// Put the value into a buffer full of ones
+			z := bytes.Repeat([]byte{0xff}, 32)
+			z = LittleEndian.PutUint(z, ofs, w, ^uint64(0) &^ want)
+			z = LittleEndian.PutUint(z, ofs, w, want)
+			if leRef(z, ofs, w) != want ||
+					LittleEndian.Leading(z, 1) < 256 - ofs - w ||
+					LittleEndian.Trailing(z, 1) < ofs {
+				t.Errorf("PutUint(%v, %v) wrong or clobbered: %x",
+					ofs, w, z)
+			}
+		}
+		if v := LittleEndian.Uint64(x, ofs); v != leRef(x, ofs, 64) {
+			t.Errorf("Uint64(%v): got %x", ofs, v)
+		}
+	}
+	if v := LittleEndian.Uint32(x, 8); v != binary.LittleEndian.Uint32(x[1:]) {
+		t.Errorf("aligned Uint32 differs from binary.LittleEndian")
+	}
+	if LittleEndian.Bit([]byte{0x00, 0x04}, 10) != 1 {
+		t.Errorf("Bit 10 of 0004 not set")
+	}
+
+	// Wide values
+	for _, w := range []int{0, 65, 128, 200} {
+		v := LittleEndian.Words(x, 5, w, nil)
+		z := LittleEndian.PutWords(nil, 3, w, v)
+		if !bytes.Equal(LittleEndian.Copy(nil, z, 0, 3, w),
+				LittleEndian.Copy(nil, x, 0, 5, w)) {
+			t.Errorf("Words/PutWords w=%v: round trip failed", w)
+		}
+		if w >= 64 && v[len(v)-1] != leRef(x, 5, 64) {
+			t.Errorf("Words w=%v: low word %x wrong", w, v[len(v)-1])
+		}
+	}
+	hi, lo := LittleEndian.Uint128(x, 9)
+	if lo != leRef(x, 9, 64) || hi != leRef(x, 73, 64) {
+		t.Errorf("Uint128: got %x %x", hi, lo)
+	}
+}
+
+func TestLittleEndianPutBytes(t *testing.T) {
+	b := []byte("little-endian bits")
+	z := LittleEndian.PutBytes(nil, 11, b)
+	for i := range b {
+		if LittleEndian.Uint8(z, 11 + 8*i) != b[i] {
+			t.Fatalf("PutBytes: byte %v wrong in %x", i, z)
+		}
+	}
+}
+
+func TestLittleEndianRotate(t *testing.T) {
+	x := make([]byte, 8)
+	rand.Read(x)
+	v := binary.LittleEndian.Uint64(x)
+	for _, rot := range []int{0, 1, 5, 8, -3, -8, 9, 31, -40, 64, 100} {
+		z := LittleEndian.RotateLeft(nil, x, rot)
+		if got, want := binary.LittleEndian.Uint64(z),
+				bits.RotateLeft64(v, -rot); got != want {
+			t.Errorf("RotateLeft(%v): got %x, want %x", rot, got, want)
+		}
+	}
+
+	// A single byte rotates the same way by offset in both bit orders
+	for rot := -8; rot <= 8; rot++ {
+		b := []byte{0x96}
+		r := []byte{bits.Reverse8(b[0])}
+		if LittleEndian.RotateLeft(nil, b, rot)[0] !=
+				bits.Reverse8(BigEndian.RotateLeft(nil, r, rot)[0]) {
+			t.Errorf("RotateLeft(%v) of one byte differs by order", rot)
+		}
+	}
+}
+
+// LittleEndian shifts and rotations are defined by bit offset:
+// left moves bits toward bit 0 of byte 0, as in BigEndian order.
+func TestLittleEndianDirection(t *testing.T) {
+	x := []byte{0x01, 0x00, 0x80}	// bits at offsets 0 and 23
+	if z := LittleEndian.ShiftLeft(nil, x, 1); !bytes.Equal(z,
+			[]byte{0x00, 0x00, 0x40}) {
+		t.Errorf("ShiftLeft by 1: got %x", z)
+	}
+	if z := LittleEndian.ShiftRight(nil, x, 1); !bytes.Equal(z,
+			[]byte{0x02, 0x00, 0x00}) {
+		t.Errorf("ShiftRight by 1: got %x", z)
+	}
+	if z := LittleEndian.RotateLeft(nil, x, 1); !bytes.Equal(z,
+			[]byte{0x00, 0x00, 0xc0}) {
+		t.Errorf("RotateLeft by 1: got %x", z)
+	}
+	if z := LittleEndian.RotateLeft(nil, x, -9); !bytes.Equal(z,
+			[]byte{0x00, 0x03, 0x00}) {
+		t.Errorf("RotateLeft by -9: got %x", z)
+	}
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		sz, rz := make([]byte, 3), make([]byte, 3)
+		order.Field(sz, 0, 24).ShiftLeft(order.Field(x, 0, 24), 1)
+		order.Field(rz, 0, 24).RotateLeft(order.Field(x, 0, 24), 1)
+		for i := 0; i < 24; i++ {
+			want := order.Bit(x, (i + 1) % 24)
+			if order.Bit(rz, i) != want {
+				t.Errorf("%v Field RotateLeft by 1: got %x", order, rz)
+				break
+			}
+			if i == 23 {
+				want = 0
+			}
+			if order.Bit(sz, i) != want {
+				t.Errorf("%v Field ShiftLeft by 1: got %x", order, sz)
+				break
+			}
+		}
+	}
+}
+
+func TestLittleEndianLeadingTrailing(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		var x [8]byte
+		v := uint64(1) << i
+		binary.LittleEndian.PutUint64(x[:], v)
+		if n := LittleEndian.Leading(x[:], 0); n != bits.LeadingZeros64(v) {
+			t.Errorf("Leading(%x, 0): got %v", v, n)
+		}
+		if n := LittleEndian.Trailing(x[:], 0); n != bits.TrailingZeros64(v) {
+			t.Errorf("Trailing(%x, 0): got %v", v, n)
+		}
+		binary.LittleEndian.PutUint64(x[:], ^v)
+		if n := LittleEndian.Leading(x[:], 1); n != bits.LeadingZeros64(v) {
+			t.Errorf("Leading(%x, 1): got %v", ^v, n)
+		}
+		if n := LittleEndian.Trailing(x[:], 1); n != bits.TrailingZeros64(v) {
+			t.Errorf("Trailing(%x, 1): got %v", ^v, n)
+		}
+	}
+}
+
+func TestLittleEndianCopySwap(t *testing.T) {
+	x := make([]byte, 40)
+	rand.Read(x)
+	for _, w := range []int{0, 3, 64, 100, 150} {
+		z := LittleEndian.Copy(nil, x, 7, 2, w)
+		for i := 0; i < w; i++ {
+			if LittleEndian.Bit(z, 7+i) != LittleEndian.Bit(x, 2+i) {
+				t.Fatalf("Copy w=%v: bit %v differs", w, i)
+			}
+		}
+		if n := LittleEndian.CommonPrefixLen(z, x, 7, 2, w); n != w {
+			t.Errorf("CommonPrefixLen of copy w=%v: got %v", w, n)
+		}
+		if w > 0 {
+			z = LittleEndian.PutBit(z, 7 + w/2, 1 ^ LittleEndian.Bit(z, 7 + w/2))
+			if n := LittleEndian.CommonPrefixLen(z, x, 7, 2, w); n != w/2 {
+				t.Errorf("CommonPrefixLen w=%v: got %v, want %v",
+					w, n, w/2)
+			}
+		}
+
+		y := append([]byte(nil), x...)
+		LittleEndian.SwapRanges(y, 1, 160, w)
+		if leRef(y, 1, w % 64) != leRef(x, 160, w % 64) ||
+				leRef(y, 160, w % 64) != leRef(x, 1, w % 64) {
+			t.Errorf("SwapRanges w=%v failed", w)
+		}
+	}
+}
+
+func TestLittleEndianField(t *testing.T) {
+	for _, w := range []int{0, 1, 13, 64, 100, 180} {
+		x := LittleEndian.Field(testBits, 3, w).(*LittleEndianField)
+		y := LittleEndian.Field(testBits[1:], 1, w)
+		zbuf := bytes.Repeat([]byte{0xff}, 26)
+		z := LittleEndian.Field(zbuf, 5, w)
+
+		// Compare field operations with those on whole canonical slices
+		xc, yc := x.Canonical(), y.Canonical()
+		check := func(op string, want []byte) {
+			if got := z.Canonical(); !bytes.Equal(got, want) {
+				t.Errorf("%s of %v-bit fields: got %x, want %x",
+					op, w, got, want)
+			}
+			if zbuf[0] & 0x1f != 0x1f || LittleEndian.Leading(zbuf, 1) <
+					len(zbuf)*8 - 5 - w {
+				t.Errorf("%s of %v-bit fields clobbered", op, w)
+			}
+		}
+		canon := func(b []byte) []byte {
+			if w & 7 != 0 {
+				b[len(b)-1] &= byte(1) << (w & 7) - 1
+			}
+			return b
+		}
+		z.And(x, y)
+		check("And", And(nil, xc, yc))
+		z.Or(x, y)
+		check("Or", Or(nil, xc, yc))
+		z.Xor(x, y)
+		check("Xor", Xor(nil, xc, yc))
+		z.AndNot(x, y)
+		check("AndNot", AndNot(nil, xc, yc))
+		z.Not(x)
+		check("Not", canon(Not(nil, xc)))
+		z.Set(x)
+		check("Set", xc)
+		if n := z.Count(1); n != Count(xc, 1) {
+			t.Errorf("Count of %v-bit field: got %v", w, n)
+		}
+		if n := z.Count(0); n != w - Count(xc, 1) {
+			t.Errorf("Count(0) of %v-bit field: got %v", w, n)
+		}
+		if z.Hash(7) != x.Hash(7) || z.Key() != x.Key() {
+			t.Errorf("Hash or Key of %v-bit field depends on alignment", w)
+		}
+		z.Fill(1)
+		check("Fill", canon(bytes.Repeat([]byte{0xff}, (w+7)/8)))
+
+		if w > 0 && w <= 64 {
+			z.RotateLeft(x, 5)
+			r := uint64(5 % w)
+			v := leRef(xc, 0, w)
+			want := (v >> r | v << (uint64(w) - r)) & (1 << w - 1)
+			if w == 64 {
+				want = bits.RotateLeft64(v, -5)
+			}
+			if got := leRef(z.Canonical(), 0, w); got != want {
+				t.Errorf("RotateLeft of %v-bit field: got %x, want %x",
+					w, got, want)
+			}
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	x := make([]byte, 8)
+	rand.Read(x)
+	bv, lv := binary.BigEndian.Uint64(x), binary.LittleEndian.Uint64(x)
+	for _, s := range []int{0, 1, 7, 8, 13, 63, 64, 70, -1, -9, -64} {
+		var bl, br, ll, lr uint64
+		if s >= 0 && s < 64 {
+			bl, br, ll, lr = bv << s, bv >> s, lv >> s, lv << s
+		} else if s < 0 && s > -64 {
+			bl, br, ll, lr = bv >> -s, bv << -s, lv << -s, lv >> -s
+		}
+		check := func(op string, z []byte, got, want uint64) {
+			if got != want || len(z) != 8 {
+				t.Errorf("%s(%v): got %x, want %x", op, s, got, want)
+			}
+		}
+		z := BigEndian.ShiftLeft(nil, x, s)
+		check("BigEndian.ShiftLeft", z, binary.BigEndian.Uint64(z), bl)
+		z = BigEndian.ShiftRight(nil, x, s)
+		check("BigEndian.ShiftRight", z, binary.BigEndian.Uint64(z), br)
+		z = LittleEndian.ShiftLeft(nil, x, s)
+		check("LittleEndian.ShiftLeft", z, binary.LittleEndian.Uint64(z), ll)
+		z = LittleEndian.ShiftRight(nil, x, s)
+		check("LittleEndian.ShiftRight", z, binary.LittleEndian.Uint64(z), lr)
+
+		// In-place shifts
+		y := append([]byte(nil), x...)
+		LittleEndian.ShiftLeft(y, y, s)
+		check("in-place LittleEndian.ShiftLeft", y,
+			binary.LittleEndian.Uint64(y), ll)
+		y = append(y[:0], x...)
+		BigEndian.ShiftRight(y, y, s)
+		check("in-place BigEndian.ShiftRight", y,
+			binary.BigEndian.Uint64(y), br)
+	}
+	if z := BigEndian.ShiftLeft(nil, nil, 3); len(z) != 0 {
+		t.Errorf("ShiftLeft of empty slice: got %x", z)
+	}
+}
@@ -0,0 +1,859 @@
+package bytebits
+
+import (
+	"io"
+	"iter"
+	"math/bits"
+)
+
+
+// LittleEndianField represents a bit field within a byte slice
+// interpreted as a little-endian sequence of bits,
+// whose first bit is its least significant.
+type LittleEndianField field
+
+
+// Init sets the field to refer to a little-endian bit field within slice buf,
+// starting at bit offset ofs and extending for width bits.
+// The underlying slice must be large enough
+// to contain the complete bit field specified;
+// otherwise Init panics with ErrShortBuffer.
+func (z *LittleEndianField) Init(buf []byte, ofs, width int) Field {
+	if ofs < 0 || width < 0 {
+		panic(ErrOutOfRange)
+	}
+	if ofs + width > len(buf) * 8 {
+		panic(ErrShortBuffer)
+	}
+	z.b = buf[ofs >> 3:]
+	z.o = ofs & 7
+	z.w = width
+	return z
+}
+
+// Grow points the field to a little-endian bit field within slice buf,
+// copying buf to a new larger buffer if needed to include the bit field.
+// Returns buf or the newly-allocated buffer if it was grown.
+func (z *LittleEndianField) Grow(buf []byte, ofs, width int) []byte {
+	buf = Grow(buf, (ofs+width+7) >> 3)
+	z.Init(buf, ofs, width)
+	return buf
+}
+
+// Slice returns a new field aliasing the w-bit sub-range
+// starting at bit offset ofs within field z,
+// so that changes to either field are visible through the other.
+// The new field inherits z's padding policy.
+// Panics with ErrOutOfRange if the sub-range is not within z.
+func (z *LittleEndianField) Slice(ofs, w int) Field {
+	if ofs < 0 || w < 0 || ofs + w > z.w {
+		panic(ErrOutOfRange)
+	}
+	b, o := leNorm(z.b, z.o + ofs)
+	return &LittleEndianField{b, o, w, z.p}
+}
+
+// ReadBits implements the BitReader interface,
+// reading up to n bits from the start of the field, or 64 bits maximum.
+// On success, returns the bits read
+// in the least-significant bits of the returned value b,
+// with the first bit read in the least-significant position,
+// and shrinks the field to skip the n bits read.
+// Returns an EOF error if the bit field is less than n bits wide.
+func (z *LittleEndianField) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if n > z.w {
+		return 0, EOF
+	}
+	z.b, z.o, v = leGet(z.b, z.o, n)
+	z.w -= n
+	return v, nil
+}
+
+// ReadBitsAt implements the BitReaderAt interface,
+// reading n bits, or 64 bits maximum,
+// starting off bits from the start of the field,
+// without consuming them or otherwise modifying the field.
+// Returns an EOF error if the field ends before reading n bits,
+// or ErrOutOfRange if off is negative.
+func (z *LittleEndianField) ReadBitsAt(n int, off int64) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if off < 0 {
+		return 0, ErrOutOfRange
+	}
+	if off + int64(n) > int64(z.w) {
+		return 0, EOF
+	}
+	b, o := leNorm(z.b, z.o + int(off))
+	_, _, v = leGet(b, o, n)
+	return v, nil
+}
+
+// ReadBool reads a single bit from the start of the field
+// and returns true if it is 1, shrinking the field to skip it.
+// Returns an EOF error if the field is empty.
+func (z *LittleEndianField) ReadBool() (bool, error) {
+	return ReadBool(z)
+}
+
+// ReadBitsInto reads n bits from the start of the field,
+// which may be more than 64,
+// into a byte slice left-aligned as for LittleEndian.Bits,
+// and shrinks the field to skip the n bits read.
+// Returns dst[:(n+7)/8], or a new slice if dst is not large enough.
+// Returns an EOF error, reading nothing,
+// if the bit field is less than n bits wide.
+func (z *LittleEndianField) ReadBitsInto(dst []byte, n int) ([]byte, error) {
+	if n > z.w {
+		return dst, EOF
+	}
+	dst = LittleEndian.Bits(dst, z.b, z.o, n, Left)
+	z.b, z.o = leNorm(z.b, z.o + n)
+	z.w -= n
+	return dst, nil
+}
+
+// Reader returns a BitReader that reads the contents of field z
+// from its start, without consuming or otherwise modifying z itself.
+// The reader is an independent copy of the field,
+// so multiple readers may scan the same field concurrently.
+func (z *LittleEndianField) Reader() BitReader {
+	c := *z
+	return &c
+}
+
+// WriteBits implements the BitWriter interface,
+// writing the least-significant n bits of v, or 64 bits maximum,
+// to the start of the field, least-significant bit first.
+// On success, shrinks the field to skip the n bits written.
+// Returns io.ErrShortWrite if the bit field is less than n bits wide.
+func (z *LittleEndianField) WriteBits(n int, v uint64) error {
+	if n > 64 {
+		n = 64
+	}
+	if n > z.w {
+		return io.ErrShortWrite
+	}
+	z.b, z.o = lePut(z.b, z.o, n, v)
+	z.w -= n
+	return nil
+}
+
+// WriteBool writes a single bit to the start of the field,
+// 1 if b is true or 0 if false, shrinking the field to skip it.
+// Returns io.ErrShortWrite if the field is empty.
+func (z *LittleEndianField) WriteBool(b bool) error {
+	return WriteBool(z, b)
+}
+
+// SetPadding sets the policy that WriteTo and ReadFrom apply
+// to a trailing partial byte of field z.
+// Fields created by Field, and zero-valued fields, use PadZeros.
+func (z *LittleEndianField) SetPadding(p Padding) {
+	z.p = p
+}
+
+// WriteTo implements the io.WriterTo interface,
+// writing the contents of field z to w as whole bytes,
+// first bit first as for LittleEndian.Bits with Left alignment,
+// and completing or omitting a trailing partial byte
+// according to the field's padding policy.
+// Shrinks the field to skip the bits written,
+// which exclude any truncated trailing bits.
+// Returns the number of bytes written and any error encountered.
+func (z *LittleEndianField) WriteTo(w io.Writer) (int64, error) {
+	b := z.Bytes(Left)
+	if r := z.w & 7; r != 0 {
+		if z.p == Truncate {
+			b = b[:len(b)-1]
+		} else {
+			lePut(b[len(b)-1:], r, 8 - r, z.p.bits(r, true))
+		}
+	}
+	n, err := w.Write(b)
+	bits := n * 8
+	if bits > z.w {
+		bits = z.w
+	}
+	z.b, z.o = leNorm(z.b, z.o + bits)
+	z.w -= bits
+	return int64(n), err
+}
+
+// ReadFrom implements the io.ReaderFrom interface,
+// filling field z from the start with bytes read from r,
+// first bit first as for LittleEndian.PutBits with Left alignment,
+// until the field is full or r reaches EOF.
+// The field's padding policy determines whether a trailing partial byte
+// is read, with its extra bits discarded, or left unfilled.
+// Shrinks the field to skip the bits filled.
+// Returns the number of bytes read and any error other than EOF.
+func (z *LittleEndianField) ReadFrom(r io.Reader) (int64, error) {
+	l := z.w >> 3
+	if z.w & 7 != 0 && z.p != Truncate {
+		l++
+	}
+	b := make([]byte, l)
+	n, err := io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	bits := n * 8
+	if bits > z.w {
+		bits = z.w
+	}
+	leCopy(z.b, b, z.o, 0, bits)
+	z.b, z.o = leNorm(z.b, z.o + bits)
+	z.w -= bits
+	return int64(n), err
+}
+
+// Set sets the contents of bit field z to that of field x,
+// and returns z.
+// The source field x must be at least as long as field z.
+// If x is a BigEndianField, Set converts its first z.Len() bits
+// to little-endian bit order, preserving their value as an integer,
+// so that the first bit of z receives the least-significant bit of x.
+func (z *LittleEndianField) Set(x Field) Field {
+	if cx, ok := x.(*BigEndianField); ok {
+		convertField(z, cx.Slice(0, z.w).(fieldDigits))
+		return z
+	}
+	xf := x.(*LittleEndianField)
+	xb, xo, zb, zo, w := xf.b, xf.o, z.b, z.o, z.w
+	var xv uint64
+	for w >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		zb, zo = lePut64(zb, zo, xv)
+		w -= 64
+	}
+	xb, xo, xv = leGet(xb, xo, w)
+	zb, zo = lePut(zb, zo, w, xv)
+	return z
+}
+
+// And sets the contents of bit field z to the bitwise AND of fields x and y,
+// and returns z.
+// The source fields x and y must be at least as long as field z.
+func (z *LittleEndianField) And(x, y Field) Field {
+	xf, yf := x.(*LittleEndianField), y.(*LittleEndianField)
+	xb, xo, yb, yo, zb, zo, w := xf.b, xf.o, yf.b, yf.o, z.b, z.o, z.w
+	var xv, yv uint64
+	for w >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		yb, yo, yv = leGet64(yb, yo)
+		zb, zo = lePut64(zb, zo, xv & yv)
+		w -= 64
+	}
+	xb, xo, xv = leGet(xb, xo, w)
+	yb, yo, yv = leGet(yb, yo, w)
+	zb, zo = lePut(zb, zo, w, xv & yv)
+	return z
+}
+
+// AndNot sets the contents of bit field z
+// to the bitwise AND of fields x and NOT y,
+// and returns z.
+// The source fields x and y must be at least as long as field z.
+func (z *LittleEndianField) AndNot(x, y Field) Field {
+	xf, yf := x.(*LittleEndianField), y.(*LittleEndianField)
+	xb, xo, yb, yo, zb, zo, w := xf.b, xf.o, yf.b, yf.o, z.b, z.o, z.w
+	var xv, yv uint64
+	for w >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		yb, yo, yv = leGet64(yb, yo)
+		zb, zo = lePut64(zb, zo, xv &^ yv)
+		w -= 64
+	}
+	xb, xo, xv = leGet(xb, xo, w)
+	yb, yo, yv = leGet(yb, yo, w)
+	zb, zo = lePut(zb, zo, w, xv &^ yv)
+	return z
+}
+
+// Or sets the contents of bit field z to the bitwise OR of fields x and y,
+// and returns z.
+// The source fields x and y must be at least as long as field z.
+func (z *LittleEndianField) Or(x, y Field) Field {
+	xf, yf := x.(*LittleEndianField), y.(*LittleEndianField)
+	xb, xo, yb, yo, zb, zo, w := xf.b, xf.o, yf.b, yf.o, z.b, z.o, z.w
+	var xv, yv uint64
+	for w >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		yb, yo, yv = leGet64(yb, yo)
+		zb, zo = lePut64(zb, zo, xv | yv)
+		w -= 64
+	}
+	xb, xo, xv = leGet(xb, xo, w)
+	yb, yo, yv = leGet(yb, yo, w)
+	zb, zo = lePut(zb, zo, w, xv | yv)
+	return z
+}
+
+// Xor sets the contents of bit field z to the bitwise XOR of fields x and y,
+// and returns z.
+// The source fields x and y must be at least as long as field z.
+func (z *LittleEndianField) Xor(x, y Field) Field {
+	xf, yf := x.(*LittleEndianField), y.(*LittleEndianField)
+	xb, xo, yb, yo, zb, zo, w := xf.b, xf.o, yf.b, yf.o, z.b, z.o, z.w
+	var xv, yv uint64
+	for w >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		yb, yo, yv = leGet64(yb, yo)
+		zb, zo = lePut64(zb, zo, xv ^ yv)
+		w -= 64
+	}
+	xb, xo, xv = leGet(xb, xo, w)
+	yb, yo, yv = leGet(yb, yo, w)
+	zb, zo = lePut(zb, zo, w, xv ^ yv)
+	return z
+}
+
+// Not sets the contents of bit field z to the bitwise NOT of field x,
+// and returns z.
+// The source field x must be at least as long as field z.
+func (z *LittleEndianField) Not(x Field) Field {
+	xf := x.(*LittleEndianField)
+	xb, xo, zb, zo, w := xf.b, xf.o, z.b, z.o, z.w
+	var xv uint64
+	for w >= 64 {
+		xb, xo, xv = leGet64(xb, xo)
+		zb, zo = lePut64(zb, zo, ^xv)
+		w -= 64
+	}
+	xb, xo, xv = leGet(xb, xo, w)
+	zb, zo = lePut(zb, zo, w, ^xv)
+	return z
+}
+
+// RotateLeft sets field z to field x rotated left by rot bits,
+// toward offset 0 and less-significant positions.
+// To rotate right, pass a negative value for rot.
+// Field x must be at least as long as z.
+// The slices underlying x and z must not overlap.
+func (z *LittleEndianField) RotateLeft(x Field, rot int) Field {
+	// Determine the starting bit position to copy from source field x
+	zb, zo, w := z.b, z.o, z.w
+	if w == 0 {
+		return z
+	}
+	p := rot % w
+	if p < 0 {
+		p += w
+	}
+
+	// Copy bits until the end of the source field
+	xf := x.(*LittleEndianField)
+	xb, xo := leNorm(xf.b, xf.o + p)
+	zb, xb, zo, xo = leCopy(zb, xb, zo, xo, w - p)
+
+	// Then copy the rest of the bits from the beginning of the source
+	zb, xb, zo, xo = leCopy(zb, xf.b, zo, xf.o, p)
+	return z
+}
+
+// Reverse sets field z to the contents of field x in reverse bit order,
+// so that the first bit of x becomes the last bit of z and vice versa,
+// and returns z.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place reversal,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *LittleEndianField) Reverse(x Field) Field {
+	xf := x.(*LittleEndianField)
+	lo, hi := 0, z.w
+	for hi - lo > 0 {
+		// Swap k bits from each end, at most 64,
+		// leaving the center bit of an odd-width middle in place
+		k := (hi - lo) >> 1
+		if k > 64 {
+			k = 64
+		}
+		if k == 0 {
+			xb, xo := leNorm(xf.b, xf.o + lo)
+			zb, zo := leNorm(z.b, z.o + lo)
+			leCopy(zb, xb, zo, xo, 1)
+			break
+		}
+		ab, ao := leNorm(xf.b, xf.o + lo)
+		_, _, a := leGet(ab, ao, k)
+		bb, bo := leNorm(xf.b, xf.o + hi - k)
+		_, _, b := leGet(bb, bo, k)
+		zb, zo := leNorm(z.b, z.o + lo)
+		lePut(zb, zo, k, bits.Reverse64(b) >> (64 - k))
+		zb, zo = leNorm(z.b, z.o + hi - k)
+		lePut(zb, zo, k, bits.Reverse64(a) >> (64 - k))
+		lo, hi = lo + k, hi - k
+	}
+	return z
+}
+
+// BinaryToGray sets field z to the reflected binary Gray code of field x,
+// in which each bit is the exclusive-or of the corresponding bit of x
+// and the next more-significant bit, and returns z.
+// Fields are treated as unsigned integers with the last bit most significant,
+// as in LittleEndian.Uint.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place conversion,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *LittleEndianField) BinaryToGray(x Field) Field {
+	xf := x.(*LittleEndianField)
+	var c uint64	// least-significant bit of the preceding chunk
+	for hi := z.w; hi > 0; {	// convert from the most-significant end
+		n := min(hi, 64)
+		o := hi - n
+		hi = o
+		xb, xo := leNorm(xf.b, xf.o + o)
+		_, _, v := leGet(xb, xo, n)
+		zb, zo := leNorm(z.b, z.o + o)
+		lePut(zb, zo, n, v ^ (v >> 1 | c << (n-1)))
+		c = v & 1
+	}
+	return z
+}
+
+// GrayToBinary sets field z to the binary value of the Gray code in field x,
+// inverting BinaryToGray, and returns z.
+// Each bit of z is the parity of the corresponding bit of x
+// and all more-significant bits.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place conversion,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *LittleEndianField) GrayToBinary(x Field) Field {
+	xf := x.(*LittleEndianField)
+	var c uint64	// least-significant bit of the preceding chunk
+	for hi := z.w; hi > 0; {	// convert from the most-significant end
+		n := min(hi, 64)
+		o := hi - n
+		hi = o
+		xb, xo := leNorm(xf.b, xf.o + o)
+		_, _, v := leGet(xb, xo, n)
+		for s := 1; s < n; s <<= 1 {	// prefix parity within the chunk
+			v ^= v >> s
+		}
+		v ^= -c & (1 << n - 1)	// parity of all more-significant chunks
+		zb, zo := leNorm(z.b, z.o + o)
+		lePut(zb, zo, n, v)
+		c = v & 1
+	}
+	return z
+}
+
+// ShiftLeft sets field z to field x shifted left by s bits,
+// toward offset 0 and less-significant positions, filling the vacated bits with zeros.
+// To shift right, pass a negative value for s.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place shifts,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *LittleEndianField) ShiftLeft(x Field, s int) Field {
+	if s < 0 {
+		return z.ShiftRight(x, -s)
+	}
+	xf := x.(*LittleEndianField)
+	zb, zo, w := z.b, z.o, z.w
+	if s > w {
+		s = w
+	}
+	if sameStart(xf.b, zb, xf.o, zo) {
+		leMove(zb, zo, zo + s, w - s)
+		zb, zo = leNorm(zb, zo + w - s)
+	} else {
+		xb, xo := leNorm(xf.b, xf.o + s)
+		zb, _, zo, _ = leCopy(zb, xb, zo, xo, w - s)
+	}
+	leFill(zb, zo, s, 0)
+	return z
+}
+
+// ShiftRight sets field z to field x shifted right by s bits,
+// toward higher offsets and more-significant positions, filling the vacated bits with zeros.
+// To shift left, pass a negative value for s.
+// Field x must be at least as long as z.
+// The fields may be identical for in-place shifts,
+// but the slices underlying x and z must not otherwise overlap.
+func (z *LittleEndianField) ShiftRight(x Field, s int) Field {
+	if s < 0 {
+		return z.ShiftLeft(x, -s)
+	}
+	xf := x.(*LittleEndianField)
+	zb, zo, w := z.b, z.o, z.w
+	if s > w {
+		s = w
+	}
+	if sameStart(xf.b, zb, xf.o, zo) {	// copy from the end
+		leMove(zb, zo + s, zo, w - s)
+	} else {
+		tb, to := leNorm(zb, zo + s)
+		leCopy(tb, xf.b, to, xf.o, w - s)
+	}
+	leFill(zb, zo, s, 0)
+	return z
+}
+
+// InsertBits inserts n zero bits into field z at bit offset ofs,
+// moving the bits previously at ofs and beyond n bits toward the end,
+// and grows the field's width by n bits.
+// The inserted bits may then be set via z.Slice(ofs, n).
+// The underlying buffer must have room for the grown field;
+// otherwise InsertBits panics with ErrShortBuffer.
+// Panics with ErrOutOfRange if ofs is not within the field or n is negative.
+func (z *LittleEndianField) InsertBits(ofs, n int) {
+	if ofs < 0 || ofs > z.w || n < 0 {
+		panic(ErrOutOfRange)
+	}
+	if z.o + z.w + n > len(z.b) * 8 {
+		panic(ErrShortBuffer)
+	}
+	leMove(z.b, z.o + ofs + n, z.o + ofs, z.w - ofs)
+	zb, zo := leNorm(z.b, z.o + ofs)
+	leFill(zb, zo, n, 0)
+	z.w += n
+}
+
+// DeleteBits deletes n bits from field z starting at bit offset ofs,
+// moving the bits following them n bits toward the start,
+// and shrinks the field's width by n bits.
+// The bits in the underlying buffer past the field's new end
+// are left unmodified.
+// Panics with ErrOutOfRange if the deleted range is not within the field.
+func (z *LittleEndianField) DeleteBits(ofs, n int) {
+	if ofs < 0 || n < 0 || ofs + n > z.w {
+		panic(ErrOutOfRange)
+	}
+	leMove(z.b, z.o + ofs, z.o + ofs + n, z.w - ofs - n)
+	z.w -= n
+}
+
+// Swap exchanges the contents of bit field z with those of field x,
+// which must be of the same width, 64 bits at a time,
+// without allocating a temporary buffer.
+// The fields may be in different buffers but must not overlap.
+// Panics with ErrLengthMismatch if the fields' widths differ.
+func (z *LittleEndianField) Swap(x Field) {
+	xf := x.(*LittleEndianField)
+	if xf.w != z.w {
+		panic(ErrLengthMismatch)
+	}
+	xb, xo, zb, zo, w := xf.b, xf.o, z.b, z.o, z.w
+	var xv, zv uint64
+	for w >= 64 {
+		_, _, xv = leGet64(xb, xo)
+		_, _, zv = leGet64(zb, zo)
+		xb, xo = lePut64(xb, xo, zv)
+		zb, zo = lePut64(zb, zo, xv)
+		w -= 64
+	}
+	_, _, xv = leGet(xb, xo, w)
+	_, _, zv = leGet(zb, zo, w)
+	lePut(xb, xo, w, zv)
+	lePut(zb, zo, w, xv)
+}
+
+// Len returns the width of field z in bits.
+func (z *LittleEndianField) Len() int {
+	return z.w
+}
+
+// Equal reports whether fields z and x have the same width and contents,
+// regardless of their alignment in their underlying buffers.
+func (z *LittleEndianField) Equal(x Field) bool {
+	return x.Len() == z.w && z.Compare(x) == 0
+}
+
+// Compare compares the contents of fields z and x as unsigned integers
+// with the last bit most significant, as in LittleEndian.Uint,
+// and returns -1 if z < x, 0 if z == x, or +1 if z > x.
+// Panics with ErrLengthMismatch if the fields' widths differ.
+func (z *LittleEndianField) Compare(x Field) int {
+	xf := x.(*LittleEndianField)
+	if xf.w != z.w {
+		panic(ErrLengthMismatch)
+	}
+	w := z.w
+	var xv, zv uint64
+	for w >= 64 {	// compare from the most-significant end
+		xb, xo := leNorm(xf.b, xf.o + w - 64)
+		zb, zo := leNorm(z.b, z.o + w - 64)
+		_, _, xv = leGet64(xb, xo)
+		_, _, zv = leGet64(zb, zo)
+		if zv != xv {
+			break
+		}
+		w -= 64
+	}
+	if w < 64 {
+		_, _, xv = leGet(xf.b, xf.o, w)
+		_, _, zv = leGet(z.b, z.o, w)
+	}
+	switch {
+	case zv < xv:
+		return -1
+	case zv > xv:
+		return 1
+	}
+	return 0
+}
+
+// Count returns the number of bits with value b (0 or 1) in field z.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func (z *LittleEndianField) Count(b uint) (n int) {
+	zb, zo, w := z.b, z.o, z.w
+	var v uint64
+	switch b {
+	case 0:
+		for w >= 64 {
+			zb, zo, v = leGet64(zb, zo)
+			n += bits.OnesCount64(^v)
+			w -= 64
+		}
+		zb, zo, v = leGet(zb, zo, w)
+		n += bits.OnesCount64(v ^ ((1 << w) - 1))
+	case 1:
+		for w >= 64 {
+			zb, zo, v = leGet64(zb, zo)
+			n += bits.OnesCount64(v)
+			w -= 64
+		}
+		zb, zo, v = leGet(zb, zo, w)
+		n += bits.OnesCount64(v)
+	default:
+		panic(ErrBadBitValue)
+	}
+	return n
+}
+
+// FindFirst returns the offset of the first bit in field z
+// with value b (0 or 1), or -1 if there is no such bit.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func (z *LittleEndianField) FindFirst(b uint) int {
+	return z.Next(b, 0)
+}
+
+// Next returns the offset of the first bit in field z
+// with value b (0 or 1) at or after offset from,
+// or -1 if there is no such bit.
+// Panics with ErrBadBitValue if b is not 0 or 1,
+// or with ErrOutOfRange if from is negative.
+func (z *LittleEndianField) Next(b uint, from int) int {
+	if b > 1 {
+		panic(ErrBadBitValue)
+	}
+	if from < 0 {
+		panic(ErrOutOfRange)
+	}
+	if from >= z.w {
+		return -1
+	}
+	i := from + LittleEndian.TrailingAt(z.b, z.o + from, z.w - from, 1 - b)
+	if i == z.w {
+		return -1
+	}
+	return i
+}
+
+// Ones returns an iterator over the offsets of the bits in field z
+// with value 1, in increasing order.
+func (z *LittleEndianField) Ones() iter.Seq[int] {
+	return z.positions(1)
+}
+
+// Zeros returns an iterator over the offsets of the bits in field z
+// with value 0, in increasing order.
+func (z *LittleEndianField) Zeros() iter.Seq[int] {
+	return z.positions(0)
+}
+
+// Words returns an iterator over the contents of field z
+// 64 bits at a time in order of increasing offset,
+// yielding the number of bits n and the bits themselves
+// in the least-significant n bits of v, with the first bit least significant.
+// Every word but the last holds 64 bits;
+// the last holds the remaining 1 to 64 bits of the field.
+func (z *LittleEndianField) Words() iter.Seq2[int, uint64] {
+	return func(yield func(n int, v uint64) bool) {
+		zb, zo, w := z.b, z.o, z.w
+		for w > 0 {
+			n := w
+			if n > 64 {
+				n = 64
+			}
+			var v uint64
+			zb, zo, v = leGet(zb, zo, n)
+			if !yield(n, v) {
+				return
+			}
+			w -= n
+		}
+	}
+}
+
+// positions returns an iterator over the offsets of bits with value b,
+// scanning the field 64 bits at a time.
+func (z *LittleEndianField) positions(b uint) iter.Seq[int] {
+	m := ^bitMask(b)	// flips zero bits to ones if b == 0
+	return func(yield func(int) bool) {
+		zb, zo, w := z.b, z.o, z.w
+		for base := 0; base < w; base += 64 {
+			n := w - base
+			if n > 64 {
+				n = 64
+			}
+			var v uint64
+			zb, zo, v = leGet(zb, zo, n)
+			v ^= m & (1 << n - 1)
+			for v != 0 {
+				i := bits.TrailingZeros64(v)
+				if !yield(base + i) {
+					return
+				}
+				v &= v - 1
+			}
+		}
+	}
+}
+
+// Canonical returns a copy of the contents of field z
+// in a freshly-allocated buffer just large enough to hold it,
+// starting at bit offset 0 and with any unused most-significant bits
+// of the last byte cleared to zero.
+// Two fields with the same width and contents always yield identical
+// canonical buffers regardless of their alignment in their underlying slices,
+// making this form suitable for hashing, comparing, or storing fields.
+func (z *LittleEndianField) Canonical() []byte {
+	buf := make([]byte, (z.w + 7) >> 3)
+	leCopy(buf, z.b, 0, z.o, z.w)
+	return buf
+}
+
+// String returns the contents of field z as a string of binary digits,
+// most-significant (last) bit first.
+func (z *LittleEndianField) String() string {
+	return string(appendDigits(nil, z, 1, lowerDigits))
+}
+
+// top returns as an integer the n bits, at most 64,
+// that follow the ofs most-significant bits of field z.
+func (z *LittleEndianField) top(ofs, n int) uint64 {
+	b, o := leNorm(z.b, z.o + z.w - ofs - n)
+	_, _, v := leGet(b, o, n)
+	return v
+}
+
+// putTop sets the n bits, at most 64,
+// that follow the ofs most-significant bits of field z to integer v.
+func (z *LittleEndianField) putTop(ofs, n int, v uint64) {
+	b, o := leNorm(z.b, z.o + z.w - ofs - n)
+	lePut(b, o, n, v)
+}
+
+// Bytes returns a copy of the contents of field z
+// in a freshly-allocated buffer just large enough to hold it,
+// left- or right-aligned according to align,
+// with any unused padding bits cleared to zero.
+func (z *LittleEndianField) Bytes(align Align) []byte {
+	return z.AppendBytes(nil, align)
+}
+
+// AppendBytes appends the contents of field z to dst
+// as (z.Len()+7)/8 whole bytes, left- or right-aligned according to align,
+// with any unused padding bits cleared to zero,
+// and returns the extended slice.
+func (z *LittleEndianField) AppendBytes(dst []byte, align Align) []byte {
+	l, n := len(dst), (z.w + 7) >> 3
+	dst = Grow(dst, l + n)
+	b := dst[l:]
+	for i := range b {
+		b[i] = 0
+	}
+	leCopy(b, z.b, alignOfs(n, z.w, align), z.o, z.w)
+	return dst
+}
+
+// Key returns a string that uniquely encodes
+// both the width and the bit content of field z,
+// so that the contents of fields may be used as Go map keys.
+// Like Hash, the key does not depend on the field's alignment.
+// Fields with the same bits but different widths,
+// such as a 1-bit and a 2-bit field both containing only zeros,
+// always yield different keys.
+func (z *LittleEndianField) Key() string {
+	return bitsKey(z.w, z.Canonical())
+}
+
+// Hash returns a 64-bit hash of the contents of field z,
+// which depends on the seed and on the field's bit content and width,
+// but not on the alignment of the field within its underlying buffer.
+// Fields with identical contents therefore hash identically
+// wherever they are located, while fields of different widths,
+// such as a 1-bit and a 2-bit field both containing only zeros,
+// generally hash differently.
+// The hash is not cryptographically secure.
+func (z *LittleEndianField) Hash(seed uint64) uint64 {
+	zb, zo, w := z.b, z.o, z.w
+	h := seed
+	var v uint64
+	for w >= 64 {
+		zb, zo, v = leGet64(zb, zo)
+		h = hashMix(h, v)
+		w -= 64
+	}
+	if w > 0 {
+		zb, zo, v = leGet(zb, zo, w)
+		h = hashMix(h, v)
+	}
+	return hashMix(h, uint64(z.w))
+}
+
+// Fill sets all bits in field z to bit value b (0 or 1).
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func (z *LittleEndianField) Fill(b uint) {
+	zb, zo, w := z.b, z.o, z.w
+	switch b {
+	case 0:
+		for w >= 64 {
+			zb, zo = lePut64(zb, zo, 0)
+			w -= 64
+		}
+		zb, zo = lePut(zb, zo, w, 0)
+	case 1:
+		for w >= 64 {
+			zb, zo = lePut64(zb, zo, (1<<64)-1)
+			w -= 64
+		}
+		zb, zo = lePut(zb, zo, w, (1<<64)-1)
+	default:
+		panic(ErrBadBitValue)
+	}
+}
+
+// FillPattern fills field z with repetitions of the n-bit pattern
+// in the least-significant n bits of pattern,
+// whose first bit is its least significant,
+// where n must be between 1 and 64.
+// If the field's width is not a multiple of n,
+// the last repetition is truncated to the leading bits of the pattern.
+// Panics with ErrOutOfRange if n is not within this range.
+func (z *LittleEndianField) FillPattern(pattern uint64, n int) {
+	if n < 1 || n > 64 {
+		panic(ErrOutOfRange)
+	}
+	if n < 64 {
+		pattern &= 1 << n - 1
+	}
+
+	// Tile as many whole repetitions as fit into a 64-bit word
+	var rep uint64
+	k := 64 / n * n
+	for i := 0; i < 64 / n; i++ {
+		rep |= pattern << (i * n)
+	}
+	zb, zo, w := z.b, z.o, z.w
+	for w >= k {
+		zb, zo = lePut(zb, zo, k, rep)
+		w -= k
+	}
+	lePut(zb, zo, w, rep & (1 << w - 1))
+}
@@ -0,0 +1,111 @@
+package bytebits
+
+import (
+	"sync/atomic"
+)
+
+
+// BitMeter collects statistics on the calls made through
+// the BitReader and BitWriter wrappers it creates,
+// so that decoders and encoders can expose throughput
+// and field-size statistics to monitoring
+// by wrapping a stream once rather than instrumenting every call site.
+//
+// A BitMeter counts the total number of bits successfully transferred,
+// the total number of calls, and a histogram of the number of bits
+// requested per call.
+// Its counters are updated atomically, and may be read concurrently
+// with I/O through its wrappers.
+// A single BitMeter may aggregate statistics over several streams.
+//
+// The zero value is a BitMeter ready to use.
+//
+type BitMeter struct {
+	bits, calls atomic.Int64
+	sizes [65]atomic.Int64
+
+	// Hook, if non-nil, is called after each call through a wrapper
+	// with the number of bits requested and the error returned, if any.
+	// It must be set before the meter is used
+	// and must be safe to call from any goroutine using the wrappers.
+	Hook func(n int, err error)
+}
+
+// Bits returns the total number of bits transferred through m's wrappers.
+func (m *BitMeter) Bits() int64 {
+	return m.bits.Load()
+}
+
+// Calls returns the total number of calls made through m's wrappers.
+func (m *BitMeter) Calls() int64 {
+	return m.calls.Load()
+}
+
+// Histogram returns a snapshot of the histogram of call sizes,
+// in which element n is the number of calls requesting n bits.
+func (m *BitMeter) Histogram() (h [65]int64) {
+	for i := range h {
+		h[i] = m.sizes[i].Load()
+	}
+	return h
+}
+
+// Reset clears all of m's counters.
+func (m *BitMeter) Reset() {
+	m.bits.Store(0)
+	m.calls.Store(0)
+	for i := range m.sizes {
+		m.sizes[i].Store(0)
+	}
+}
+
+func (m *BitMeter) record(n int, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if n < 0 {
+		n = 0
+	}
+	m.calls.Add(1)
+	m.sizes[n].Add(1)
+	if err == nil {
+		m.bits.Add(int64(n))
+	}
+	if m.Hook != nil {
+		m.Hook(n, err)
+	}
+}
+
+// Reader returns a BitReader that reads from r,
+// recording each call in meter m.
+func (m *BitMeter) Reader(r BitReader) BitReader {
+	return &meteredReader{r, m}
+}
+
+// Writer returns a BitWriter that writes to w,
+// recording each call in meter m.
+func (m *BitMeter) Writer(w BitWriter) BitWriter {
+	return &meteredWriter{w, m}
+}
+
+type meteredReader struct {
+	r BitReader
+	m *BitMeter
+}
+
+func (mr *meteredReader) ReadBits(n int) (uint64, error) {
+	v, err := mr.r.ReadBits(n)
+	mr.m.record(n, err)
+	return v, err
+}
+
+type meteredWriter struct {
+	w BitWriter
+	m *BitMeter
+}
+
+func (mw *meteredWriter) WriteBits(n int, v uint64) error {
+	err := mw.w.WriteBits(n, v)
+	mw.m.record(n, err)
+	return err
+}
@@ -0,0 +1,38 @@
+package bytebits
+
+import (
+	"testing"
+)
+
+
+func TestBitMeter(t *testing.T) {
+	var m BitMeter
+	var hooked int
+	m.Hook = func(n int, err error) { hooked += n }
+
+	buf := make([]byte, 4)
+	var f BigEndianField
+	f.Init(buf, 0, 32)
+	w := m.Writer(&f)
+	w.WriteBits(3, 5)
+	w.WriteBits(13, 0x1234)
+	w.WriteBits(20, 0)		// fails: only 16 bits left
+
+	f.Init(buf, 0, 32)
+	r := m.Reader(&f)
+	r.ReadBits(3)
+	r.ReadBits(3)
+
+	if m.Bits() != 22 || m.Calls() != 5 || hooked != 42 ||
+			buf[0] != 0xb2 {
+		t.Errorf("BitMeter: got %v bits in %v calls, %v hooked",
+			m.Bits(), m.Calls(), hooked)
+	}
+	if h := m.Histogram(); h[3] != 3 || h[13] != 1 || h[20] != 1 {
+		t.Errorf("BitMeter histogram wrong: %v", h)
+	}
+	m.Reset()
+	if m.Bits() != 0 || m.Calls() != 0 || m.Histogram()[3] != 0 {
+		t.Errorf("BitMeter not reset")
+	}
+}
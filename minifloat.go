@@ -0,0 +1,227 @@
+package bytebits
+
+import (
+	"math"
+)
+
+
+// Minifloat describes a small binary floating-point format,
+// such as the 8-bit FP8 formats of machine learning accelerators,
+// the half-precision formats of graphics hardware,
+// or the ad-hoc formats of embedded sensors,
+// with the sign, exponent, and mantissa fields laid out
+// most-significant first as in IEEE 754.
+//
+// A value with an exponent field e between 1 and its maximum
+// is normal, with an implicit leading one bit,
+// and has the value 1.m * 2^(e-Bias).
+// An exponent field of zero denotes a subnormal value 0.m * 2^(1-Bias)
+// if Subnormals is true, and otherwise a zero of either sign,
+// in which case values too small to be normal are flushed to zero.
+// Special selects which encodings, if any, denote infinities and NaNs.
+//
+type Minifloat struct {
+	ExpBits int		// Width of the exponent field, 1 to 11
+	MantBits int		// Width of the mantissa field, 0 to 52
+	Bias int		// Exponent bias, usually 2^(ExpBits-1)-1
+	Signed bool		// Leading sign bit present
+	Subnormals bool		// Zero exponent denotes subnormal values
+	Special Special		// Encodings of infinities and NaNs
+}
+
+// Special identifies how a Minifloat format encodes infinities and NaNs.
+type Special uint8
+
+const (
+	// SpecialNone reserves no encodings: the all-ones exponent field
+	// is an ordinary exponent, values too large to represent
+	// saturate to the largest finite value, and NaNs encode as zero.
+	SpecialNone Special = iota
+
+	// SpecialInfNaN reserves the all-ones exponent field as in IEEE 754,
+	// for infinities with a zero mantissa and NaNs otherwise.
+	// Values too large to represent become infinities.
+	SpecialInfNaN
+
+	// SpecialNaNOnly reserves only the encodings with all-ones exponent
+	// and mantissa fields, of either sign, for NaN, and has no infinities,
+	// as in the FP8 E4M3 format.
+	// Values too large to represent, including infinities,
+	// saturate to the largest finite value.
+	SpecialNaNOnly
+)
+
+// Common minifloat formats.
+var (
+	// Binary16 is the IEEE 754 half-precision format.
+	Binary16 = Minifloat{5, 10, 15, true, true, SpecialInfNaN}
+
+	// BFloat16 is the brain floating-point format,
+	// the upper half of an IEEE 754 single-precision value.
+	BFloat16 = Minifloat{8, 7, 127, true, true, SpecialInfNaN}
+
+	// FP8E5M2 is the 8-bit format with 5 exponent and 2 mantissa bits
+	// of the OCP 8-bit floating point specification.
+	FP8E5M2 = Minifloat{5, 2, 15, true, true, SpecialInfNaN}
+
+	// FP8E4M3 is the 8-bit format with 4 exponent and 3 mantissa bits
+	// of the OCP 8-bit floating point specification,
+	// which has no infinities and a single NaN encoding of each sign.
+	FP8E4M3 = Minifloat{4, 3, 7, true, true, SpecialNaNOnly}
+
+	// Float11 is the unsigned 11-bit format
+	// of the components of packed R11G11B10 graphics formats.
+	Float11 = Minifloat{5, 6, 15, false, true, SpecialInfNaN}
+)
+
+// Width returns the total width in bits of values in format f.
+// Panics with ErrOutOfRange if the format's field widths are invalid.
+func (f Minifloat) Width() int {
+	if f.ExpBits < 1 || f.ExpBits > 11 || f.MantBits < 0 || f.MantBits > 52 {
+		panic(ErrOutOfRange)
+	}
+	w := f.ExpBits + f.MantBits
+	if f.Signed {
+		w++
+	}
+	return w
+}
+
+// maxFinite returns the encoding of the largest finite value,
+// without its sign bit.
+func (f Minifloat) maxFinite() uint64 {
+	ones := uint64(1) << (f.ExpBits + f.MantBits) - 1
+	switch f.Special {
+	case SpecialInfNaN:
+		return ones - 1 << f.MantBits	// exponent field one below all-ones
+	case SpecialNaNOnly:
+		return ones - 1
+	}
+	return ones
+}
+
+// Decode returns the value of the encoded minifloat v,
+// held in the least-significant f.Width() bits.
+func (f Minifloat) Decode(v uint64) float64 {
+	w := f.Width()
+	mb := f.MantBits
+	m := v & (1 << mb - 1)
+	e := int(v >> mb) & (1 << f.ExpBits - 1)
+	var x float64
+	switch {
+	case f.Special == SpecialInfNaN && e == 1 << f.ExpBits - 1:
+		x = math.Inf(1)
+		if m != 0 {
+			x = math.NaN()
+		}
+	case f.Special == SpecialNaNOnly && e == 1 << f.ExpBits - 1 &&
+			m == 1 << mb - 1:
+		x = math.NaN()
+	case e == 0 && f.Subnormals:
+		x = math.Ldexp(float64(m), 1 - f.Bias - mb)
+	case e == 0:
+		x = 0
+	default:
+		x = math.Ldexp(float64(m | 1 << mb), e - f.Bias - mb)
+	}
+	if f.Signed && v >> (w-1) & 1 != 0 {
+		x = -x
+	}
+	return x
+}
+
+// Encode returns the encoding of x in format f,
+// in the least-significant f.Width() bits,
+// rounding to the nearest representable value with ties to even.
+// Values too large for the format become infinities
+// or saturate to the largest finite value, according to f.Special.
+// A NaN encodes as a quiet NaN, or as zero in a format without NaNs,
+// or as an infinity in a SpecialInfNaN format with no mantissa bits
+// to mark a NaN,
+// and negative values encode as zero in an unsigned format.
+func (f Minifloat) Encode(x float64) uint64 {
+	w := f.Width()
+	mb := f.MantBits
+	var s uint64
+	if math.Signbit(x) {
+		if !f.Signed {
+			return 0
+		}
+		s = 1 << (w-1)
+		x = -x
+	}
+	inf := uint64(1 << f.ExpBits - 1) << mb
+	max := f.maxFinite()
+	switch {
+	case math.IsNaN(x):
+		switch f.Special {
+		case SpecialInfNaN:
+			return s | inf | 1 << mb >> 1	// quiet NaN
+		case SpecialNaNOnly:
+			return s | inf | (1 << mb - 1)
+		}
+		return 0
+	case math.IsInf(x, 1):
+		if f.Special == SpecialInfNaN {
+			return s | inf
+		}
+		return s | max
+	case x == 0:
+		return s
+	}
+
+	// Determine the exponent field of x and round its mantissa,
+	// letting a mantissa that rounds up to 2.0 carry into the exponent
+	_, exp := math.Frexp(x)	// x = frac * 2^exp, 0.5 <= frac < 1
+	e := exp - 1 + f.Bias
+	if e < 1 {
+		if !f.Subnormals {
+			if x <= math.Ldexp(1, -f.Bias) {	// half the smallest normal
+				return s
+			}
+			return s | 1 << mb
+		}
+		e = 1
+	}
+	v := max + 1
+	if e <= int(max >> mb) {
+		m := math.RoundToEven(math.Ldexp(x, mb - (e - f.Bias)))
+		v = uint64(e - 1) << mb + uint64(m)
+	}
+	if v > max {
+		if f.Special == SpecialInfNaN {
+			return s | inf
+		}
+		return s | max
+	}
+	return s | v
+}
+
+// Read reads a minifloat in format f from bit stream r,
+// which must deliver its bits most-significant first.
+func (f Minifloat) Read(r BitReader) (float64, error) {
+	v, err := r.ReadBits(f.Width())
+	if err != nil {
+		return 0, err
+	}
+	return f.Decode(v), nil
+}
+
+// Write writes x as a minifloat in format f to bit stream w,
+// most-significant bit first, rounding it as Encode does.
+func (f Minifloat) Write(w BitWriter, x float64) error {
+	return w.WriteBits(f.Width(), f.Encode(x))
+}
+
+// Get returns the value of the minifloat in format f
+// at bit offset xofs in slice x, using bit order order.
+func (f Minifloat) Get(order BitOrder, x []byte, xofs int) float64 {
+	return f.Decode(order.Uint(x, xofs, f.Width()))
+}
+
+// Put writes x as a minifloat in format f at bit offset zofs in slice z,
+// using bit order order, rounding it as Encode does.
+// Copies z and returns a new slice if z is nil or not large enough.
+func (f Minifloat) Put(order BitOrder, z []byte, zofs int, x float64) []byte {
+	return order.PutUint(z, zofs, f.Width(), f.Encode(x))
+}
@@ -0,0 +1,145 @@
+package bytebits
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+
+func TestMinifloatBinary16(t *testing.T) {
+	for _, c := range []struct {
+		x float64
+		v uint64
+	}{
+		{0, 0x0000}, {math.Copysign(0, -1), 0x8000},
+		{1, 0x3c00}, {-2, 0xc000}, {0.5, 0x3800}, {1.0/3, 0x3555},
+		{65504, 0x7bff}, {65519, 0x7bff}, {65520, 0x7c00},
+		{math.Inf(-1), 0xfc00}, {math.Ldexp(1, -24), 0x0001},
+		{math.Ldexp(1, -25), 0x0000}, {math.Ldexp(3, -26), 0x0001},
+		{math.Ldexp(1023, -24), 0x03ff}, {math.Ldexp(2047, -25), 0x0400},
+	} {
+		if v := Binary16.Encode(c.x); v != c.v {
+			t.Errorf("Binary16.Encode(%v): got %04x, want %04x", c.x, v, c.v)
+		}
+	}
+	if v := Binary16.Encode(math.NaN()); v != 0x7e00 {
+		t.Errorf("Binary16.Encode(NaN): got %04x", v)
+	}
+
+	// Every non-NaN encoding round-trips through float64
+	for v := uint64(0); v < 1 << 16; v++ {
+		x := Binary16.Decode(v)
+		if math.IsNaN(x) {
+			if v & 0x7c00 != 0x7c00 || v & 0x3ff == 0 {
+				t.Fatalf("Binary16.Decode(%04x): unexpected NaN", v)
+			}
+			continue
+		}
+		if e := Binary16.Encode(x); e != v {
+			t.Fatalf("Binary16 %04x decodes to %v, encodes to %04x",
+				v, x, e)
+		}
+	}
+}
+
+func TestMinifloatFP8E4M3(t *testing.T) {
+	for _, c := range []struct {
+		x float64
+		v uint64
+	}{
+		{0, 0x00}, {1, 0x38}, {-2, 0xc0}, {256, 0x78}, {448, 0x7e},
+		{464, 0x7e}, {1e9, 0x7e}, {math.Inf(1), 0x7e}, {math.Inf(-1), 0xfe},
+		{math.Ldexp(1, -6), 0x08}, {math.Ldexp(1, -9), 0x01},
+		{math.Ldexp(1, -11), 0x00},
+	} {
+		if v := FP8E4M3.Encode(c.x); v != c.v {
+			t.Errorf("FP8E4M3.Encode(%v): got %02x, want %02x", c.x, v, c.v)
+		}
+	}
+	if v := FP8E4M3.Encode(math.NaN()); v != 0x7f {
+		t.Errorf("FP8E4M3.Encode(NaN): got %02x", v)
+	}
+
+	// Every encoding but S.1111.111 is finite and round-trips
+	for v := uint64(0); v < 1 << 8; v++ {
+		x := FP8E4M3.Decode(v)
+		if v & 0x7f == 0x7f {
+			if !math.IsNaN(x) {
+				t.Errorf("FP8E4M3.Decode(%02x): got %v, want NaN", v, x)
+			}
+			continue
+		}
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			t.Fatalf("FP8E4M3.Decode(%02x): got %v", v, x)
+		}
+		if e := FP8E4M3.Encode(x); e != v {
+			t.Fatalf("FP8E4M3 %02x decodes to %v, encodes to %02x",
+				v, x, e)
+		}
+	}
+}
+
+func TestMinifloatBFloat16(t *testing.T) {
+	// BFloat16 rounds float32 values as truncation with round-to-even
+	for i := 0; i < 100000; i++ {
+		b := rand.Uint32()
+		x := math.Float32frombits(b)
+		if x != x {
+			continue
+		}
+		want := uint64((b + 0x7fff + (b >> 16 & 1)) >> 16)
+		if v := BFloat16.Encode(float64(x)); v != want {
+			t.Fatalf("BFloat16.Encode(%v): got %04x, want %04x",
+				x, v, want)
+		}
+	}
+}
+
+func TestMinifloatFormats(t *testing.T) {
+	// Saturating unsigned format without subnormals
+	f := Minifloat{ExpBits: 3, MantBits: 2, Bias: 3}
+	for _, c := range []struct {
+		x float64
+		v uint64
+	}{
+		{0, 0}, {-1, 0}, {1, 0x0c}, {0.25, 0x04}, {0.125, 0x00},
+		{0.13, 0x04}, {1.75, 0x0f}, {28, 0x1f}, {1e9, 0x1f},
+		{math.Inf(1), 0x1f}, {math.NaN(), 0},
+	} {
+		if v := f.Encode(c.x); v != c.v {
+			t.Errorf("Encode(%v): got %02x, want %02x", c.x, v, c.v)
+		}
+	}
+	if x := f.Decode(0x03); x != 0 {
+		t.Errorf("Decode of flushed subnormal: got %v", x)
+	}
+	if x := f.Decode(0x1f); x != 28 {
+		t.Errorf("Decode of largest value: got %v", x)
+	}
+
+	// FP8 E5M2 through the stream and slice interfaces
+	w := NewAppendWriter(nil)
+	vals := []float64{1.5, -57344, 0.0625, math.Ldexp(1, -16)}
+	for _, x := range vals {
+		FP8E5M2.Write(w, x)
+	}
+	Float11.Write(w, 65024)
+	r := BigEndian.Field(w.Bytes(), 0, w.Len()).Reader()
+	for _, x := range vals {
+		if y, err := FP8E5M2.Read(r); y != x || err != nil {
+			t.Errorf("FP8E5M2.Read: got %v, %v, want %v", y, err, x)
+		}
+	}
+	if y, err := Float11.Read(r); y != 65024 || err != nil {
+		t.Errorf("Float11.Read: got %v, %v", y, err)
+	}
+	z := Float11.Put(LittleEndian, nil, 3, 0.375)
+	if x := Float11.Get(LittleEndian, z, 3); x != 0.375 || len(z) != 2 {
+		t.Errorf("Float11 Put and Get: got %v in %x", x, z)
+	}
+
+	if panicValue(func() { Minifloat{}.Width() }) != ErrOutOfRange {
+		t.Errorf("Width of invalid format did not panic")
+	}
+}
@@ -0,0 +1,126 @@
+package netbits
+
+import (
+	"net"
+
+	"github.com/bford/bytebits/header"
+)
+
+
+// Bit-field layout of the IPv4 header (RFC 791).
+const (
+	IPv4Version        header.Field = 0 << 8 | 4
+	IPv4IHL            header.Field = 4 << 8 | 4
+	IPv4DSCP           header.Field = 8 << 8 | 6
+	IPv4ECN            header.Field = 14 << 8 | 2
+	IPv4TotalLength    header.Field = 16 << 8 | 16
+	IPv4ID             header.Field = 32 << 8 | 16
+	IPv4Flags          header.Field = 48 << 8 | 3
+	IPv4FragmentOffset header.Field = 51 << 8 | 13
+	IPv4TTL            header.Field = 64 << 8 | 8
+	IPv4Protocol       header.Field = 72 << 8 | 8
+	IPv4Checksum       header.Field = 80 << 8 | 16
+	IPv4Src            header.Field = 96 << 8 | 32
+	IPv4Dst            header.Field = 128 << 8 | 32
+)
+
+// Bits of the IPv4 Flags field.
+const (
+	IPv4FlagMF = 1 << iota		// More Fragments
+	IPv4FlagDF			// Don't Fragment
+	IPv4FlagReserved		// Reserved, must be zero
+)
+
+// IPv4MinLen is the length in bytes of an IPv4 header without options.
+const IPv4MinLen = 20
+
+
+// IPv4 is a byte slice holding an IPv4 header,
+// optionally followed by the packet's payload.
+type IPv4 []byte
+
+func (h IPv4) Version() uint8        { return uint8(IPv4Version.Get(h)) }
+func (h IPv4) IHL() uint8            { return uint8(IPv4IHL.Get(h)) }
+func (h IPv4) DSCP() uint8           { return uint8(IPv4DSCP.Get(h)) }
+func (h IPv4) ECN() uint8            { return uint8(IPv4ECN.Get(h)) }
+func (h IPv4) TotalLength() uint16   { return uint16(IPv4TotalLength.Get(h)) }
+func (h IPv4) ID() uint16            { return uint16(IPv4ID.Get(h)) }
+func (h IPv4) Flags() uint8          { return uint8(IPv4Flags.Get(h)) }
+func (h IPv4) FragmentOffset() uint16 { return uint16(IPv4FragmentOffset.Get(h)) }
+func (h IPv4) TTL() uint8            { return uint8(IPv4TTL.Get(h)) }
+func (h IPv4) Protocol() uint8       { return uint8(IPv4Protocol.Get(h)) }
+func (h IPv4) Checksum() uint16      { return uint16(IPv4Checksum.Get(h)) }
+
+func (h IPv4) SetVersion(v uint8)        { IPv4Version.Put(h, uint64(v)) }
+func (h IPv4) SetIHL(v uint8)            { IPv4IHL.Put(h, uint64(v)) }
+func (h IPv4) SetDSCP(v uint8)           { IPv4DSCP.Put(h, uint64(v)) }
+func (h IPv4) SetECN(v uint8)            { IPv4ECN.Put(h, uint64(v)) }
+func (h IPv4) SetTotalLength(v uint16)   { IPv4TotalLength.Put(h, uint64(v)) }
+func (h IPv4) SetID(v uint16)            { IPv4ID.Put(h, uint64(v)) }
+func (h IPv4) SetFlags(v uint8)          { IPv4Flags.Put(h, uint64(v)) }
+func (h IPv4) SetFragmentOffset(v uint16) { IPv4FragmentOffset.Put(h, uint64(v)) }
+func (h IPv4) SetTTL(v uint8)            { IPv4TTL.Put(h, uint64(v)) }
+func (h IPv4) SetProtocol(v uint8)       { IPv4Protocol.Put(h, uint64(v)) }
+func (h IPv4) SetChecksum(v uint16)      { IPv4Checksum.Put(h, uint64(v)) }
+
+// Src returns the source address, aliasing the header's bytes.
+func (h IPv4) Src() net.IP {
+	return net.IP(h[IPv4Src.Ofs()/8 : (IPv4Src.Ofs()+IPv4Src.Width())/8])
+}
+
+// Dst returns the destination address, aliasing the header's bytes.
+func (h IPv4) Dst() net.IP {
+	return net.IP(h[IPv4Dst.Ofs()/8 : (IPv4Dst.Ofs()+IPv4Dst.Width())/8])
+}
+
+// SetSrc sets the source address, which must be an IPv4 address.
+func (h IPv4) SetSrc(ip net.IP) {
+	copy(h.Src(), ip.To4())
+}
+
+// SetDst sets the destination address, which must be an IPv4 address.
+func (h IPv4) SetDst(ip net.IP) {
+	copy(h.Dst(), ip.To4())
+}
+
+// HeaderLen returns the length of the header in bytes,
+// including any options, as indicated by the IHL field.
+func (h IPv4) HeaderLen() int {
+	return int(h.IHL()) * 4
+}
+
+// Payload returns the packet's payload following the header,
+// limited by the TotalLength field and the length of the slice.
+func (h IPv4) Payload() []byte {
+	end := int(h.TotalLength())
+	if end > len(h) {
+		end = len(h)
+	}
+	return h[h.HeaderLen():end]
+}
+
+// ChecksumValid reports whether the header checksum is correct.
+func (h IPv4) ChecksumValid() bool {
+	return Checksum(h[:h.HeaderLen()]) == 0
+}
+
+// UpdateChecksum recomputes and sets the header checksum.
+func (h IPv4) UpdateChecksum() {
+	h.SetChecksum(0)
+	h.SetChecksum(Checksum(h[:h.HeaderLen()]))
+}
+
+// PseudoHeader returns the pseudo-header that the TCP and UDP checksums
+// of the packet's payload cover, consisting of the source and destination
+// addresses, protocol, and upper-layer length.
+func (h IPv4) PseudoHeader() []byte {
+	ph := make([]byte, 12)
+	copy(ph[0:4], h.Src())
+	copy(ph[4:8], h.Dst())
+	ph[9] = h.Protocol()
+	ipv4PseudoLength.Put(ph, uint64(int(h.TotalLength()) - h.HeaderLen()))
+	return ph
+}
+
+// ipv4PseudoLength is the upper-layer length field of the pseudo-header.
+const ipv4PseudoLength header.Field = 80 << 8 | 16
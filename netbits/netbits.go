@@ -0,0 +1,47 @@
+// Package netbits provides accessors for the fields of IPv4, TCP, and UDP
+// headers held in byte slices, such as packets captured from the wire.
+//
+// The accessors are built entirely on the bit-field operations
+// of the bytebits package, and serve both as a practical utility
+// and as a worked example of describing a packed binary layout
+// as a table of bit-field descriptors.
+// Each header field is described by a constant header.Field
+// giving its bit offset and width within the header;
+// the header types' accessor methods are thin wrappers around these.
+//
+package netbits
+
+import (
+	"github.com/bford/bytebits"
+)
+
+
+// Checksum computes the Internet checksum (RFC 1071)
+// over the byte slices b, treated as one contiguous sequence of bytes,
+// as used in the IPv4, TCP, and UDP headers.
+// A final odd byte is padded on the right with zero bits.
+// Verifying a header or segment that includes a correct checksum
+// yields zero.
+func Checksum(b ...[]byte) uint16 {
+	var sum uint64
+	odd := false		// whether the previous slice ended mid-word
+	for _, s := range b {
+		if odd && len(s) > 0 {
+			sum += uint64(s[0])
+			s = s[1:]
+			odd = false
+		}
+		for len(s) >= 2 {
+			sum += uint64(bytebits.BigEndian.Uint16(s, 0))
+			s = s[2:]
+		}
+		if len(s) > 0 {
+			sum += uint64(s[0]) << 8
+			odd = true
+		}
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,86 @@
+package netbits
+
+import (
+	"net"
+	"testing"
+
+	"github.com/bford/bytebits"
+)
+
+
+// An IPv4/UDP packet carrying a 3-byte payload
+var testPacket = []byte{
+	0x45, 0x00, 0x00, 0x1f, 0x1c, 0x46, 0x40, 0x00,
+	0x40, 0x11, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+	0xc0, 0xa8, 0x00, 0xc7,
+	0x30, 0x39, 0x00, 0x35, 0x00, 0x0b, 0x00, 0x00,
+	'a', 'b', 'c',
+}
+
+func TestIPv4UDP(t *testing.T) {
+	pkt := append([]byte(nil), testPacket...)
+	ip := IPv4(pkt)
+	if ip.Version() != 4 || ip.HeaderLen() != 20 || ip.TotalLength() != 31 {
+		t.Errorf("bad version %v, header length %v, or total length %v",
+			ip.Version(), ip.HeaderLen(), ip.TotalLength())
+	}
+	if ip.Flags() != IPv4FlagDF || ip.FragmentOffset() != 0 {
+		t.Errorf("bad flags %v or fragment offset %v",
+			ip.Flags(), ip.FragmentOffset())
+	}
+	if ip.TTL() != 64 || ip.Protocol() != 17 {
+		t.Errorf("bad TTL %v or protocol %v", ip.TTL(), ip.Protocol())
+	}
+	if !ip.Src().Equal(net.IPv4(192, 168, 0, 1)) ||
+			!ip.Dst().Equal(net.IPv4(192, 168, 0, 199)) {
+		t.Errorf("bad addresses %v -> %v", ip.Src(), ip.Dst())
+	}
+
+	ip.UpdateChecksum()
+	if !ip.ChecksumValid() {
+		t.Errorf("header checksum %x not valid", ip.Checksum())
+	}
+
+	udp := UDP(ip.Payload())
+	if udp.SrcPort() != 12345 || udp.DstPort() != 53 ||
+			string(udp.Payload()) != "abc" {
+		t.Errorf("bad UDP ports %v -> %v or payload %q",
+			udp.SrcPort(), udp.DstPort(), udp.Payload())
+	}
+	udp.UpdateChecksum(ip.PseudoHeader())
+	if udp.Checksum() == 0 || !udp.ChecksumValid(ip.PseudoHeader()) {
+		t.Errorf("UDP checksum %x not valid", udp.Checksum())
+	}
+
+	// Unaligned sub-byte fields should not disturb their neighbors
+	ip.SetDSCP(0x2e)
+	ip.SetECN(1)
+	ip.SetFragmentOffset(0x1234)
+	if pkt[1] != 0xb9 || ip.Flags() != IPv4FlagDF ||
+			ip.FragmentOffset() != 0x1234 || ip.IHL() != 5 {
+		t.Errorf("bad DSCP/ECN byte %x or flags/fragment %v/%x",
+			pkt[1], ip.Flags(), ip.FragmentOffset())
+	}
+}
+
+func TestTCPFlags(t *testing.T) {
+	h := make(TCP, TCPMinLen)
+	h.SetDataOffset(5)
+	h.SetFlags(TCPFlagSYN | TCPFlagACK | TCPFlagNS)
+	if h[12] != 0x51 || h[13] != 0x12 {
+		t.Errorf("bad offset/flags bytes %x %x", h[12], h[13])
+	}
+	if !h.HasFlags(TCPFlagSYN|TCPFlagACK) || h.HasFlags(TCPFlagFIN) {
+		t.Errorf("bad flags %x", h.Flags())
+	}
+}
+
+func TestShortHeader(t *testing.T) {
+	defer func() {
+		if v := recover(); v != bytebits.ErrShortBuffer {
+			t.Errorf("short header put panicked with %v", v)
+		}
+	}()
+	h := make(TCP, TCPMinLen-1)
+	h.SetUrgent(1)
+}
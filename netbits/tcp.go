@@ -0,0 +1,90 @@
+package netbits
+
+import (
+	"github.com/bford/bytebits/header"
+)
+
+
+// Bit-field layout of the TCP header (RFC 793, RFC 3168).
+const (
+	TCPSrcPort    header.Field = 0 << 8 | 16
+	TCPDstPort    header.Field = 16 << 8 | 16
+	TCPSeq        header.Field = 32 << 8 | 32
+	TCPAck        header.Field = 64 << 8 | 32
+	TCPDataOffset header.Field = 96 << 8 | 4
+	TCPReserved   header.Field = 100 << 8 | 3
+	TCPFlags      header.Field = 103 << 8 | 9
+	TCPWindow     header.Field = 112 << 8 | 16
+	TCPChecksum   header.Field = 128 << 8 | 16
+	TCPUrgent     header.Field = 144 << 8 | 16
+)
+
+// Bits of the TCP Flags field.
+const (
+	TCPFlagFIN = 1 << iota
+	TCPFlagSYN
+	TCPFlagRST
+	TCPFlagPSH
+	TCPFlagACK
+	TCPFlagURG
+	TCPFlagECE
+	TCPFlagCWR
+	TCPFlagNS
+)
+
+// TCPMinLen is the length in bytes of a TCP header without options.
+const TCPMinLen = 20
+
+
+// TCP is a byte slice holding a TCP header,
+// optionally followed by the segment's payload.
+type TCP []byte
+
+func (h TCP) SrcPort() uint16    { return uint16(TCPSrcPort.Get(h)) }
+func (h TCP) DstPort() uint16    { return uint16(TCPDstPort.Get(h)) }
+func (h TCP) Seq() uint32        { return uint32(TCPSeq.Get(h)) }
+func (h TCP) Ack() uint32        { return uint32(TCPAck.Get(h)) }
+func (h TCP) DataOffset() uint8  { return uint8(TCPDataOffset.Get(h)) }
+func (h TCP) Flags() uint16      { return uint16(TCPFlags.Get(h)) }
+func (h TCP) Window() uint16     { return uint16(TCPWindow.Get(h)) }
+func (h TCP) Checksum() uint16   { return uint16(TCPChecksum.Get(h)) }
+func (h TCP) Urgent() uint16     { return uint16(TCPUrgent.Get(h)) }
+
+func (h TCP) SetSrcPort(v uint16)   { TCPSrcPort.Put(h, uint64(v)) }
+func (h TCP) SetDstPort(v uint16)   { TCPDstPort.Put(h, uint64(v)) }
+func (h TCP) SetSeq(v uint32)       { TCPSeq.Put(h, uint64(v)) }
+func (h TCP) SetAck(v uint32)       { TCPAck.Put(h, uint64(v)) }
+func (h TCP) SetDataOffset(v uint8) { TCPDataOffset.Put(h, uint64(v)) }
+func (h TCP) SetFlags(v uint16)     { TCPFlags.Put(h, uint64(v)) }
+func (h TCP) SetWindow(v uint16)    { TCPWindow.Put(h, uint64(v)) }
+func (h TCP) SetChecksum(v uint16)  { TCPChecksum.Put(h, uint64(v)) }
+func (h TCP) SetUrgent(v uint16)    { TCPUrgent.Put(h, uint64(v)) }
+
+// HasFlags reports whether all the flag bits in mask are set.
+func (h TCP) HasFlags(mask uint16) bool {
+	return h.Flags() & mask == mask
+}
+
+// HeaderLen returns the length of the header in bytes,
+// including any options, as indicated by the DataOffset field.
+func (h TCP) HeaderLen() int {
+	return int(h.DataOffset()) * 4
+}
+
+// Payload returns the segment's payload following the header.
+func (h TCP) Payload() []byte {
+	return h[h.HeaderLen():]
+}
+
+// ChecksumValid reports whether the checksum of the complete segment h
+// is correct, given the pseudo-header from the enclosing IP packet.
+func (h TCP) ChecksumValid(pseudo []byte) bool {
+	return Checksum(pseudo, h) == 0
+}
+
+// UpdateChecksum recomputes and sets the checksum of the complete segment h,
+// given the pseudo-header from the enclosing IP packet.
+func (h TCP) UpdateChecksum(pseudo []byte) {
+	h.SetChecksum(0)
+	h.SetChecksum(Checksum(pseudo, h))
+}
@@ -0,0 +1,62 @@
+package netbits
+
+import (
+	"github.com/bford/bytebits/header"
+)
+
+
+// Bit-field layout of the UDP header (RFC 768).
+const (
+	UDPSrcPort  header.Field = 0 << 8 | 16
+	UDPDstPort  header.Field = 16 << 8 | 16
+	UDPLength   header.Field = 32 << 8 | 16
+	UDPChecksum header.Field = 48 << 8 | 16
+)
+
+// UDPLen is the length in bytes of a UDP header.
+const UDPLen = 8
+
+
+// UDP is a byte slice holding a UDP header,
+// optionally followed by the datagram's payload.
+type UDP []byte
+
+func (h UDP) SrcPort() uint16  { return uint16(UDPSrcPort.Get(h)) }
+func (h UDP) DstPort() uint16  { return uint16(UDPDstPort.Get(h)) }
+func (h UDP) Length() uint16   { return uint16(UDPLength.Get(h)) }
+func (h UDP) Checksum() uint16 { return uint16(UDPChecksum.Get(h)) }
+
+func (h UDP) SetSrcPort(v uint16)  { UDPSrcPort.Put(h, uint64(v)) }
+func (h UDP) SetDstPort(v uint16)  { UDPDstPort.Put(h, uint64(v)) }
+func (h UDP) SetLength(v uint16)   { UDPLength.Put(h, uint64(v)) }
+func (h UDP) SetChecksum(v uint16) { UDPChecksum.Put(h, uint64(v)) }
+
+// Payload returns the datagram's payload following the header,
+// limited by the Length field and the length of the slice.
+func (h UDP) Payload() []byte {
+	end := int(h.Length())
+	if end > len(h) {
+		end = len(h)
+	}
+	return h[UDPLen:end]
+}
+
+// ChecksumValid reports whether the checksum of the complete datagram h
+// is correct, given the pseudo-header from the enclosing IP packet.
+// A zero checksum indicates that the sender did not compute one,
+// and is always considered valid.
+func (h UDP) ChecksumValid(pseudo []byte) bool {
+	return h.Checksum() == 0 || Checksum(pseudo, h) == 0
+}
+
+// UpdateChecksum recomputes and sets the checksum of the complete datagram h,
+// given the pseudo-header from the enclosing IP packet.
+// A computed checksum of zero is transmitted as all ones.
+func (h UDP) UpdateChecksum(pseudo []byte) {
+	h.SetChecksum(0)
+	c := Checksum(pseudo, h)
+	if c == 0 {
+		c = 0xffff
+	}
+	h.SetChecksum(c)
+}
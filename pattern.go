@@ -0,0 +1,44 @@
+package bytebits
+
+
+// EvenlySpaced sets the n-bit field starting at offset zofs in z,
+// in big-endian bit order, to a pattern of k one bits
+// distributed as evenly as possible among the n positions,
+// and returns z.
+// The pattern is the one Bresenham's line algorithm produces,
+// equivalent to a Euclidean rhythm: it starts with a one bit,
+// and the gaps between successive ones differ in length by at most one,
+// as needed for duty-cycle patterns and credit-based schedulers.
+// For example, 3 ones among 8 positions yields 10010010.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+// Panics with ErrOutOfRange if k is negative or greater than n.
+//
+func EvenlySpaced(z []byte, zofs, n, k int) []byte {
+	if k < 0 || k > n {
+		panic(ErrOutOfRange)
+	}
+	z, zb, zo := beGrow(z, zofs, n)
+
+	acc := n - k		// Bresenham error accumulator
+	if k == 0 {
+		acc = 0		// never reaches n: all zeros
+	}
+	for rem := n; rem > 0; {
+		w := rem
+		if w > 64 {
+			w = 64
+		}
+		var v uint64
+		for i := 0; i < w; i++ {
+			v <<= 1
+			if acc += k; acc >= n {
+				acc -= n
+				v |= 1
+			}
+		}
+		zb, zo = bePut(zb, zo, w, v)
+		rem -= w
+	}
+	return z
+}
@@ -0,0 +1,53 @@
+package bytebits
+
+import (
+	"testing"
+)
+
+
+func TestEvenlySpaced(t *testing.T) {
+	if got := EvenlySpaced(nil, 0, 8, 3); got[0] != 0x92 {	// 10010010
+		t.Errorf("EvenlySpaced(8, 3): got %08b, want 10010010", got[0])
+	}
+
+	for _, n := range []int{1, 7, 64, 65, 200} {
+		for _, k := range []int{0, 1, 2, n/3, n/2, n-1, n} {
+			if k > n {
+				continue
+			}
+			z := EvenlySpaced([]byte{0xff}, 3, n, k)
+			if BigEndian.Uint(z, 0, 3) != 7 {
+				t.Errorf("n=%v k=%v: preceding bits clobbered", n, k)
+			}
+
+			// Gaps between successive ones differ by at most one
+			ones, min, max, last := 0, n, 0, -1
+			for i := 0; i < n; i++ {
+				if BigEndian.Bit(z, 3 + i) == 0 {
+					continue
+				}
+				ones++
+				if last >= 0 {
+					g := i - last
+					if g < min {
+						min = g
+					}
+					if g > max {
+						max = g
+					}
+				}
+				last = i
+			}
+			if ones != k {
+				t.Errorf("n=%v k=%v: %v ones", n, k, ones)
+			}
+			if k > 0 && BigEndian.Bit(z, 3) != 1 {
+				t.Errorf("n=%v k=%v: does not start with one", n, k)
+			}
+			if k > 1 && max > min + 1 {
+				t.Errorf("n=%v k=%v: gaps from %v to %v",
+					n, k, min, max)
+			}
+		}
+	}
+}
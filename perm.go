@@ -0,0 +1,136 @@
+package bytebits
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+
+// Permutation is an arbitrary permutation of the bits of an n-bit vector
+// in big-endian bit order, compiled into a plan
+// that applies it efficiently to many buffers.
+//
+// Compilation groups together all the bits that move
+// from the same 64-bit source word to the same 64-bit destination word
+// by the same distance, so that each group moves with a single
+// masked shift.  Permutations with regular structure,
+// such as those in block ciphers or scan-chain reorderings,
+// typically compile to far fewer steps than there are bits.
+//
+// A Permutation is immutable once compiled
+// and may be used concurrently from multiple goroutines.
+//
+type Permutation struct {
+	n int
+	ops []permOp
+}
+
+// permOp moves the bits selected by mask from source word src
+// to destination word dst, shifting them left by shift bits,
+// or right by -shift bits if shift is negative.
+type permOp struct {
+	dst, src int
+	shift int
+	mask uint64
+}
+
+// NewPermutation compiles a Permutation of len(perm) bits,
+// which moves bit perm[i] of the source vector to bit i of the destination.
+// Returns ErrOutOfRange if perm is not a permutation of 0 to len(perm)-1.
+func NewPermutation(perm []int) (*Permutation, error) {
+	n := len(perm)
+	seen := make([]bool, n)
+	masks := make(map[permOp]uint64)
+	for q, p := range perm {
+		if p < 0 || p >= n || seen[p] {
+			return nil, ErrOutOfRange
+		}
+		seen[p] = true
+		k := permOp{dst: q >> 6, src: p >> 6, shift: p & 63 - q & 63}
+		masks[k] |= 1 << (63 - p & 63)
+	}
+
+	ops := make([]permOp, 0, len(masks))
+	for k, m := range masks {
+		k.mask = m
+		ops = append(ops, k)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		a, b := ops[i], ops[j]
+		if a.dst != b.dst {
+			return a.dst < b.dst
+		}
+		if a.src != b.src {
+			return a.src < b.src
+		}
+		return a.shift < b.shift
+	})
+	return &Permutation{n, ops}, nil
+}
+
+// Len returns the number of bits p permutes.
+func (p *Permutation) Len() int {
+	return p.n
+}
+
+// Steps returns the number of masked-shift steps in p's compiled plan,
+// as a measure of the cost of applying it.
+func (p *Permutation) Steps() int {
+	return len(p.ops)
+}
+
+// Apply permutes the first Len bits of slice x
+// into the first Len bits of slice z, and returns z.
+// Slices z and x must not overlap.
+// Copies z and returns a new slice if z is nil or not large enough.
+// All other bits within z are left unmodified.
+// Panics with ErrShortBuffer if x holds fewer than Len bits.
+func (p *Permutation) Apply(z, x []byte) []byte {
+	nb := (p.n + 7) >> 3
+	if len(x) < nb {
+		panic(ErrShortBuffer)
+	}
+	z = Grow(z, nb)
+	x = x[:nb]
+
+	dw, v := 0, uint64(0)
+	for _, op := range p.ops {
+		if op.dst != dw {
+			permStore(z[:nb], dw, v, p.n)
+			dw, v = op.dst, 0
+		}
+		s := permLoad(x, op.src) & op.mask
+		if op.shift >= 0 {
+			v |= s << op.shift
+		} else {
+			v |= s >> -op.shift
+		}
+	}
+	if p.n > 0 {
+		permStore(z[:nb], dw, v, p.n)
+	}
+	return z
+}
+
+// permLoad loads 64-bit word i of x, padded with zero bytes if necessary.
+func permLoad(x []byte, i int) uint64 {
+	x = x[i << 3:]
+	if len(x) >= 8 {
+		return binary.BigEndian.Uint64(x)
+	}
+	var t [8]byte
+	copy(t[:], x)
+	return binary.BigEndian.Uint64(t[:])
+}
+
+// permStore stores v into 64-bit word i of z,
+// truncated to the first n bits of z.
+func permStore(z []byte, i int, v uint64, n int) {
+	o := i << 6
+	w := n - o
+	if w >= 64 {
+		binary.BigEndian.PutUint64(z[i << 3:], v)
+		return
+	}
+	bePutN(z[i << 3:], 0, w, v >> (64 - w))
+}
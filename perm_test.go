@@ -0,0 +1,50 @@
+package bytebits
+
+import (
+	"math/rand"
+	"testing"
+)
+
+
+func TestPermutation(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 64, 100, 333} {
+		perm := rand.Perm(n)
+		p, err := NewPermutation(perm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := make([]byte, (n + 7) >> 3)
+		rand.Read(x)
+		z := make([]byte, len(x) + 1)
+		for i := range z {
+			z[i] = 0xff
+		}
+		z = p.Apply(z, x)
+		for i, src := range perm {
+			if got, want := BigEndian.Bit(z, i), BigEndian.Bit(x, src);
+					got != want {
+				t.Errorf("n=%v bit %v: got %v, want %v",
+					n, i, got, want)
+			}
+		}
+		if BigEndian.Trailing(z, 1) < len(z)*8 - n {
+			t.Errorf("n=%v: bits past the permutation clobbered", n)
+		}
+	}
+
+	// Reversing the bits within each byte moves every bit
+	// by one of only 8 distances within the same word.
+	perm := make([]int, 256)
+	for i := range perm {
+		perm[i] = i ^ 7
+	}
+	p, _ := NewPermutation(perm)
+	if p.Steps() != 4 * 8 {
+		t.Errorf("byte reversal compiled to %v steps, want 32", p.Steps())
+	}
+
+	if _, err := NewPermutation([]int{0, 2, 2}); err != ErrOutOfRange {
+		t.Errorf("NewPermutation of non-permutation: got %v, want %v",
+			err, ErrOutOfRange)
+	}
+}
@@ -0,0 +1,247 @@
+package bytebits
+
+import (
+	"math"
+	"math/bits"
+)
+
+
+// The randomness test functions compute basic statistics
+// over the w-bit field starting at offset xofs in x, in big-endian bit order,
+// following the definitions of the frequency (monobit), runs, and serial tests
+// of NIST Special Publication 800-22,
+// for validating the output of random number generators.
+// Tests returning a P-value indicate nonrandomness if it is small,
+// typically below 0.01.
+
+// MonobitTest performs the frequency (monobit) test,
+// returning the difference between the numbers of one and zero bits,
+// and the P-value of the test.
+// The P-value is zero for an empty field.
+func MonobitTest(x []byte, xofs, w int) (sum int, p float64) {
+	if w == 0 {
+		return 0, 0
+	}
+	ones := 0
+	beWords(x, xofs, w, func(v uint64, _ int) {
+		ones += bits.OnesCount64(v)
+	})
+	sum = 2 * ones - w
+	s := math.Abs(float64(sum)) / math.Sqrt(float64(w))
+	return sum, math.Erfc(s / math.Sqrt2)
+}
+
+// RunsTest performs the runs test,
+// returning the total number of runs of identical bits in the field
+// and the P-value of the test.
+// The P-value is zero if the proportion of one bits is too far from 1/2
+// for the runs test to be applicable.
+func RunsTest(x []byte, xofs, w int) (runs int, p float64) {
+	if w == 0 {
+		return 0, 0
+	}
+	ones, trans := 0, 0
+	xb, xo := beNorm(x, xofs)
+	last := uint64(BigEndian.Bit(x, xofs))
+	for n := w; n > 0; {
+		k := n
+		if k > 64 {
+			k = 64
+		}
+		var v uint64
+		xb, xo, v = beGet(xb, xo, k)
+		ones += bits.OnesCount64(v)
+		trans += bits.OnesCount64((v ^ (v >> 1 | last << (k-1))) &
+					(^uint64(0) >> (64 - k)))
+		last = v & 1
+		n -= k
+	}
+	runs = trans + 1
+
+	nf := float64(w)
+	pi := float64(ones) / nf
+	if math.Abs(pi - 0.5) >= 2 / math.Sqrt(nf) {
+		return runs, 0
+	}
+	t := 2 * nf * pi * (1 - pi)
+	return runs, math.Erfc(math.Abs(float64(runs) - t) /
+				(2 * math.Sqrt(2 * nf) * pi * (1 - pi)))
+}
+
+// LongestRun returns the length of the longest run
+// of consecutive bits with value b (0 or 1) in the field.
+// Panics with ErrBadBitValue if b is not 0 or 1.
+func LongestRun(x []byte, xofs, w int, b uint) int {
+	if b > 1 {
+		panic(ErrBadBitValue)
+	}
+	flip := uint64(b) - 1		// all ones to count runs of zeros
+	max, cur := 0, 0
+	beWords(x, xofs, w, func(v uint64, _ int) {
+		k := w
+		if k > 64 {
+			k = 64
+		}
+		w -= k
+		v ^= flip
+		for k > 0 {
+			l := bits.LeadingZeros64(^v)	// run of b continuing
+			if l >= k {
+				cur += k
+				break
+			}
+			cur += l
+			if cur > max {
+				max = cur
+			}
+			cur = 0
+			s := l + 1 + bits.LeadingZeros64(v << (l + 1))
+			if s >= k {
+				break
+			}
+			v <<= s
+			k -= s
+		}
+	})
+	if cur > max {
+		max = cur
+	}
+	return max
+}
+
+// SerialTest performs the serial test on the frequencies
+// of all overlapping m-bit patterns in the field,
+// wrapping around from its end to its beginning,
+// where m is from 2 to 16, and returns the test's two P-values.
+// With m = 2 the test examines the frequencies of overlapping bit pairs.
+// Panics with ErrOutOfRange if m is not in the range 2 to 16,
+// or exceeds the field's width.
+func SerialTest(x []byte, xofs, w, m int) (p1, p2 float64) {
+	if m < 2 || m > 16 || m > w {
+		panic(ErrOutOfRange)
+	}
+
+	// Count m-bit patterns in the field extended by its first m-1 bits
+	aug := BigEndian.Copy(nil, x, 0, xofs, w)
+	aug = BigEndian.Copy(aug, x, w, xofs, m - 1)
+	counts := make([]int, 1 << m)
+	mask := 1 << m - 1
+	pat := int(BigEndian.Uint(aug, 0, m - 1))
+	rem := w
+	beWords(aug, m - 1, w, func(v uint64, _ int) {
+		for i := 0; i < 64 && rem > 0; i++ {
+			pat = (pat << 1 | int(v >> 63)) & mask
+			v <<= 1
+			counts[pat]++
+			rem--
+		}
+	})
+
+	psi := func(c []int) float64 {
+		var s float64
+		for _, v := range c {
+			s += float64(v) * float64(v)
+		}
+		return s * float64(len(c)) / float64(w) - float64(w)
+	}
+	psim := psi(counts)
+	for i := range counts[:1 << (m-1)] {	// marginal (m-1)-bit counts
+		counts[i] = counts[2*i] + counts[2*i+1]
+	}
+	psim1 := psi(counts[:1 << (m-1)])
+	psim2 := 0.0
+	if m > 2 {
+		for i := range counts[:1 << (m-2)] {
+			counts[i] = counts[2*i] + counts[2*i+1]
+		}
+		psim2 = psi(counts[:1 << (m-2)])
+	}
+
+	d1 := psim - psim1
+	d2 := psim - 2 * psim1 + psim2
+	return igamc(math.Ldexp(1, m-2), d1 / 2), igamc(math.Ldexp(1, m-3), d2 / 2)
+}
+
+
+// Constants for the incomplete gamma function, following Cephes.
+const (
+	igamEps = 1.11022302462515654042e-16
+	igamBig = 4.503599627370496e15
+	igamBigInv = 2.22044604925031308085e-16
+)
+
+// igam returns the regularized lower incomplete gamma function P(a, x).
+func igam(a, x float64) float64 {
+	if x <= 0 || a <= 0 {
+		return 0
+	}
+	if x > 1 && x > a {
+		return 1 - igamc(a, x)
+	}
+	lg, _ := math.Lgamma(a)
+	ax := a * math.Log(x) - x - lg
+	if ax < -709.78 {
+		return 0
+	}
+	ax = math.Exp(ax)
+
+	// Power series
+	r, c, sum := a, 1.0, 1.0
+	for c / sum > igamEps {
+		r++
+		c *= x / r
+		sum += c
+	}
+	return sum * ax / a
+}
+
+// igamc returns the regularized upper incomplete gamma function Q(a, x),
+// the complement of igam, as used for chi-squared P-values.
+func igamc(a, x float64) float64 {
+	if x <= 0 || a <= 0 {
+		return 1
+	}
+	if x < 1 || x < a {
+		return 1 - igam(a, x)
+	}
+	lg, _ := math.Lgamma(a)
+	ax := a * math.Log(x) - x - lg
+	if ax < -709.78 {
+		return 0
+	}
+	ax = math.Exp(ax)
+
+	// Continued fraction
+	y := 1 - a
+	z := x + y + 1
+	c := 0.0
+	pkm2, qkm2 := 1.0, x
+	pkm1, qkm1 := x + 1, z * x
+	ans := pkm1 / qkm1
+	for {
+		c++
+		y++
+		z += 2
+		yc := y * c
+		pk := pkm1 * z - pkm2 * yc
+		qk := qkm1 * z - qkm2 * yc
+		t := 1.0
+		if qk != 0 {
+			r := pk / qk
+			t = math.Abs((ans - r) / r)
+			ans = r
+		}
+		pkm2, pkm1 = pkm1, pk
+		qkm2, qkm1 = qkm1, qk
+		if math.Abs(pk) > igamBig {
+			pkm2 *= igamBigInv
+			pkm1 *= igamBigInv
+			qkm2 *= igamBigInv
+			qkm1 *= igamBigInv
+		}
+		if t <= igamEps {
+			break
+		}
+	}
+	return ans * ax
+}
@@ -0,0 +1,69 @@
+package bytebits
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+
+// bitsOf parses a string of '0' and '1' characters into a bit string.
+func bitsOf(s string) []byte {
+	b := make([]byte, (len(s) + 7) >> 3)
+	for i, c := range s {
+		b = BigEndian.PutBit(b, i, uint(c - '0'))
+	}
+	return b
+}
+
+func TestRandomnessTests(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a - b) < 1e-6 }
+
+	// Examples from NIST SP 800-22 rev 1a
+	if s, p := MonobitTest(bitsOf("1011010101"), 0, 10); s != 2 ||
+			!near(p, 0.527089) {
+		t.Errorf("MonobitTest: got %v, %v", s, p)
+	}
+	if r, p := RunsTest(bitsOf("1001101011"), 0, 10); r != 7 ||
+			!near(p, 0.147232) {
+		t.Errorf("RunsTest: got %v, %v", r, p)
+	}
+	if s, p := MonobitTest(nil, 0, 0); s != 0 || p != 0 {
+		t.Errorf("MonobitTest of empty field: got %v, %v", s, p)
+	}
+	if r, p := RunsTest(nil, 0, 0); r != 0 || p != 0 {
+		t.Errorf("RunsTest of empty field: got %v, %v", r, p)
+	}
+	if p1, p2 := SerialTest(bitsOf("0011011101"), 0, 10, 3);
+			!near(p1, 0.808792) || !near(p2, 0.670320) {
+		t.Errorf("SerialTest: got %v, %v", p1, p2)
+	}
+
+	// Compare statistics of longer unaligned fields with bitwise counts
+	x := make([]byte, 40)
+	rand.Read(x)
+	x[5], x[6], x[7] = 0xff, 0xff, 0xf0
+	for _, w := range []int{1, 63, 64, 65, 300} {
+		runs, longest := 1, [2]int{}
+		cur := 0
+		for i := 0; i < w; i++ {
+			if i > 0 && BigEndian.Bit(x, 3+i) != BigEndian.Bit(x, 2+i) {
+				runs++
+				cur = 0
+			}
+			cur++
+			if b := BigEndian.Bit(x, 3+i); cur > longest[b] {
+				longest[b] = cur
+			}
+		}
+		if r, _ := RunsTest(x, 3, w); r != runs {
+			t.Errorf("RunsTest w=%v: got %v runs, want %v", w, r, runs)
+		}
+		for b := uint(0); b < 2; b++ {
+			if l := LongestRun(x, 3, w, b); l != longest[b] {
+				t.Errorf("LongestRun w=%v b=%v: got %v, want %v",
+					w, b, l, longest[b])
+			}
+		}
+	}
+}
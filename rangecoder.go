@@ -0,0 +1,246 @@
+package bytebits
+
+
+// The range coder is a binary arithmetic coder
+// in the style of the classic Witten-Neal-Cleary coder,
+// renormalizing a bit at a time through a BitWriter or BitReader,
+// with each bit coded under a probability supplied by a BitModel.
+// It is intended as a building block for experiments
+// with CABAC-like and other adaptive entropy coders.
+
+const (
+	rcHalf = 1 << 31
+	rcQuarter = 1 << 30
+)
+
+// BitModel supplies the probability with which a binary range coder
+// expects the next bit to be zero, and learns from each coded bit.
+type BitModel interface {
+	// P0 returns the probability that the next bit is 0,
+	// in units of 1/65536, from 1 to 65535.
+	P0() uint16
+
+	// Update adapts the model after coding bit, 0 or 1.
+	Update(bit uint)
+}
+
+// FixedBitModel is a BitModel whose probability of a zero bit,
+// in units of 1/65536, never changes.
+type FixedBitModel uint16
+
+func (m FixedBitModel) P0() uint16 {
+	return uint16(m)
+}
+
+func (m FixedBitModel) Update(bit uint) {
+}
+
+// AdaptiveBitModel is a BitModel that tracks the probability
+// of a zero bit with an exponentially decaying average,
+// as the adaptive contexts of LZMA do.
+// Each coded bit moves the probability 1/2^Shift of the way
+// toward certainty of that bit.
+// The zero value is ready to use, starting from a probability of 1/2
+// with a Shift of 5.
+type AdaptiveBitModel struct {
+	p uint16		// Probability of 0, or 0 for 1/2 initially
+	Shift uint		// Adaptation rate, or 0 for 5
+}
+
+const minP0 = 32		// Keep probabilities away from certainty
+
+func (m *AdaptiveBitModel) P0() uint16 {
+	if m.p == 0 {
+		return 1 << 15
+	}
+	return m.p
+}
+
+func (m *AdaptiveBitModel) Update(bit uint) {
+	p, s := uint32(m.P0()), m.Shift
+	if s == 0 {
+		s = 5
+	}
+	if bit == 0 {
+		p += (1 << 16 - p) >> s
+	} else {
+		p -= p >> s
+	}
+	if p < minP0 {
+		p = minP0
+	} else if p > 1 << 16 - minP0 {
+		p = 1 << 16 - minP0
+	}
+	m.p = uint16(p)
+}
+
+// rcSplit returns the last value of the interval [low, high]
+// assigned to a zero bit of probability p0.
+func rcSplit(low, high uint32, p0 uint16) uint32 {
+	r := uint64(high - low) + 1
+	return low + uint32(r * uint64(p0) >> 16) - 1
+}
+
+
+// RangeEncoder encodes bits with a binary range coder,
+// writing its output to a BitWriter.
+// After encoding all bits, the client must call Close
+// to write the final bits needed to decode them.
+type RangeEncoder struct {
+	w BitWriter
+	low, high uint32	// Current coding interval
+	pending int		// Opposite bits owed after the next bit
+	err error		// Sticky write error
+}
+
+// NewRangeEncoder returns a RangeEncoder writing to w.
+func NewRangeEncoder(w BitWriter) *RangeEncoder {
+	return &RangeEncoder{w: w, high: ^uint32(0)}
+}
+
+// Encode encodes bit, 0 or 1, with the probability given by model m,
+// then updates m with the bit.
+// Returns any error encountered writing to the underlying writer,
+// after which further calls fail with the same error,
+// or ErrClosed after Close.
+// Panics with ErrOutOfRange if m's probability is zero.
+func (e *RangeEncoder) Encode(bit uint, m BitModel) error {
+	if e.err != nil {
+		return e.err
+	}
+	p0 := m.P0()
+	if p0 == 0 {
+		panic(ErrOutOfRange)
+	}
+	split := rcSplit(e.low, e.high, p0)
+	if bit == 0 {
+		e.high = split
+	} else {
+		e.low = split + 1
+	}
+	m.Update(bit)
+	for e.err == nil {
+		switch {
+		case e.high < rcHalf:
+			e.emit(0)
+		case e.low >= rcHalf:
+			e.emit(1)
+		case e.low >= rcQuarter && e.high < rcHalf + rcQuarter:
+			e.pending++
+			e.low -= rcQuarter
+			e.high -= rcQuarter
+		default:
+			return nil
+		}
+		e.low <<= 1
+		e.high = e.high << 1 | 1
+	}
+	return e.err
+}
+
+// emit writes bit followed by any pending opposite bits.
+func (e *RangeEncoder) emit(bit uint64) {
+	if e.err = e.w.WriteBits(1, bit); e.err != nil {
+		return
+	}
+	for ; e.pending > 0 && e.err == nil; e.pending-- {
+		e.err = e.w.WriteBits(1, bit ^ 1)
+	}
+}
+
+// Close writes the final bits needed to decode all bits encoded.
+// Close does not flush or close the underlying writer.
+func (e *RangeEncoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.pending++
+	if e.low < rcQuarter {
+		e.emit(0)
+	} else {
+		e.emit(1)
+	}
+	if e.err == nil {
+		e.err = ErrClosed
+		return nil
+	}
+	return e.err
+}
+
+
+// RangeDecoder decodes bits encoded by a RangeEncoder
+// from a BitReader.
+// The decoder reads up to 32 bits ahead of the encoded bits,
+// treating bits past the end of the stream as zero,
+// so the encoded data may be followed by other data
+// only if the client can locate that data independently.
+type RangeDecoder struct {
+	r BitReader
+	low, high uint32	// Current coding interval
+	value uint32		// Current 32-bit window of the code
+	err error		// Sticky read error other than EOF
+}
+
+// NewRangeDecoder returns a RangeDecoder reading from r.
+// Returns any error other than EOF encountered reading the first bits.
+func NewRangeDecoder(r BitReader) (*RangeDecoder, error) {
+	d := &RangeDecoder{r: r, high: ^uint32(0)}
+	for i := 0; i < 32; i++ {
+		d.value = d.value << 1 | d.next()
+	}
+	return d, d.err
+}
+
+// next reads the next bit of the code, or zero past its end.
+func (d *RangeDecoder) next() uint32 {
+	if d.err != nil {
+		return 0
+	}
+	b, err := d.r.ReadBits(1)
+	if err != nil {
+		if err != EOF {
+			d.err = err
+		}
+		return 0
+	}
+	return uint32(b)
+}
+
+// Decode decodes a bit with the probability given by model m,
+// which must be in the same state as the model used to encode it,
+// then updates m with the bit.
+// Returns any error other than EOF encountered reading the code.
+// Panics with ErrOutOfRange if m's probability is zero.
+func (d *RangeDecoder) Decode(m BitModel) (uint, error) {
+	p0 := m.P0()
+	if p0 == 0 {
+		panic(ErrOutOfRange)
+	}
+	var bit uint
+	split := rcSplit(d.low, d.high, p0)
+	if d.value <= split {
+		d.high = split
+	} else {
+		d.low = split + 1
+		bit = 1
+	}
+	m.Update(bit)
+	for {
+		switch {
+		case d.high < rcHalf:
+		case d.low >= rcHalf:
+			d.low -= rcHalf
+			d.high -= rcHalf
+			d.value -= rcHalf
+		case d.low >= rcQuarter && d.high < rcHalf + rcQuarter:
+			d.low -= rcQuarter
+			d.high -= rcQuarter
+			d.value -= rcQuarter
+		default:
+			return bit, d.err
+		}
+		d.low <<= 1
+		d.high = d.high << 1 | 1
+		d.value = d.value << 1 | d.next()
+	}
+}
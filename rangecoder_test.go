@@ -0,0 +1,72 @@
+package bytebits
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+
+func TestRangeCoder(t *testing.T) {
+	// Skewed bits whose probability depends on the previous bit
+	const n = 20000
+	in := make([]uint, n)
+	for i := 1; i < n; i++ {
+		p := 0.05
+		if in[i-1] == 1 {
+			p = 0.8
+		}
+		if rand.Float64() < p {
+			in[i] = 1
+		}
+	}
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	e := NewRangeEncoder(w)
+	var enc [2]AdaptiveBitModel
+	fixed := FixedBitModel(60000)
+	for i, b := range in {
+		ctx := uint(0)
+		if i > 0 {
+			ctx = in[i-1]
+		}
+		if err := e.Encode(b, &enc[ctx]); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		if i % 100 == 0 {
+			e.Encode(b, fixed)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := e.Encode(0, fixed); err != ErrClosed {
+		t.Errorf("Encode after Close: got %v", err)
+	}
+	w.Flush()
+	if out.Len() * 8 > n / 2 {
+		t.Errorf("range coder produced %v bits for %v skewed bits",
+			out.Len() * 8, n)
+	}
+
+	d, err := NewRangeDecoder(NewReader(&out))
+	if err != nil {
+		t.Fatalf("NewRangeDecoder: %v", err)
+	}
+	var dec [2]AdaptiveBitModel
+	for i, want := range in {
+		ctx := uint(0)
+		if i > 0 {
+			ctx = in[i-1]
+		}
+		if b, err := d.Decode(&dec[ctx]); b != want || err != nil {
+			t.Fatalf("Decode %v: got %v, %v, want %v", i, b, err, want)
+		}
+		if i % 100 == 0 {
+			if b, _ := d.Decode(fixed); b != want {
+				t.Fatalf("Decode %v with fixed model: got %v", i, b)
+			}
+		}
+	}
+}
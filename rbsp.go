@@ -0,0 +1,117 @@
+package bytebits
+
+import (
+	"io"
+)
+
+
+// RBSPReader is an io.Reader that removes the emulation prevention bytes
+// that H.264 and HEVC insert into NAL unit payloads,
+// recovering the raw byte sequence payload (RBSP) for a bit-level parser.
+// Wherever two zero bytes are followed by a 0x03 byte,
+// the RBSPReader drops the 0x03.
+// To parse a NAL unit payload, layer a Reader on top of an RBSPReader:
+//
+//	r := bytebits.NewReader(bytebits.NewRBSPReader(nal))
+//
+type RBSPReader struct {
+	rd io.Reader		// Underlying escaped byte stream
+	zeros int		// Number of consecutive zero bytes just read
+}
+
+// NewRBSPReader returns an RBSPReader reading escaped bytes from rd.
+func NewRBSPReader(rd io.Reader) *RBSPReader {
+	return &RBSPReader{rd: rd}
+}
+
+// Read implements the io.Reader interface,
+// reading escaped bytes from the underlying reader into p
+// and returning the number of bytes left after unescaping.
+func (e *RBSPReader) Read(p []byte) (n int, err error) {
+	for n == 0 && err == nil && len(p) > 0 {
+		var m int
+		m, err = e.rd.Read(p)
+		for _, b := range p[:m] {
+			if e.zeros >= 2 && b == 0x03 {
+				e.zeros = 0
+				continue
+			}
+			if b == 0 {
+				e.zeros++
+			} else {
+				e.zeros = 0
+			}
+			p[n] = b
+			n++
+		}
+		if m == 0 {
+			break		// let the caller handle a (0, nil) read
+		}
+	}
+	return n, err
+}
+
+
+// RBSPWriter is an io.Writer that inserts the emulation prevention bytes
+// H.264 and HEVC require in NAL unit payloads,
+// so that the payload cannot contain a start code.
+// Wherever two zero bytes would be followed by a byte from 0x00 to 0x03,
+// the RBSPWriter inserts a 0x03 byte between them.
+// To produce a NAL unit payload, layer a Writer on top of an RBSPWriter:
+//
+//	e := bytebits.NewRBSPWriter(nal)
+//	w := bytebits.NewWriter(e)
+//	...
+//	w.Flush()
+//	e.Close()
+//
+type RBSPWriter struct {
+	wr io.Writer		// Underlying escaped byte stream
+	zeros int		// Number of consecutive zero bytes just written
+}
+
+var rbspEscape = []byte{0x03}
+
+// NewRBSPWriter returns an RBSPWriter writing escaped bytes to wr.
+func NewRBSPWriter(wr io.Writer) *RBSPWriter {
+	return &RBSPWriter{wr: wr}
+}
+
+// Write implements the io.Writer interface,
+// writing the bytes of p to the underlying writer
+// with emulation prevention bytes inserted as needed.
+// Returns the number of bytes of p written, not counting insertions.
+func (e *RBSPWriter) Write(p []byte) (n int, err error) {
+	start := 0	// start of the bytes not yet written
+	for i, b := range p {
+		if e.zeros >= 2 && b <= 0x03 {
+			m, err := e.wr.Write(p[start:i])
+			if n += m; err != nil {
+				return n, err
+			}
+			if _, err := e.wr.Write(rbspEscape); err != nil {
+				return n, err
+			}
+			start, e.zeros = i, 0
+		}
+		if b == 0 {
+			e.zeros++
+		} else {
+			e.zeros = 0
+		}
+	}
+	m, err := e.wr.Write(p[start:])
+	return n + m, err
+}
+
+// Close completes the escaped stream by appending a final 0x03 byte
+// if the last byte written was zero, as the standards require.
+// Close does not close the underlying writer.
+func (e *RBSPWriter) Close() error {
+	if e.zeros == 0 {
+		return nil
+	}
+	e.zeros = 0
+	_, err := e.wr.Write(rbspEscape)
+	return err
+}
@@ -0,0 +1,57 @@
+package bytebits
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+
+var rbspTests = []struct {
+	raw, escaped []byte
+}{
+	{[]byte{}, []byte{}},
+	{[]byte{0x00, 0x00, 0x01}, []byte{0x00, 0x00, 0x03, 0x01}},
+	{[]byte{0x00, 0x00, 0x04}, []byte{0x00, 0x00, 0x04}},
+	{[]byte{0x00, 0x00, 0x00, 0x00}, []byte{0x00, 0x00, 0x03, 0x00, 0x00, 0x03}},
+	{[]byte{0x12, 0x00, 0x00, 0x03, 0x00, 0x00, 0x02},
+		[]byte{0x12, 0x00, 0x00, 0x03, 0x03, 0x00, 0x00, 0x03, 0x02}},
+}
+
+func TestRBSP(t *testing.T) {
+	for _, c := range rbspTests {
+		var out bytes.Buffer
+		e := NewRBSPWriter(&out)
+		for i := range c.raw {		// write a byte at a time
+			e.Write(c.raw[i:i+1])
+		}
+		if err := e.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+		if !bytes.Equal(out.Bytes(), c.escaped) {
+			t.Errorf("RBSPWriter %x: got %x, want %x",
+				c.raw, out.Bytes(), c.escaped)
+		}
+
+		r := NewRBSPReader(iotest.OneByteReader(bytes.NewReader(c.escaped)))
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, c.raw) {
+			t.Errorf("RBSPReader %x: got %x, %v, want %x",
+				c.escaped, got, err, c.raw)
+		}
+	}
+
+	// Bit-level round trip through the escaping layers
+	var out bytes.Buffer
+	e := NewRBSPWriter(&out)
+	w := NewWriter(e)
+	w.WriteBits(23, 0)
+	w.WriteBits(9, 0x1ff)
+	w.Flush()
+	e.Close()
+	r := NewReader(NewRBSPReader(&out))
+	if v, err := r.ReadBits(32); err != nil || v != 0x1ff {
+		t.Errorf("ReadBits through RBSP: got %x, %v", v, err)
+	}
+}
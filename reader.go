@@ -0,0 +1,409 @@
+package bytebits
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+
+const defaultBufSize = 4096	// Default stream buffer size in bytes
+const minBufSize = 32		// Holds 64 bits unaligned plus unread history
+
+// maxUnread is the number of bits a Reader guarantees it can unread.
+const maxUnread = 64
+
+// maxEmptyReads limits consecutive empty reads from an underlying reader
+// before a Reader gives up with io.ErrNoProgress.
+const maxEmptyReads = 100
+
+
+// Reader is a BitReader that reads bits from an underlying io.Reader,
+// buffering its input so that bit-level parsers can work directly
+// on files, network connections, and other byte streams.
+// A Reader created by NewReader or BigEndian.NewReader
+// reads the bits of each byte most-significant first,
+// returning the first bit read in the most-significant position,
+// while one created by LittleEndian.NewReader reads them
+// least-significant first, as in DEFLATE,
+// returning the first bit read in the least-significant position.
+//
+type Reader struct {
+	rd io.Reader		// Underlying byte stream
+	buf []byte		// Buffered bytes read from rd
+	pos int			// Bit offset of the next unread bit in buf
+	base int64		// Stream bit offset of buf[0]
+	err error		// Sticky error from rd
+	lsb bool		// Read bits least-significant first
+	saved bool		// Buffer pinned from mark on by Save
+	mark int64		// Earliest stream bit offset saved
+}
+
+// ReaderState records a Reader's position for a later Restore.
+type ReaderState struct {
+	off int64		// Stream bit offset
+}
+
+// NewReader returns a new Reader reading from rd,
+// with a default-sized buffer,
+// that reads the bits of each byte most-significant first.
+func NewReader(rd io.Reader) *Reader {
+	return BigEndian.NewReader(rd)
+}
+
+// NewReader returns a new Reader reading from rd,
+// with a default-sized buffer,
+// that reads the bits of each byte most-significant first.
+func (_ BigEndianOrder) NewReader(rd io.Reader) *Reader {
+	return &Reader{rd: rd, buf: make([]byte, 0, defaultBufSize)}
+}
+
+// NewReader returns a new Reader reading from rd,
+// with a default-sized buffer,
+// that reads the bits of each byte least-significant first.
+func (_ LittleEndianOrder) NewReader(rd io.Reader) *Reader {
+	return &Reader{rd: rd, buf: make([]byte, 0, defaultBufSize), lsb: true}
+}
+
+// fill reads from the underlying reader until at least n bits are buffered
+// or an error occurs, first discarding any bytes already fully consumed
+// except for those holding the last maxUnread bits read.
+func (r *Reader) fill(n int) {
+	k := (r.pos - maxUnread) >> 3
+	if r.saved && int64(k) > (r.mark - r.base) >> 3 {
+		k = int((r.mark - r.base) >> 3)
+	}
+	if k > 0 {
+		r.buf = r.buf[:copy(r.buf, r.buf[k:])]
+		r.pos -= k * 8
+		r.base += int64(k) * 8
+	}
+	if len(r.buf) * 8 - r.pos < n && len(r.buf) == cap(r.buf) {
+		r.buf = append(r.buf, 0)[:len(r.buf)]	// grow a pinned buffer
+	}
+	for empty := 0; len(r.buf) * 8 - r.pos < n && r.err == nil; {
+		m, err := r.rd.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf) + m]
+		r.err = err
+		if m > 0 {
+			empty = 0
+		} else if empty++; empty >= maxEmptyReads {
+			r.err = io.ErrNoProgress
+		}
+	}
+}
+
+// ReadBits implements the BitReader interface,
+// reading n bits, or 64 bits maximum,
+// into the least-significant bits of the returned value.
+// Returns EOF without consuming any bits
+// if the stream ends before n bits can be read,
+// or any other error the underlying reader returns.
+func (r *Reader) ReadBits(n int) (v uint64, err error) {
+	if n > 64 {
+		n = 64
+	}
+	if len(r.buf) * 8 - r.pos < n {
+		r.fill(n)
+		if len(r.buf) * 8 - r.pos < n {
+			return 0, r.err
+		}
+	}
+	v = r.peek(n)
+	r.pos += n
+	return v, nil
+}
+
+// peek returns the next n bits, at most 64, which must be buffered.
+// While at least 8 bytes remain in the buffer, bits are taken
+// from a single unaligned 64-bit load acting as a lookahead register.
+func (r *Reader) peek(n int) uint64 {
+	i, o := r.pos >> 3, r.pos & 7
+	if n == 0 {
+		return 0
+	}
+	if o + n <= 64 && i + 8 <= len(r.buf) {
+		if r.lsb {
+			return binary.LittleEndian.Uint64(r.buf[i:]) >> o &
+				(^uint64(0) >> (64 - n))
+		}
+		return binary.BigEndian.Uint64(r.buf[i:]) << o >> (64 - n)
+	}
+	var v uint64
+	if r.lsb {
+		_, _, v = leGet(r.buf[i:], o, n)
+	} else {
+		_, _, v = beGet(r.buf[i:], o, n)
+	}
+	return v
+}
+
+// Peek returns the next n bits, at most 64, without consuming them,
+// as a Huffman or other table-driven decoder does
+// to look ahead before deciding how many bits to Consume.
+// Peek refills the buffer in bulk only as needed,
+// so repeated Peek and Consume calls are cheap.
+// If fewer than n bits remain in the stream,
+// Peek returns those that remain, as if followed by zero bits,
+// along with EOF or the error that ended the stream.
+func (r *Reader) Peek(n int) (uint64, error) {
+	if n > 64 {
+		n = 64
+	}
+	k := len(r.buf) * 8 - r.pos		// bits buffered
+	if k >= n {
+		return r.peek(n), nil
+	}
+	if r.fill(n); len(r.buf) * 8 - r.pos >= n {
+		return r.peek(n), nil
+	}
+	k = len(r.buf) * 8 - r.pos
+	v := r.peek(k)
+	if !r.lsb {
+		v <<= n - k
+	}
+	return v, r.err
+}
+
+// Consume discards the next n bits, which must be buffered,
+// as they are after a Peek of at least n bits returns successfully.
+// Panics with ErrOutOfRange if n is negative
+// or more than the number of bits buffered.
+func (r *Reader) Consume(n int) {
+	if n < 0 || n > len(r.buf) * 8 - r.pos {
+		panic(ErrOutOfRange)
+	}
+	r.pos += n
+}
+
+// Skip discards the next n bits of the stream,
+// as when skipping reserved fields or padding.
+// Skips seek the underlying reader if it implements io.Seeker
+// and no positions are saved, as SeekBits does,
+// in which case skipping past the end of the stream is not detected
+// until the next read returns EOF.
+// Otherwise, or if the seek fails, as on an *os.File that is a pipe,
+// Skip reads and discards the bits,
+// and if the stream ends first, consumes the rest of it and returns EOF.
+// Returns ErrOutOfRange if n is negative.
+func (r *Reader) Skip(n int64) error {
+	if n < 0 {
+		return ErrOutOfRange
+	}
+	if _, ok := r.rd.(io.Seeker); ok && !r.saved {
+		if _, err := r.SeekBits(n, io.SeekCurrent); err == nil {
+			return nil
+		}
+		// The reader may not be seekable after all, as for a pipe
+	}
+	for {
+		k := int64(len(r.buf) * 8 - r.pos)	// bits buffered
+		if n <= k {
+			r.pos += int(n)
+			return nil
+		}
+		r.pos += int(k)
+		n -= k
+		if r.fill(1); len(r.buf) * 8 == r.pos {
+			return r.err
+		}
+	}
+}
+
+// FindSync scans forward from the current position, at every bit offset,
+// for the next occurrence of the n-bit sync word pattern,
+// given as ReadBits(n) would return it,
+// and positions the Reader just after it.
+// A client wishing to position the Reader at the start of the sync word
+// can then call UnreadBits(n).
+// Returns the number of bits skipped before the sync word.
+// If the stream ends first, FindSync consumes all but the last n-1 bits
+// and returns the number of bits skipped along with EOF.
+// Panics with ErrOutOfRange if n is not from 1 to 64.
+func (r *Reader) FindSync(pattern uint64, n int) (skipped int64, err error) {
+	if n < 1 || n > 64 {
+		panic(ErrOutOfRange)
+	}
+	for {
+		if len(r.buf) * 8 - r.pos < n {
+			if r.fill(n); len(r.buf) * 8 - r.pos < n {
+				return skipped, r.err
+			}
+		}
+		var v uint64
+		if r.lsb {
+			_, _, v = leGet(r.buf[r.pos >> 3:], r.pos & 7, n)
+		} else {
+			_, _, v = beGet(r.buf[r.pos >> 3:], r.pos & 7, n)
+		}
+		if v == pattern {
+			r.pos += n
+			return skipped, nil
+		}
+		r.pos++
+		skipped++
+	}
+}
+
+// ReadBool reads a single bit and returns true if it is 1.
+func (r *Reader) ReadBool() (bool, error) {
+	return ReadBool(r)
+}
+
+// ReadBytes reads len(p) whole bytes, that is 8*len(p) bits, into p,
+// starting at the current bit position, which need not be byte-aligned.
+// Bits are copied in blocks of up to 64 rather than byte by byte,
+// and in the Reader's bit order, so that for an LSB-first Reader
+// the first bit read lands in the least-significant bit of p[0].
+// Returns the number of bytes read and, if fewer than len(p),
+// the error that stopped the read, typically EOF.
+// Any trailing bits too few to make up a byte are left unread.
+func (r *Reader) ReadBytes(p []byte) (n int, err error) {
+	for n < len(p) {
+		k := (len(r.buf) * 8 - r.pos) >> 3	// whole bytes buffered
+		if k == 0 {
+			if r.fill(8); len(r.buf) * 8 - r.pos < 8 {
+				return n, r.err
+			}
+			continue
+		}
+		if k > len(p) - n {
+			k = len(p) - n
+		}
+		xb, xo := r.buf[r.pos >> 3:], r.pos & 7
+		switch {
+		case xo == 0:
+			copy(p[n:n + k], xb)
+		case r.lsb:
+			leCopy(p[n:], xb, 0, xo, k * 8)
+		default:
+			beCopy(p[n:], xb, 0, xo, k * 8)
+		}
+		r.pos += k * 8
+		n += k
+	}
+	return n, nil
+}
+
+// UnreadBits backs up the Reader by n bits,
+// so that the next read returns them again,
+// allowing a parser to back up after reading too far.
+// The Reader can always unread up to 64 of the bits most recently read.
+// Panics with ErrOutOfRange if n is negative or greater than 64,
+// or if fewer than n bits have been read.
+func (r *Reader) UnreadBits(n int) {
+	if n < 0 || n > maxUnread || n > r.pos {
+		panic(ErrOutOfRange)
+	}
+	r.pos -= n
+}
+
+// Align skips any remaining bits of the current byte,
+// so that the next read starts at a byte boundary in the stream,
+// and returns the number of bits skipped, from 0 to 7.
+func (r *Reader) Align() int {
+	n := -r.pos & 7
+	r.pos += n
+	return n
+}
+
+// Save returns the Reader's current position,
+// to which a later call to Restore can return,
+// as a parser might before speculatively reading ahead.
+// Until Release is called, the Reader retains all data
+// from the earliest position saved onward,
+// so that Restore succeeds even on a stream that cannot seek.
+func (r *Reader) Save() ReaderState {
+	off := r.Offset()
+	if !r.saved || off < r.mark {
+		r.saved, r.mark = true, off
+	}
+	return ReaderState{off}
+}
+
+// Restore returns the Reader to position s, previously returned by Save,
+// so that the next read starts from there again.
+// Restore does not release saved data,
+// so the same position may be restored more than once.
+// If the data at s is no longer buffered,
+// because of an intervening Release or SeekBits,
+// Restore seeks as SeekBits does and returns any error.
+func (r *Reader) Restore(s ReaderState) error {
+	_, err := r.SeekBits(s.off, io.SeekStart)
+	return err
+}
+
+// Release discards all positions saved with Save,
+// allowing the Reader to discard data before its current position.
+func (r *Reader) Release() {
+	r.saved = false
+}
+
+// read returns the number of bytes read from the underlying reader,
+// which is positioned just after the buffered bytes.
+func (r *Reader) read() int64 {
+	return r.base / 8 + int64(len(r.buf))
+}
+
+// Offset returns the number of bits read from the stream so far,
+// less any bits unread with UnreadBits.
+func (r *Reader) Offset() int64 {
+	return r.base + int64(r.pos)
+}
+
+// SeekBits sets the bit offset of the next read to offset,
+// interpreted according to whence as for io.Seeker:
+// io.SeekStart means relative to the start of the stream,
+// io.SeekCurrent relative to the current offset,
+// and io.SeekEnd relative to the end.
+// Returns the new offset relative to the start of the stream.
+// Seeks within the buffered data succeed on any Reader;
+// other seeks require the underlying reader to implement io.Seeker,
+// and otherwise fail with ErrNotSeeker.
+// Seeking to a negative offset fails with ErrOutOfRange.
+// The start of the stream is the position of the underlying reader
+// when the Reader was created, which need not be its offset 0,
+// as the underlying reader is only ever seeked relative to its position.
+func (r *Reader) SeekBits(offset int64, whence int) (int64, error) {
+	sk, ok := r.rd.(io.Seeker)
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.Offset()
+	case io.SeekEnd:
+		if !ok {
+			return r.Offset(), ErrNotSeeker
+		}
+		cur, err := sk.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return r.Offset(), err
+		}
+		end, err := sk.Seek(0, io.SeekEnd)
+		if err == nil {		// restore rd's position after the buffer
+			_, err = sk.Seek(cur, io.SeekStart)
+		}
+		if err != nil {
+			return r.Offset(), err
+		}
+		offset += (end - (cur - r.read())) * 8
+	default:
+		return r.Offset(), ErrOutOfRange
+	}
+	if offset < 0 {
+		return r.Offset(), ErrOutOfRange
+	}
+
+	// Seek within the buffer if possible
+	if rel := offset - r.base; rel >= 0 && rel <= int64(len(r.buf)) * 8 {
+		r.pos = int(rel)
+		return offset, nil
+	}
+	if !ok {
+		return r.Offset(), ErrNotSeeker
+	}
+	if _, err := sk.Seek(offset >> 3 - r.read(), io.SeekCurrent); err != nil {
+		return r.Offset(), err
+	}
+	r.buf, r.base, r.pos, r.err = r.buf[:0], offset &^ 7, int(offset & 7), nil
+	r.saved = false
+	return offset, nil
+}
@@ -0,0 +1,446 @@
+package bytebits
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+	"testing/iotest"
+)
+
+
+func TestReader(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+
+	// One-byte reads exercise buffer refills at every position
+	for _, r := range []*Reader{
+		NewReader(bytes.NewReader(data)),
+		NewReader(iotest.OneByteReader(bytes.NewReader(data))),
+	} {
+		ofs := 0
+		for i := 0; ofs < len(data) * 8; i++ {
+			n := (i * 37) % 65
+			if n > len(data) * 8 - ofs {
+				n = len(data) * 8 - ofs
+			}
+			want := BigEndian.Uint(data, ofs, n)
+			if v, err := r.ReadBits(n); err != nil || v != want {
+				t.Fatalf("ReadBits(%v) at %v: got %x, %v, want %x",
+					n, ofs, v, err, want)
+			}
+			ofs += n
+		}
+		if _, err := r.ReadBits(1); err != EOF {
+			t.Errorf("ReadBits at end: got %v, want EOF", err)
+		}
+	}
+
+	// A short stream returns EOF without consuming the remaining bits
+	r := NewReader(bytes.NewReader([]byte{0xab, 0xcd}))
+	if _, err := r.ReadBits(17); err != EOF {
+		t.Errorf("ReadBits past end: got %v, want EOF", err)
+	}
+	if v, err := r.ReadBits(16); err != nil || v != 0xabcd {
+		t.Errorf("ReadBits after EOF: got %x, %v", v, err)
+	}
+
+	// Other errors are passed through
+	bad := errors.New("bad")
+	r = NewReader(iotest.ErrReader(bad))
+	if _, err := r.ReadBits(3); err != bad {
+		t.Errorf("ReadBits from failing reader: got %v", err)
+	}
+}
+
+func TestReaderLSBFirst(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.Read(data)
+	r := LittleEndian.NewReader(iotest.HalfReader(bytes.NewReader(data)))
+	ofs := 0
+	for i := 0; ofs < len(data) * 8; i++ {
+		n := (i * 29) % 65
+		if n > len(data) * 8 - ofs {
+			n = len(data) * 8 - ofs
+		}
+		want := LittleEndian.Uint(data, ofs, n)
+		if v, err := r.ReadBits(n); err != nil || v != want {
+			t.Fatalf("ReadBits(%v) at %v: got %x, %v, want %x",
+				n, ofs, v, err, want)
+		}
+		ofs += n
+	}
+
+	// DEFLATE block header: BFINAL=1, BTYPE=01 in the low bits of 0x03
+	r = LittleEndian.NewReader(bytes.NewReader([]byte{0x03}))
+	bfinal, _ := r.ReadBits(1)
+	btype, _ := r.ReadBits(2)
+	if bfinal != 1 || btype != 1 {
+		t.Errorf("DEFLATE header: got BFINAL=%v BTYPE=%v", bfinal, btype)
+	}
+}
+
+func TestReaderUnreadBits(t *testing.T) {
+	data := make([]byte, 5000)
+	rand.Read(data)
+	for _, r := range []*Reader{
+		NewReader(iotest.OneByteReader(bytes.NewReader(data))),
+		LittleEndian.NewReader(bytes.NewReader(data)),
+	} {
+		order := BitOrder(BigEndian)
+		if r.lsb {
+			order = LittleEndian
+		}
+		ofs := 0
+		for i := 0; ofs + 128 <= len(data) * 8; i++ {
+			n := (i * 23) % 65
+			r.ReadBits(n)
+			ofs += n
+			u := (i * 11) % (n + 1)
+			r.UnreadBits(u)
+			ofs -= u
+			if v, err := r.ReadBits(64); err != nil ||
+					v != order.Uint(data, ofs, 64) {
+				t.Fatalf("%v ReadBits after UnreadBits(%v) at %v: "+
+					"got %x, %v", order, u, ofs, v, err)
+			}
+			r.UnreadBits(64)
+		}
+	}
+
+	defer func() {
+		if recover() != ErrOutOfRange {
+			t.Errorf("UnreadBits before start did not panic")
+		}
+	}()
+	r := NewReader(bytes.NewReader(data))
+	r.ReadBits(3)
+	r.UnreadBits(4)
+}
+
+func TestReaderAlign(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte{0xab, 0xcd, 0xef}))
+	if n := r.Align(); n != 0 {
+		t.Errorf("Align at start skipped %v bits", n)
+	}
+	r.ReadBits(3)
+	if n := r.Align(); n != 5 {
+		t.Errorf("Align after 3 bits skipped %v bits, want 5", n)
+	}
+	if v, _ := r.ReadBits(8); v != 0xcd {
+		t.Errorf("ReadBits after Align: got %x, want cd", v)
+	}
+}
+
+func TestReaderOffset(t *testing.T) {
+	data := make([]byte, 10000)
+	r := NewReader(iotest.HalfReader(bytes.NewReader(data)))
+	want := int64(0)
+	for i := 0; i < 1000; i++ {
+		n := (i * 37) % 65
+		r.ReadBits(n)
+		want += int64(n)
+		if i % 7 == 0 {
+			r.UnreadBits(n)
+			want -= int64(n)
+		}
+		if i % 5 == 0 {
+			want += int64(r.Align())
+		}
+		if o := r.Offset(); o != want {
+			t.Fatalf("Offset after %v reads: got %v, want %v", i+1, o, want)
+		}
+	}
+}
+
+func TestReaderSeekBits(t *testing.T) {
+	data := make([]byte, 20000)
+	rand.Read(data)
+	r := NewReader(bytes.NewReader(data))
+	for _, s := range []struct {
+		off int64
+		whence int
+		want int64
+	}{
+		{100, io.SeekStart, 100},
+		{-37, io.SeekCurrent, 63 + 5},	// after reading 5 bits
+		{150000, io.SeekStart, 150000},	// beyond the buffer
+		{-9, io.SeekEnd, 160000 - 9},
+		{3, io.SeekStart, 3},
+		{-1000, io.SeekCurrent, -1},
+	} {
+		got, err := r.SeekBits(s.off, s.whence)
+		if s.want < 0 {
+			if err != ErrOutOfRange {
+				t.Errorf("SeekBits(%v, %v): got %v, want ErrOutOfRange",
+					s.off, s.whence, err)
+			}
+			continue
+		}
+		if err != nil || got != s.want || r.Offset() != s.want {
+			t.Fatalf("SeekBits(%v, %v): got %v, %v, want %v",
+				s.off, s.whence, got, err, s.want)
+		}
+		if v, err := r.ReadBits(5); err != nil ||
+				v != BigEndian.Uint(data, int(s.want), 5) {
+			t.Errorf("ReadBits after SeekBits(%v, %v): got %x, %v",
+				s.off, s.whence, v, err)
+		}
+	}
+
+	// Readers without io.Seeker can seek only within their buffers
+	r = NewReader(iotest.OneByteReader(bytes.NewReader(data)))
+	r.ReadBits(30)
+	if _, err := r.SeekBits(2, io.SeekStart); err != nil {
+		t.Errorf("SeekBits within buffer: %v", err)
+	}
+	if _, err := r.SeekBits(100000, io.SeekStart); err != ErrNotSeeker {
+		t.Errorf("SeekBits beyond buffer: got %v, want ErrNotSeeker", err)
+	}
+}
+
+func TestReaderReadBytes(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, skip := range []int{0, 5, 8, 11} {
+			r := order.NewReader(iotest.HalfReader(bytes.NewReader(data)))
+			ref := order.NewReader(bytes.NewReader(data))
+			r.ReadBits(skip)
+			ref.ReadBits(skip)
+
+			p := make([]byte, 7000)
+			if n, err := r.ReadBytes(p); n != len(p) || err != nil {
+				t.Fatalf("ReadBytes: got %v, %v", n, err)
+			}
+			for i := range p {
+				if v, _ := ref.ReadBits(8); p[i] != byte(v) {
+					t.Fatalf("%v ReadBytes after %v bits: "+
+						"byte %v is %x, want %x",
+						order, skip, i, p[i], v)
+				}
+			}
+
+			// Reading past the end stops at the last whole byte
+			n, err := r.ReadBytes(p)
+			if want := len(data) - 7000 - (skip + 7) / 8; n != want ||
+					err != EOF {
+				t.Errorf("ReadBytes at end: got %v, %v, want %v, EOF",
+					n, err, want)
+			}
+			if v, err := r.ReadBits(-skip & 7); err != nil ||
+					v != order.Uint(data, len(data) * 8 - -skip & 7, -skip & 7) {
+				t.Errorf("ReadBits of trailing bits: got %x, %v", v, err)
+			}
+		}
+	}
+}
+
+func TestReaderSaveRestore(t *testing.T) {
+	data := make([]byte, 20000)
+	rand.Read(data)
+
+	// A non-seekable reader must retain saved data across refills
+	r := NewReader(iotest.OneByteReader(bytes.NewReader(data)))
+	r.ReadBits(5)
+	s := r.Save()
+	for i := 0; i < 10000; i++ {
+		r.ReadBits(13)
+	}
+	for try := 0; try < 2; try++ {
+		if err := r.Restore(s); err != nil || r.Offset() != 5 {
+			t.Fatalf("Restore: %v, offset %v", err, r.Offset())
+		}
+		if v, err := r.ReadBits(64); err != nil ||
+				v != BigEndian.Uint(data, 5, 64) {
+			t.Errorf("ReadBits after Restore: got %x, %v", v, err)
+		}
+	}
+
+	// Nested saves keep the earliest position available
+	s2 := r.Save()
+	r.ReadBits(40)
+	r.Restore(s2)
+	r.Restore(s)
+	if r.Offset() != 5 {
+		t.Errorf("Restore to outer state: offset %v", r.Offset())
+	}
+
+	// After Release, old data may be discarded
+	r.Release()
+	for i := 0; i < 10000; i++ {
+		r.ReadBits(13)
+	}
+	if err := r.Restore(s); err != ErrNotSeeker {
+		t.Errorf("Restore after Release: got %v, want ErrNotSeeker", err)
+	}
+}
+
+func TestReaderSkip(t *testing.T) {
+	data := make([]byte, 20000)
+	rand.Read(data)
+
+	// Skip both through a seekable reader and by discarding data
+	for _, rd := range []io.Reader{
+		bytes.NewReader(data),
+		iotest.HalfReader(bytes.NewReader(data)),
+	} {
+		r := NewReader(rd)
+		ofs := int64(0)
+		for _, n := range []int64{3, 0, 50000, 7, 100000} {
+			if err := r.Skip(n); err != nil {
+				t.Fatalf("Skip(%v): %v", n, err)
+			}
+			ofs += n
+			v, err := r.ReadBits(11)
+			if want := BigEndian.Uint(data, int(ofs), 11); err != nil ||
+					v != want || r.Offset() != ofs + 11 {
+				t.Errorf("ReadBits after Skip(%v): got %x, %v, want %x",
+					n, v, err, want)
+			}
+			ofs += 11
+		}
+		if err := r.Skip(-1); err != ErrOutOfRange {
+			t.Errorf("Skip(-1): got %v", err)
+		}
+	}
+
+	// A pipe is an io.Seeker whose seeks fail
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		pw.Write(data[:1000])
+		pw.Close()
+	}()
+	r := NewReader(pr)
+	if err := r.Skip(5003); err != nil {
+		t.Errorf("Skip on pipe: %v", err)
+	} else if v, _ := r.ReadBits(9); v != BigEndian.Uint(data, 5003, 9) {
+		t.Errorf("ReadBits after Skip on pipe: got %x", v)
+	}
+	pr.Close()
+
+	// Offsets are relative to where the underlying reader started
+	br := bytes.NewReader(data)
+	br.Seek(100, io.SeekStart)
+	r = NewReader(br)
+	if err := r.Skip(50000); err != nil {
+		t.Errorf("Skip from mid-stream: %v", err)
+	} else if v, _ := r.ReadBits(13); v != BigEndian.Uint(data, 800 + 50000, 13) {
+		t.Errorf("ReadBits after Skip from mid-stream: got %x", v)
+	}
+	if o, err := r.SeekBits(-8, io.SeekEnd); err != nil ||
+			o != int64(len(data) - 101) * 8 {
+		t.Errorf("SeekBits from end: got %v, %v", o, err)
+	} else if v, _ := r.ReadBits(8); v != uint64(data[len(data)-1]) {
+		t.Errorf("ReadBits after SeekBits from end: got %x", v)
+	}
+
+	r = NewReader(iotest.HalfReader(bytes.NewReader(data)))
+	if err := r.Skip(int64(len(data)) * 8 + 1); err != EOF {
+		t.Errorf("Skip past end: got %v, want EOF", err)
+	}
+
+	// SkipBits works on any BitReader
+	f := BigEndian.Field(testBits, 0, 192).(*BigEndianField)
+	if err := SkipBits(f, 150); err != nil || f.Len() != 42 {
+		t.Errorf("SkipBits: %v, %v bits left", err, f.Len())
+	}
+	if err := SkipBits(f, 43); err != EOF {
+		t.Errorf("SkipBits past end: got %v, want EOF", err)
+	}
+}
+
+func TestReaderFindSync(t *testing.T) {
+	const sync = 0x47		// MPEG-TS sync byte
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		var out bytes.Buffer
+		w := order.NewWriter(&out)
+		w.WriteBits(13, 0)
+		w.WriteBits(8, sync)
+		w.WriteBits(9, 0x1a5)
+		w.WriteBits(64, 0)
+		w.WriteBits(8, sync)
+		w.WriteBits(3, 0)
+		w.Flush()
+
+		r := order.NewReader(&out)
+		if n, err := r.FindSync(sync, 8); n != 13 || err != nil {
+			t.Errorf("%v FindSync: got %v, %v, want 13", order, n, err)
+		}
+		if v, _ := r.ReadBits(9); v != 0x1a5 {
+			t.Errorf("%v ReadBits after sync: got %x", order, v)
+		}
+		if n, err := r.FindSync(sync, 8); n != 64 || err != nil {
+			t.Errorf("%v second FindSync: got %v, %v", order, n, err)
+		}
+		r.UnreadBits(8)
+		if v, _ := r.ReadBits(8); v != sync {
+			t.Errorf("%v ReadBits at sync: got %x", order, v)
+		}
+		// 3 bits plus 7 bits of padding remain, all but 7 skipped
+		n, err := r.FindSync(sync, 8)
+		if n != 3 || err != EOF {
+			t.Errorf("%v FindSync at end: got %v, %v", order, n, err)
+		}
+	}
+}
+
+func TestReaderPeekConsume(t *testing.T) {
+	data := make([]byte, 5000)
+	rand.Read(data)
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		r := order.NewReader(iotest.HalfReader(bytes.NewReader(data)))
+		ofs := 0
+		for i := 0; ofs < len(data) * 8 - 64; i++ {
+			n := (i * 23) % 65
+			v, err := r.Peek(n)
+			if want := order.Uint(data, ofs, n); err != nil || v != want {
+				t.Fatalf("%v Peek(%v) at %v: got %x, %v, want %x",
+					order, n, ofs, v, err, want)
+			}
+			k := n / 2
+			r.Consume(k)
+			ofs += k
+		}
+
+		// Peeking past the end pads with zeros and reports EOF
+		rem := 37
+		r.Peek(64)
+		r.Consume(len(data) * 8 - ofs - rem)
+		ofs = len(data) * 8 - rem
+		v, err := r.Peek(64)
+		want := order.Uint(data, ofs, rem)
+		if order == BigEndian {
+			want <<= 64 - rem
+		}
+		if err != EOF || v != want {
+			t.Errorf("%v Peek past end: got %x, %v, want %x, EOF",
+				order, v, err, want)
+		}
+		r.Consume(rem)
+		if v := panicValue(func() { r.Consume(1) }); v != ErrOutOfRange {
+			t.Errorf("Consume past end: got panic %v", v)
+		}
+	}
+}
+
+func BenchmarkReaderPeekConsume(b *testing.B) {
+	data := make([]byte, 1 << 16)
+	rand.Read(data)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		r := NewReader(bytes.NewReader(data))
+		for {
+			v, err := r.Peek(15)
+			if err != nil {
+				break
+			}
+			r.Consume(int(v & 7) + 1)
+		}
+	}
+}
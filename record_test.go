@@ -0,0 +1,142 @@
+package bytebits
+
+import (
+	"math/rand"
+	"testing"
+)
+
+
+func TestRecordDecoder(t *testing.T) {
+	specs := []FieldSpec{
+		{3, FieldUint}, {5, FieldPad}, {7, FieldPad}, {12, FieldInt},
+		{1, FieldBool}, {64, FieldUint}, {9, FieldBool}, {57, FieldUint},
+		{100, FieldPad}, {33, FieldInt}, {64, FieldInt}, {0, FieldUint},
+	}
+	d, err := NewRecordDecoder(specs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.NumValues() != 9 {
+		t.Errorf("NumValues: got %v, want 9", d.NumValues())
+	}
+
+	// Compare against independent per-field extraction at all offsets
+	buf := make([]byte, (d.Width() + 7 + 7) >> 3)
+	rand.Read(buf)
+	for ofs := 0; ofs < 8; ofs++ {
+		vals, err := d.Decode(nil, buf, ofs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, o := 0, ofs
+		for _, s := range specs {
+			if s.Kind == FieldPad {
+				o += s.Width
+				continue
+			}
+			v := BigEndian.Uint(buf, o, s.Width)
+			switch {
+			case s.Kind == FieldInt && s.Width > 0 && s.Width < 64:
+				v = uint64(int64(v << (64 - s.Width)) >>
+						(64 - s.Width))
+			case s.Kind == FieldBool && v != 0:
+				v = 1
+			}
+			if vals[i] != v {
+				t.Errorf("ofs %v field %v: got %x, want %x",
+					ofs, i, vals[i], v)
+			}
+			i, o = i+1, o + s.Width
+		}
+	}
+
+	if _, err := d.Decode(nil, buf[:len(buf)-1], 7); err != ErrShortBuffer {
+		t.Errorf("Decode of short buffer: got %v, want ErrShortBuffer",
+			err)
+	}
+	if _, err := NewRecordDecoder(FieldSpec{65, FieldUint}); err != ErrOutOfRange {
+		t.Errorf("NewRecordDecoder with 65-bit field: got %v, want ErrOutOfRange",
+			err)
+	}
+}
+
+func TestRecordEncoder(t *testing.T) {
+	specs := []FieldSpec{
+		{4, FieldUint}, {4, FieldLength}, {6, FieldPad}, {13, FieldInt},
+		{1, FieldBool}, {64, FieldUint}, {16, FieldChecksum},
+		{70, FieldPad}, {57, FieldUint}, {3, FieldInt},
+	}
+	checksum := func(rec []byte) uint64 {
+		var s uint64
+		for _, b := range rec {
+			s = s*31 + uint64(b)
+		}
+		return s
+	}
+	e, err := NewRecordEncoder(checksum, specs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := NewRecordDecoder(specs...)
+	nbytes := uint64((e.Width() + 7) >> 3)
+
+	for ofs := 0; ofs < 8; ofs++ {
+		vals := make([]uint64, e.NumValues())
+		for i := range vals {
+			vals[i] = rand.Uint64()
+		}
+		vals[1] = 3
+
+		// Encode into a buffer full of ones to check for stray bits
+		z := make([]byte, (ofs + e.Width() + 7) >> 3 + 1)
+		for i := range z {
+			z[i] = 0xff
+		}
+		z, err := e.Encode(z, ofs, vals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := BigEndian.Leading(z, 1); c < ofs {
+			t.Errorf("ofs %v: bits before record clobbered", ofs)
+		}
+		if c := BigEndian.Trailing(z, 1); c < len(z)*8 - ofs - e.Width() {
+			t.Errorf("ofs %v: bits after record clobbered", ofs)
+		}
+
+		got, err := d.Decode(nil, z, ofs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []uint64{
+			vals[0] & 0xf,
+			(3 + nbytes) & 0xf,
+			uint64(int64(vals[2] << 51) >> 51),
+			0,
+			vals[4],
+			got[5],
+			vals[6] & (1<<57 - 1),
+			uint64(int64(vals[7] << 61) >> 61),
+		}
+		if vals[3] != 0 {
+			want[3] = 1
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("ofs %v field %v: got %x, want %x",
+					ofs, i, got[i], want[i])
+			}
+		}
+
+		// Recompute the checksum with the checksum field zeroed
+		rec := append([]byte(nil), z[ofs >> 3 : (ofs + e.Width() + 7) >> 3]...)
+		rec = BigEndian.PutUint(rec, ofs & 7 + 92, 16, 0)
+		if sum := checksum(rec) & 0xffff; got[5] != sum {
+			t.Errorf("ofs %v: checksum %x, want %x", ofs, got[5], sum)
+		}
+	}
+
+	if _, err := e.Encode(nil, 0, nil); err != ErrLengthMismatch {
+		t.Errorf("Encode with no values: got %v, want ErrLengthMismatch",
+			err)
+	}
+}
@@ -0,0 +1,158 @@
+package bytebits
+
+import (
+	"io"
+)
+
+
+// Runs returns the lengths of the successive runs of equal bits
+// in field x, alternating between runs of zeros and runs of ones
+// and starting with a run of zeros,
+// which has length zero if x starts with a one bit,
+// as in fax-style run-length coding.
+// The lengths sum to x.Len().
+func Runs(x Field) []int {
+	var runs []int
+	b := uint(0)
+	for pos, w := 0, x.Len(); pos < w; b ^= 1 {
+		next := x.Next(b ^ 1, pos)
+		if next < 0 {
+			next = w
+		}
+		runs = append(runs, next - pos)
+		pos = next
+	}
+	return runs
+}
+
+// SetRuns sets the contents of field z from run lengths
+// as returned by Runs, alternating between zeros and ones
+// and starting with zeros, and returns z.
+// Panics with ErrOutOfRange if any run length is negative,
+// or with ErrLengthMismatch if the lengths do not sum to z.Len().
+func SetRuns(z Field, runs []int) Field {
+	pos, b := 0, uint(0)
+	for _, n := range runs {
+		if n < 0 {
+			panic(ErrOutOfRange)
+		}
+		if n > z.Len() - pos {
+			panic(ErrLengthMismatch)
+		}
+		z.Slice(pos, n).Fill(b)
+		pos += n
+		b ^= 1
+	}
+	if pos != z.Len() {
+		panic(ErrLengthMismatch)
+	}
+	return z
+}
+
+
+// RunLengthCode specifies how WriteRuns and ReadRuns
+// encode each run length in a bit stream.
+type RunLengthCode struct {
+	write func(w BitWriter, n uint64) error
+	read func(r BitReader) (uint64, error)
+}
+
+// ExpGolombRuns returns a RunLengthCode encoding each run length
+// as a k-th order exponential-Golomb code, as WriteExpGolomb does,
+// suiting run lengths spread over a wide range.
+func ExpGolombRuns(k int) RunLengthCode {
+	return RunLengthCode{
+		func(w BitWriter, n uint64) error {
+			return WriteExpGolomb(w, n, k)
+		},
+		func(r BitReader) (uint64, error) {
+			return ReadExpGolomb(r, k)
+		},
+	}
+}
+
+// RiceRuns returns a RunLengthCode encoding each run length
+// as a Rice code with parameter k, as WriteRice does,
+// suiting geometrically distributed run lengths near 2^k.
+func RiceRuns(k int) RunLengthCode {
+	return RunLengthCode{
+		func(w BitWriter, n uint64) error {
+			return WriteRice(w, n, k)
+		},
+		func(r BitReader) (uint64, error) {
+			return ReadRice(r, k)
+		},
+	}
+}
+
+// FixedRuns returns a RunLengthCode encoding each run length
+// in fixed fields of width bits, from 1 to 64.
+// A run of 2^width - 1 bits or longer is encoded as a series
+// of all-ones fields, each adding 2^width - 1 bits to the run,
+// ending with a field holding the remainder,
+// which may be zero.
+// Panics with ErrOutOfRange if width is not within this range.
+func FixedRuns(width int) RunLengthCode {
+	if width < 1 || width > 64 {
+		panic(ErrOutOfRange)
+	}
+	max := ^uint64(0) >> (64 - width)
+	return RunLengthCode{
+		func(w BitWriter, n uint64) error {
+			for ; n >= max; n -= max {
+				if err := w.WriteBits(width, max); err != nil {
+					return err
+				}
+			}
+			return w.WriteBits(width, n)
+		},
+		func(r BitReader) (n uint64, err error) {
+			for {
+				v, err := r.ReadBits(width)
+				if err != nil {
+					return 0, err
+				}
+				if n += v; v != max {
+					return n, nil
+				}
+			}
+		},
+	}
+}
+
+// WriteRuns writes the run lengths of field x, as returned by Runs,
+// to w using run-length code c.
+// The width of x is not written, and must be known to the reader.
+func WriteRuns(w BitWriter, x Field, c RunLengthCode) error {
+	for _, n := range Runs(x) {
+		if err := c.write(w, uint64(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRuns reads run lengths written by WriteRuns from r
+// using run-length code c, until they fill field z,
+// and sets z from them as SetRuns does.
+// Returns ErrInvalidCode if the runs read overflow the field,
+// and io.ErrUnexpectedEOF if r ends before the field is full.
+func ReadRuns(r BitReader, z Field, c RunLengthCode) error {
+	pos, b := 0, uint(0)
+	for first := true; pos < z.Len(); first = false {
+		n, err := c.read(r)
+		if err != nil {
+			if err == EOF && !first {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if n > uint64(z.Len() - pos) {
+			return ErrInvalidCode
+		}
+		z.Slice(pos, int(n)).Fill(b)
+		pos += int(n)
+		b ^= 1
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package bytebits
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+
+func TestRuns(t *testing.T) {
+	x := beFieldAt([]byte{0x0f, 0x80, 0xff}, 0, 24)
+	if r := Runs(x); fmt.Sprint(r) != "[4 5 7 8]" {
+		t.Errorf("Runs: got %v", r)
+	}
+	if r := Runs(x.Slice(4, 20)); fmt.Sprint(r) != "[0 5 7 8]" {
+		t.Errorf("Runs from a one bit: got %v", r)
+	}
+	if r := Runs(x.Slice(0, 0)); len(r) != 0 {
+		t.Errorf("Runs of empty field: got %v", r)
+	}
+
+	// Sparse random masks round trip through each run-length code
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		buf := make([]byte, 300)
+		for i := 0; i < 40; i++ {
+			order.PutBit(buf, rand.Intn(2400), 1)
+		}
+		x := order.Field(buf, 3, 2390)
+		z := order.Field(make([]byte, 300), 5, 2390)
+		if !SetRuns(z, Runs(x)).Equal(x) {
+			t.Errorf("%v SetRuns(Runs(x)) differs from x", order)
+		}
+		for _, c := range []RunLengthCode{
+			ExpGolombRuns(2), RiceRuns(5), FixedRuns(3), FixedRuns(64),
+		} {
+			w := NewAppendWriter(nil)
+			if err := WriteRuns(w, x, c); err != nil {
+				t.Fatalf("WriteRuns: %v", err)
+			}
+			z.Fill(1)
+			r := BigEndian.Field(w.Bytes(), 0, w.Len()).Reader()
+			if err := ReadRuns(r, z, c); err != nil || !z.Equal(x) {
+				t.Errorf("%v ReadRuns: %v", order, err)
+			}
+			if _, err := r.ReadBits(1); err != EOF {
+				t.Errorf("%v ReadRuns left bits unread", order)
+			}
+
+			// Truncated input
+			r = BigEndian.Field(w.Bytes(), 0, w.Len() / 2).Reader()
+			if err := ReadRuns(r, z, c); err != io.ErrUnexpectedEOF {
+				t.Errorf("%v ReadRuns of truncated input: got %v",
+					order, err)
+			}
+		}
+	}
+
+	if v := panicValue(func() { SetRuns(x, []int{4, 5}) });
+			v != ErrLengthMismatch {
+		t.Errorf("SetRuns with short runs: got panic %v", v)
+	}
+}
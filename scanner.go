@@ -0,0 +1,174 @@
+package bytebits
+
+import (
+	"io"
+)
+
+
+// BitScanner splits a bit stream into successive frames,
+// in the manner of bufio.Scanner,
+// for decoders of telemetry and radio protocols
+// whose frames are marked by sync words or have a fixed length.
+// Frames may start at any bit offset in the stream.
+//
+// A BitScanner works in one of three modes,
+// chosen by the sync word and frame length passed to NewBitScanner:
+//
+//	- With a sync word and no frame length, frames are delimited
+//	  by successive sync words. Bits before the first sync word are
+//	  skipped, and the final frame runs to the end of the stream,
+//	  including any padding in its last byte.
+//	- With both a sync word and a frame length, each frame consists of
+//	  the given number of bits following the next sync word,
+//	  resynchronizing before every frame as for MPEG-TS packets.
+//	- With only a frame length, frames follow each other back to back.
+//
+// Frames never include their sync word.
+// Because a sync-delimited frame is buffered in full,
+// frame length is limited only by available memory.
+//
+// The BitScanner uses the Reader's Save and Release methods,
+// so clients should not hold saved positions of their own while scanning.
+//
+type BitScanner struct {
+	r *Reader
+	sync uint64		// Sync word pattern, if syncLen > 0
+	syncLen int		// Width of the sync word in bits, or 0
+	frameLen int		// Fixed frame length in bits, or 0
+	started bool		// First sync word found
+	done bool		// No frames remain
+	frame *AppendWriter	// Current frame
+	err error		// First error other than EOF
+}
+
+// NewBitScanner returns a BitScanner reading frames from r.
+// If syncLen is nonzero, frames are marked by the syncLen-bit sync word,
+// given as r.ReadBits(syncLen) would return it.
+// If frameLen is nonzero, each frame is frameLen bits long.
+// Panics with ErrOutOfRange if syncLen is not from 0 to 64,
+// or if both syncLen and frameLen are zero or frameLen is negative.
+func NewBitScanner(r *Reader, sync uint64, syncLen, frameLen int) *BitScanner {
+	if syncLen < 0 || syncLen > 64 || frameLen < 0 ||
+			(syncLen == 0 && frameLen == 0) {
+		panic(ErrOutOfRange)
+	}
+	return &BitScanner{r: r, sync: sync, syncLen: syncLen,
+			frameLen: frameLen, frame: &AppendWriter{lsb: r.lsb}}
+}
+
+// Scan advances the BitScanner to the next frame,
+// which is then available through the Field and Reader methods.
+// Returns false when the scan stops,
+// either by reaching the end of the stream or on an error.
+// After Scan returns false, Err returns any error that occurred,
+// except that it returns nil if the stream simply ended.
+// A fixed-length frame cut short by the end of the stream
+// is not returned, and Err reports io.ErrUnexpectedEOF.
+func (s *BitScanner) Scan() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+	s.frame.b, s.frame.pos = s.frame.b[:0], 0
+	if s.syncLen > 0 && (s.frameLen > 0 || !s.started) {
+		if _, err := s.r.FindSync(s.sync, s.syncLen); err != nil {
+			return s.stop(err)
+		}
+		s.started = true
+	}
+
+	// Fixed-length frame
+	if s.frameLen > 0 {
+		if err := s.copy(int64(s.frameLen)); err != nil {
+			if err == EOF && (s.frame.pos > 0 || s.copyRest()) {
+				err = io.ErrUnexpectedEOF
+			}
+			return s.stop(err)
+		}
+		return true
+	}
+
+	// Frame delimited by the next sync word
+	sv := s.r.Save()
+	defer s.r.Release()
+	n, err := s.r.FindSync(s.sync, s.syncLen)
+	if err != nil && err != EOF {
+		return s.stop(err)
+	}
+	if err := s.r.Restore(sv); err != nil {
+		return s.stop(err)
+	}
+	if err := s.copy(n); err != nil {
+		return s.stop(err)
+	}
+	if err == nil {
+		if err := s.r.Skip(int64(s.syncLen)); err != nil {
+			return s.stop(err)
+		}
+		return true
+	}
+	s.done = true
+	return s.copyRest() || s.frame.pos > 0
+}
+
+// copy appends the next n bits of the stream to the current frame.
+func (s *BitScanner) copy(n int64) error {
+	for n > 0 {
+		k := 64
+		if n < 64 {
+			k = int(n)
+		}
+		v, err := s.r.ReadBits(k)
+		if err != nil {
+			return err
+		}
+		s.frame.WriteBits(k, v)
+		n -= int64(k)
+	}
+	return nil
+}
+
+// copyRest appends any bits remaining in the stream, too few to read
+// in a larger chunk, to the current frame,
+// and reports whether there were any.
+func (s *BitScanner) copyRest() bool {
+	any := false
+	for {
+		v, err := s.r.ReadBits(1)
+		if err != nil {
+			return any
+		}
+		s.frame.WriteBits(1, v)
+		any = true
+	}
+}
+
+// stop ends the scan, recording err unless it is EOF.
+func (s *BitScanner) stop(err error) bool {
+	if err != EOF {
+		s.err = err
+	}
+	s.done = true
+	return false
+}
+
+// Field returns the most recent frame found by Scan,
+// in the stream's bit order.
+// The underlying bytes may be overwritten by the next call to Scan.
+func (s *BitScanner) Field() Field {
+	if s.r.lsb {
+		return LittleEndian.Field(s.frame.b, 0, s.frame.pos)
+	}
+	return BigEndian.Field(s.frame.b, 0, s.frame.pos)
+}
+
+// Reader returns a BitReader that reads the most recent frame
+// found by Scan, as for Field.
+func (s *BitScanner) Reader() BitReader {
+	return s.Field().Reader()
+}
+
+// Err returns the first error other than EOF
+// that the BitScanner encountered.
+func (s *BitScanner) Err() error {
+	return s.err
+}
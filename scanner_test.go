@@ -0,0 +1,79 @@
+package bytebits
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+
+func TestBitScanner(t *testing.T) {
+	const sync = 0x1acf		// 13-bit sync word
+	frames := []struct {
+		n int
+		v uint64
+	}{{20, 0xabcde}, {1, 1}, {64, 0x0123456789abcdef}, {7, 0x55}}
+
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		var out bytes.Buffer
+		w := order.NewWriter(&out)
+		w.WriteBits(5, 0x1f)		// junk before the first sync
+		for _, f := range frames {
+			w.WriteBits(13, sync)
+			w.WriteBits(f.n, f.v)
+		}
+		w.WriteBits(1, 0)		// 150 bits, padded to 152
+		w.Flush()
+		data := out.Bytes()
+
+		// Frames delimited by sync words
+		s := NewBitScanner(order.NewReader(bytes.NewReader(data)),
+				sync, 13, 0)
+		for i, f := range frames {
+			if !s.Scan() {
+				t.Fatalf("%v Scan %v stopped: %v", order, i, s.Err())
+			}
+			want := f.n
+			if i == len(frames) - 1 {
+				want += 3	// trailing bit and padding
+			}
+			fr := s.Reader()
+			if l := s.Field().Len(); l != want {
+				t.Errorf("%v frame %v: %v bits, want %v",
+					order, i, l, want)
+			} else if v, _ := fr.ReadBits(f.n); v != f.v {
+				t.Errorf("%v frame %v: got %x, want %x",
+					order, i, v, f.v)
+			}
+		}
+		if s.Scan() || s.Err() != nil {
+			t.Errorf("%v Scan at end: err %v", order, s.Err())
+		}
+
+		// Fixed-length frames, each after a sync word
+		s = NewBitScanner(order.NewReader(bytes.NewReader(data)),
+				sync, 13, 20)
+		if !s.Scan() {
+			t.Fatalf("%v Scan: %v", order, s.Err())
+		}
+		if v, _ := s.Reader().ReadBits(20); v != 0xabcde {
+			t.Errorf("%v fixed frame after sync: got %x", order, v)
+		}
+	}
+
+	// Back-to-back fixed-length frames, the last cut short
+	data := []byte{0x12, 0x34, 0x56, 0x78}
+	s := NewBitScanner(NewReader(bytes.NewReader(data)), 0, 0, 12)
+	for _, want := range []uint64{0x123, 0x456} {
+		if !s.Scan() {
+			t.Fatalf("Scan: %v", s.Err())
+		}
+		if v, _ := s.Reader().ReadBits(12); v != want {
+			t.Errorf("fixed frame: got %x, want %x", v, want)
+		}
+	}
+	if s.Scan() || s.Err() != io.ErrUnexpectedEOF {
+		t.Errorf("Scan of short frame: got %v, want ErrUnexpectedEOF",
+			s.Err())
+	}
+}
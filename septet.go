@@ -0,0 +1,65 @@
+package bytebits
+
+
+// septetCR is the GSM 03.38 carriage return character,
+// which PackSeptets places in otherwise unused trailing bits.
+const septetCR = 0x0d
+
+// SeptetFill returns the number of fill bits, from 0 to 6,
+// that follow a user data header of udhLen octets,
+// including its length octet, in a GSM 03.38 message,
+// so that the first packed septet starts on a septet boundary.
+func SeptetFill(udhLen int) int {
+	return (7 - udhLen * 8 % 7) % 7
+}
+
+// PackSeptets appends to dst the 7-bit characters in septets
+// packed as in GSM 03.38 (3GPP TS 23.038) SMS user data,
+// and returns the extended slice.
+// The septets are packed least-significant bit first,
+// as in LittleEndian bit order, following fill zero bits,
+// where fill is between 0 and 6, as SeptetFill computes.
+// The last octet is padded with zero bits, except that
+// 7 spare bits hold a carriage return character as the standard specifies,
+// so that a receiver does not mistake them for a trailing '@'.
+// Panics with ErrOutOfRange if fill is not within range
+// or any septet exceeds 0x7f.
+func PackSeptets(dst, septets []byte, fill int) []byte {
+	if fill < 0 || fill > 6 {
+		panic(ErrOutOfRange)
+	}
+	w := LittleEndian.NewAppendWriter(dst)
+	w.WriteBits(fill, 0)
+	for _, s := range septets {
+		if s > 0x7f {
+			panic(ErrOutOfRange)
+		}
+		w.WriteBits(7, uint64(s))
+	}
+	if w.Len() & 7 == 1 {
+		w.WriteBits(7, septetCR)
+	}
+	return w.Bytes()
+}
+
+// UnpackSeptets appends to dst n 7-bit characters unpacked from src,
+// which holds septets packed as in GSM 03.38 following fill bits,
+// in the layout that PackSeptets produces, and returns the extended slice.
+// The count n is needed because trailing padding bits
+// can otherwise be indistinguishable from a septet;
+// an SMS user data length field gives it directly.
+// Returns ErrShortBuffer if src holds fewer than n septets.
+// Panics with ErrOutOfRange if fill is not between 0 and 6
+// or n is negative.
+func UnpackSeptets(dst, src []byte, fill, n int) ([]byte, error) {
+	if fill < 0 || fill > 6 || n < 0 {
+		panic(ErrOutOfRange)
+	}
+	if fill + 7 * n > len(src) * 8 {
+		return dst, ErrShortBuffer
+	}
+	for i := 0; i < n; i++ {
+		dst = append(dst, byte(LittleEndian.Uint(src, fill + 7 * i, 7)))
+	}
+	return dst, nil
+}
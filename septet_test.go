@@ -0,0 +1,50 @@
+package bytebits
+
+import (
+	"bytes"
+	"testing"
+)
+
+
+func TestSeptets(t *testing.T) {
+	// "hellohello" from the classic SMS PDU example
+	hello := []byte("hellohello")
+	want := []byte{0xe8, 0x32, 0x9b, 0xfd, 0x46, 0x97, 0xd9, 0xec, 0x37}
+	if got := PackSeptets(nil, hello, 0); !bytes.Equal(got, want) {
+		t.Errorf("PackSeptets: got %x, want %x", got, want)
+	}
+	if got, err := UnpackSeptets(nil, want, 0, len(hello));
+			err != nil || !bytes.Equal(got, hello) {
+		t.Errorf("UnpackSeptets: got %q, %v", got, err)
+	}
+
+	// Seven spare bits hold a carriage return, not a spurious '@'
+	if got := PackSeptets(nil, []byte("1234567"), 0); got[6] != septetCR << 1 {
+		t.Errorf("PackSeptets of 7 septets: last octet %x", got[6])
+	}
+
+	// A 6-octet header, as for concatenated SMS, needs one fill bit
+	fill := SeptetFill(6)
+	if fill != 1 {
+		t.Errorf("SeptetFill(6): got %v, want 1", fill)
+	}
+	udh := []byte{0x05, 0x00, 0x03, 0x2a, 0x02, 0x01}
+	msg := []byte("Hi @ there{}")
+	pdu := PackSeptets(append([]byte(nil), udh...), msg, fill)
+	if !bytes.Equal(pdu[:6], udh) || pdu[6] & 1 != 0 ||
+			len(pdu) != 6 + (fill + 7 * len(msg) + 7) / 8 {
+		t.Errorf("PackSeptets after header: got %x", pdu)
+	}
+	if got, err := UnpackSeptets(nil, pdu[6:], fill, len(msg));
+			err != nil || !bytes.Equal(got, msg) {
+		t.Errorf("UnpackSeptets after header: got %q, %v", got, err)
+	}
+	if _, err := UnpackSeptets(nil, pdu[6:], fill, len(msg) + 2);
+			err != ErrShortBuffer {
+		t.Errorf("UnpackSeptets past end: got %v, want ErrShortBuffer", err)
+	}
+	if panicValue(func() { PackSeptets(nil, []byte{0x80}, 0) }) !=
+			ErrOutOfRange {
+		t.Errorf("PackSeptets of 8-bit character did not panic")
+	}
+}
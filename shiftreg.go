@@ -0,0 +1,170 @@
+package bytebits
+
+
+// ShiftDir indicates the direction in which a ShiftRegister shifts.
+type ShiftDir bool
+
+// TowardFirst shifts bits toward stage 0:
+// serial input enters at the last stage, and stage 0 is shifted out.
+const TowardFirst ShiftDir = false
+
+// TowardLast shifts bits toward the last stage:
+// serial input enters at stage 0, and the last stage is shifted out.
+const TowardLast ShiftDir = true
+
+
+// ShiftRegister emulates a hardware shift register of arbitrary width,
+// for simulating serial protocols such as SPI captures or JTAG scan chains.
+// Its stages are numbered from 0 to Width-1,
+// and are stored in big-endian bit order,
+// so that stage i is bit i of the slice returned by Bytes.
+//
+// A ShiftRegister may be used serial-in/serial-out (SISO) via Shift,
+// serial-in/parallel-out (SIPO) via Shift and Bytes,
+// or parallel-in/serial-out (PISO) via Load and Shift,
+// and may be driven by a BitReader and captured to a BitWriter
+// via ShiftFrom.
+// Tap outputs configured when the register is created
+// may be sampled at any time via Taps.
+//
+type ShiftRegister struct {
+	b []byte		// contents of all stages, with zero padding
+	w int			// width in stages
+	dir ShiftDir		// shift direction
+	taps []int		// tap stages, in order
+}
+
+// NewShiftRegister creates a ShiftRegister with width stages,
+// all initially zero, shifting in direction dir,
+// and with tap outputs at the specified stages.
+// Panics with ErrOutOfRange if width is less than 1,
+// if any tap is not a valid stage, or if there are more than 64 taps.
+func NewShiftRegister(width int, dir ShiftDir, taps ...int) *ShiftRegister {
+	if width < 1 || len(taps) > 64 {
+		panic(ErrOutOfRange)
+	}
+	for _, t := range taps {
+		if t < 0 || t >= width {
+			panic(ErrOutOfRange)
+		}
+	}
+	return &ShiftRegister{make([]byte, (width + 7) >> 3), width, dir,
+				append([]int(nil), taps...)}
+}
+
+// Width returns the number of stages in shift register r.
+func (r *ShiftRegister) Width() int {
+	return r.w
+}
+
+// Shift shifts serial input bit in, 0 or 1, into shift register r,
+// and returns the bit shifted out.
+// Panics with ErrBadBitValue if in is not 0 or 1.
+func (r *ShiftRegister) Shift(in uint) (out uint) {
+	if in > 1 {
+		panic(ErrBadBitValue)
+	}
+	b := r.b
+	if r.dir == TowardFirst {
+		out = uint(b[0] >> 7)
+		for i := range b {
+			c := byte(0)
+			if i + 1 < len(b) {
+				c = b[i+1] >> 7
+			}
+			b[i] = b[i] << 1 | c
+		}
+		BigEndian.PutBit(b, r.w - 1, in)
+	} else {
+		out = BigEndian.Bit(b, r.w - 1)
+		c := byte(in)
+		for i := range b {
+			b[i], c = b[i] >> 1 | c << 7, b[i] & 1
+		}
+		r.clearPad()
+	}
+	return out
+}
+
+// ShiftBits shifts the least-significant n bits of in, up to 64,
+// into shift register r, most-significant bit first,
+// and returns the n bits shifted out,
+// with the first bit shifted out in the most-significant position.
+// Panics with ErrOutOfRange if n is negative or greater than 64.
+func (r *ShiftRegister) ShiftBits(n int, in uint64) (out uint64) {
+	if n < 0 || n > 64 {
+		panic(ErrOutOfRange)
+	}
+	for i := n-1; i >= 0; i-- {
+		out = out << 1 | uint64(r.Shift(uint(in >> i) & 1))
+	}
+	return out
+}
+
+// ShiftFrom shifts n bits read from BitReader in into shift register r,
+// writing the bits shifted out to BitWriter out if it is not nil,
+// up to 64 bits at a time.
+// Returns the first error encountered reading or writing, if any.
+func (r *ShiftRegister) ShiftFrom(in BitReader, out BitWriter, n int) error {
+	for n > 0 {
+		k := n
+		if k > 64 {
+			k = 64
+		}
+		v, err := in.ReadBits(k)
+		if err != nil {
+			return err
+		}
+		v = r.ShiftBits(k, v)
+		if out != nil {
+			if err := out.WriteBits(k, v); err != nil {
+				return err
+			}
+		}
+		n -= k
+	}
+	return nil
+}
+
+// Load sets all stages of shift register r in parallel
+// from the first Width bits of slice x, in big-endian bit order.
+// Panics with ErrShortBuffer if x holds fewer than Width bits.
+func (r *ShiftRegister) Load(x []byte) {
+	if len(x) * 8 < r.w {
+		panic(ErrShortBuffer)
+	}
+	copy(r.b, x)
+	r.clearPad()
+}
+
+// Bytes returns a copy of the contents of all stages of shift register r,
+// in big-endian bit order, with any bits past the last stage set to zero.
+func (r *ShiftRegister) Bytes() []byte {
+	return append([]byte(nil), r.b...)
+}
+
+// Stage returns the current value of stage i of shift register r.
+// Panics with ErrOutOfRange if i is not a valid stage.
+func (r *ShiftRegister) Stage(i int) uint {
+	if i < 0 || i >= r.w {
+		panic(ErrOutOfRange)
+	}
+	return BigEndian.Bit(r.b, i)
+}
+
+// Taps returns the current values of the tap outputs of shift register r
+// in the least-significant bits of the result,
+// with the first tap in the most-significant position.
+func (r *ShiftRegister) Taps() (v uint64) {
+	for _, t := range r.taps {
+		v = v << 1 | uint64(BigEndian.Bit(r.b, t))
+	}
+	return v
+}
+
+// clearPad zeros the padding bits past the last stage.
+func (r *ShiftRegister) clearPad() {
+	if p := r.w & 7; p != 0 {
+		r.b[len(r.b)-1] &^= 0xff >> p
+	}
+}
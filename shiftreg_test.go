@@ -0,0 +1,75 @@
+package bytebits
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+
+// bitCollector is a BitWriter that accumulates written bits
+// into a big-endian bit string.
+type bitCollector struct {
+	b []byte
+	n int
+}
+
+func (c *bitCollector) WriteBits(n int, v uint64) error {
+	c.b = BigEndian.PutUint(c.b, c.n, n, v)
+	c.n += n
+	return nil
+}
+
+func TestShiftRegister(t *testing.T) {
+	for _, w := range []int{1, 5, 8, 13, 70} {
+		for _, dir := range []ShiftDir{TowardFirst, TowardLast} {
+			r := NewShiftRegister(w, dir, 0, w-1)
+
+			// Bits emerge in order after a delay of w shifts
+			in := make([]byte, 32)
+			rand.Read(in)
+			var f BigEndianField
+			f.Init(in, 0, 256)
+			var c bitCollector
+			if err := r.ShiftFrom(&f, &c, 256); err != nil {
+				t.Fatal(err)
+			}
+			want := BigEndian.Copy(nil, in, w, 0, 256 - w)
+			if !bytes.Equal(c.b, want) {
+				t.Errorf("width %v dir %v: SISO output %x, want %x",
+					w, dir, c.b, want)
+			}
+
+			// The last w bits in are held in parallel
+			for i := 0; i < w; i++ {
+				j := 256 - w + i	// first bit in at stage 0
+				if dir == TowardLast {
+					j = 255 - i	// last bit in at stage 0
+				}
+				if r.Stage(i) != BigEndian.Bit(in, j) {
+					t.Errorf("width %v dir %v: stage %v wrong",
+						w, dir, i)
+				}
+			}
+			if v := r.Taps(); v != uint64(r.Stage(0) << 1 | r.Stage(w-1)) {
+				t.Errorf("width %v dir %v: taps %b wrong", w, dir, v)
+			}
+
+			// Parallel load, serial out
+			r.Load(in)
+			if !bytes.Equal(r.Bytes(),
+					BigEndian.Copy(nil, in, 0, 0, w)) {
+				t.Errorf("width %v dir %v: Load/Bytes mismatch",
+					w, dir)
+			}
+			first := r.Stage(0)
+			if dir == TowardLast {
+				first = r.Stage(w-1)
+			}
+			if out := r.Shift(1); out != first {
+				t.Errorf("width %v dir %v: shifted out %v, want %v",
+					w, dir, out, first)
+			}
+		}
+	}
+}
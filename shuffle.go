@@ -0,0 +1,206 @@
+package bytebits
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+
+// The shuffle and butterfly operations are the standard building blocks
+// from which arbitrary bit permutations may be composed,
+// as described in chapter 7 of Warren's Hacker's Delight.
+// Bits are numbered in big-endian bit order, from the most-significant
+// bit of a uint64 or from the first bit of a slice.
+
+
+// OuterShuffle64 performs an outer perfect shuffle of the bits of x,
+// interleaving the bits of its upper half with those of its lower half,
+// so that bits abcd...ABCD... become aAbBcCdD...
+func OuterShuffle64(x uint64) uint64 {
+	x = deltaSwap64(x, 0x00000000ffff0000, 16)
+	x = deltaSwap64(x, 0x0000ff000000ff00, 8)
+	x = deltaSwap64(x, 0x00f000f000f000f0, 4)
+	x = deltaSwap64(x, 0x0c0c0c0c0c0c0c0c, 2)
+	x = deltaSwap64(x, 0x2222222222222222, 1)
+	return x
+}
+
+// OuterUnshuffle64 inverts OuterShuffle64,
+// gathering the even-numbered bits of x into its upper half
+// and the odd-numbered bits into its lower half,
+// so that bits aAbBcCdD... become abcd...ABCD...
+func OuterUnshuffle64(x uint64) uint64 {
+	x = deltaSwap64(x, 0x2222222222222222, 1)
+	x = deltaSwap64(x, 0x0c0c0c0c0c0c0c0c, 2)
+	x = deltaSwap64(x, 0x00f000f000f000f0, 4)
+	x = deltaSwap64(x, 0x0000ff000000ff00, 8)
+	x = deltaSwap64(x, 0x00000000ffff0000, 16)
+	return x
+}
+
+// InnerShuffle64 performs an inner perfect shuffle of the bits of x,
+// interleaving the bits of its lower half with those of its upper half,
+// so that bits abcd...ABCD... become AaBbCcDd...
+func InnerShuffle64(x uint64) uint64 {
+	return OuterShuffle64(bits.RotateLeft64(x, 32))
+}
+
+// InnerUnshuffle64 inverts InnerShuffle64,
+// so that bits AaBbCcDd... become abcd...ABCD...
+func InnerUnshuffle64(x uint64) uint64 {
+	return bits.RotateLeft64(OuterUnshuffle64(x), 32)
+}
+
+// Butterfly64 performs butterfly stage k, from 0 to 5, on the bits of x:
+// each pair of bits 2^k positions apart within an aligned block
+// of 2^(k+1) bits is exchanged if the first bit of the pair,
+// at the corresponding position in m, is set.
+// Bits of m at the second position of each pair are ignored.
+// Panics with ErrOutOfRange if k is not in the range 0 to 5.
+func Butterfly64(x uint64, k int, m uint64) uint64 {
+	if k < 0 || k > 5 {
+		panic(ErrOutOfRange)
+	}
+	s := uint(1) << k
+	return deltaSwap64(x, (m >> s) & butterflyMask[k], s)
+}
+
+// butterflyMask[k] selects the second, less-significant bit of each pair
+// exchanged by butterfly stage k.
+var butterflyMask = [6]uint64{
+	0x5555555555555555, 0x3333333333333333, 0x0f0f0f0f0f0f0f0f,
+	0x00ff00ff00ff00ff, 0x0000ffff0000ffff, 0x00000000ffffffff,
+}
+
+// deltaSwap64 exchanges each bit of x selected by mask m
+// with the bit s positions more significant.
+func deltaSwap64(x, m uint64, s uint) uint64 {
+	t := ((x >> s) ^ x) & m
+	return x ^ t ^ (t << s)
+}
+
+
+// OuterShuffle sets z to the outer perfect shuffle of the bits of slice x,
+// interleaving the bits of its first half with those of its second half,
+// and returns z.
+// Slices z and x must not overlap.
+// Allocates and returns a new destination slice if z is not long enough.
+func OuterShuffle(z, x []byte) []byte {
+	return shuffleBits(z, x, false)
+}
+
+// InnerShuffle sets z to the inner perfect shuffle of the bits of slice x,
+// interleaving the bits of its second half with those of its first half,
+// and returns z.
+// Slices z and x must not overlap.
+// Allocates and returns a new destination slice if z is not long enough.
+func InnerShuffle(z, x []byte) []byte {
+	return shuffleBits(z, x, true)
+}
+
+// OuterUnshuffle inverts OuterShuffle, setting z to the bits of x
+// at even-numbered positions followed by those at odd positions,
+// and returns z.
+// Slices z and x must not overlap.
+// Allocates and returns a new destination slice if z is not long enough.
+func OuterUnshuffle(z, x []byte) []byte {
+	return unshuffleBits(z, x, false)
+}
+
+// InnerUnshuffle inverts InnerShuffle, setting z to the bits of x
+// at odd-numbered positions followed by those at even positions,
+// and returns z.
+// Slices z and x must not overlap.
+// Allocates and returns a new destination slice if z is not long enough.
+func InnerUnshuffle(z, x []byte) []byte {
+	return unshuffleBits(z, x, true)
+}
+
+// shuffleBits interleaves the two halves of x into z
+// up to 32 bits from each half at a time.
+func shuffleBits(z, x []byte, inner bool) []byte {
+	l := len(x)
+	z = Grow(z, l)
+	h := l * 4			// bits in each half
+	for i := 0; i < h; i += 32 {
+		w := h - i
+		if w > 32 {
+			w = 32
+		}
+		a := BigEndian.Uint(x, i, w) << (32 - w)
+		b := BigEndian.Uint(x, h + i, w) << (32 - w)
+		if inner {
+			a, b = b, a
+		}
+		v := OuterShuffle64(a << 32 | b)
+		BigEndian.PutUint(z, 2*i, 2*w, v >> (64 - 2*w))
+	}
+	return z
+}
+
+// unshuffleBits separates alternate bits of x into the two halves of z
+// up to 32 bits of each half at a time.
+func unshuffleBits(z, x []byte, inner bool) []byte {
+	l := len(x)
+	z = Grow(z, l)
+	h := l * 4			// bits in each half
+	for i := 0; i < h; i += 32 {
+		w := h - i
+		if w > 32 {
+			w = 32
+		}
+		v := OuterUnshuffle64(BigEndian.Uint(x, 2*i, 2*w) << (64 - 2*w))
+		a, b := v >> 32, v & 0xffffffff
+		if inner {
+			a, b = b, a
+		}
+		BigEndian.PutUint(z, i, w, a >> (32 - w))
+		BigEndian.PutUint(z, h + i, w, b >> (32 - w))
+	}
+	return z
+}
+
+// Butterfly performs butterfly stage k on the bits of slice x,
+// placing the result in z, and returns z:
+// each pair of bits 2^k positions apart within an aligned block
+// of 2^(k+1) bits is exchanged if the first bit of the pair,
+// at the corresponding position in mask m, is set.
+// Bits of m at the second position of each pair are ignored.
+// Slices z and x may be the same slice but must not otherwise overlap.
+// The slices x and m must be of the same length;
+// otherwise panics with ErrLengthMismatch.
+// Panics with ErrOutOfRange if k is negative
+// or the length of x in bits is not a multiple of 2^(k+1).
+// Allocates and returns a new destination slice if z is not long enough.
+func Butterfly(z, x, m []byte, k int) []byte {
+	l := len2(x, m)
+	if k < 0 || k > 60 || (l * 8) & (1 << (k+1) - 1) != 0 {
+		panic(ErrOutOfRange)
+	}
+	z = Grow(z, l)
+
+	if k >= 6 {	// exchange masked 64-bit words between distant pairs
+		d := 1 << (k-3)			// pair distance in bytes
+		for i := 0; i < l; i += 2*d {
+			for j := i; j < i + d; j += 8 {
+				a := binary.BigEndian.Uint64(x[j:])
+				b := binary.BigEndian.Uint64(x[j+d:])
+				t := (a ^ b) & binary.BigEndian.Uint64(m[j:])
+				binary.BigEndian.PutUint64(z[j:], a ^ t)
+				binary.BigEndian.PutUint64(z[j+d:], b ^ t)
+			}
+		}
+		return z
+	}
+
+	for i := 0; i < l; i += 8 {	// exchange bits within 64-bit words
+		var xw, mw [8]byte
+		copy(xw[:], x[i:])
+		copy(mw[:], m[i:])
+		v := Butterfly64(binary.BigEndian.Uint64(xw[:]), k,
+				binary.BigEndian.Uint64(mw[:]))
+		binary.BigEndian.PutUint64(xw[:], v)
+		copy(z[i:l], xw[:])
+	}
+	return z
+}
@@ -0,0 +1,101 @@
+package bytebits
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+
+// refShuffle returns the outer or inner perfect shuffle of the n bits of x
+// computed one bit at a time.
+func refShuffle(x []byte, inner bool) []byte {
+	n := len(x) * 8
+	z := make([]byte, len(x))
+	for i := 0; i < n/2; i++ {
+		a, b := BigEndian.Bit(x, i), BigEndian.Bit(x, n/2 + i)
+		if inner {
+			a, b = b, a
+		}
+		BigEndian.PutBit(z, 2*i, a)
+		BigEndian.PutBit(z, 2*i + 1, b)
+	}
+	return z
+}
+
+func TestShuffle64(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		var b [8]byte
+		x := rand.Uint64()
+		BigEndian.PutUint64(b[:], 0, x)
+		for _, inner := range []bool{false, true} {
+			shuf, unshuf := OuterShuffle64, OuterUnshuffle64
+			if inner {
+				shuf, unshuf = InnerShuffle64, InnerUnshuffle64
+			}
+			want := BigEndian.Uint64(refShuffle(b[:], inner), 0)
+			if got := shuf(x); got != want {
+				t.Errorf("shuffle %x inner=%v: got %x, want %x",
+					x, inner, got, want)
+			}
+			if got := unshuf(want); got != x {
+				t.Errorf("unshuffle %x inner=%v: got %x, want %x",
+					want, inner, got, x)
+			}
+		}
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	for _, l := range []int{1, 3, 8, 9, 21} {
+		x := make([]byte, l)
+		rand.Read(x)
+		for _, inner := range []bool{false, true} {
+			shuf, unshuf := OuterShuffle, OuterUnshuffle
+			if inner {
+				shuf, unshuf = InnerShuffle, InnerUnshuffle
+			}
+			want := refShuffle(x, inner)
+			if got := shuf(nil, x); !bytes.Equal(got, want) {
+				t.Errorf("shuffle %x inner=%v: got %x, want %x",
+					x, inner, got, want)
+			}
+			if got := unshuf(nil, want); !bytes.Equal(got, x) {
+				t.Errorf("unshuffle %x inner=%v: got %x, want %x",
+					want, inner, got, x)
+			}
+		}
+	}
+}
+
+func TestButterfly(t *testing.T) {
+	x := make([]byte, 64)
+	m := make([]byte, 64)
+	rand.Read(x)
+	rand.Read(m)
+	for k := 0; k < 9; k++ {
+		d := 1 << k
+		want := make([]byte, len(x))
+		copy(want, x)
+		for i := 0; i < len(x)*8; i += 2*d {
+			for j := i; j < i + d; j++ {
+				if BigEndian.Bit(m, j) == 1 {
+					a, b := BigEndian.Bit(x, j), BigEndian.Bit(x, j+d)
+					BigEndian.PutBit(want, j, b)
+					BigEndian.PutBit(want, j+d, a)
+				}
+			}
+		}
+		if got := Butterfly(nil, x, m, k); !bytes.Equal(got, want) {
+			t.Errorf("Butterfly stage %v: got %x, want %x", k, got, want)
+		}
+		if k < 6 {
+			x0, m0 := BigEndian.Uint64(x, 0), BigEndian.Uint64(m, 0)
+			if got := Butterfly64(x0, k, m0); got !=
+					BigEndian.Uint64(want, 0) {
+				t.Errorf("Butterfly64 stage %v: got %x, want %x",
+					k, got, BigEndian.Uint64(want, 0))
+			}
+		}
+	}
+}
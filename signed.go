@@ -0,0 +1,156 @@
+package bytebits
+
+
+// Int extracts a signed integer in two's complement representation
+// w bits wide, where w is at most 64,
+// starting at bit position xofs in x, using the bit order given by order,
+// and returns it sign-extended to an int64.
+// Panics with ErrOutOfRange if w exceeds 64.
+func Int(order BitOrder, x []byte, xofs, w int) int64 {
+	return signExtend(order.Uint(x, xofs, w), w)
+}
+
+// PutInt sets the signed integer w bits wide starting at zofs in z
+// to the least-significant w bits of the two's complement representation
+// of v, where w is at most 64, using the bit order given by order,
+// and returns z.
+// Copies z and returns a new slice if z is nil or not large enough.
+// Panics with ErrOutOfRange if w exceeds 64.
+func PutInt(order BitOrder, z []byte, zofs, w int, v int64) []byte {
+	return order.PutUint(z, zofs, w, uint64(v))
+}
+
+// signExtend returns the w-bit two's complement integer
+// in the least-significant bits of v, sign-extended to 64 bits.
+func signExtend(v uint64, w int) int64 {
+	if w == 0 {
+		return 0
+	}
+	return int64(v << (64 - w)) >> (64 - w)
+}
+
+
+// SignedRep identifies a representation of signed integers,
+// as used by Int and PutInt methods that decode and encode
+// signed fields in representations other than two's complement.
+type SignedRep int
+
+const (
+	// TwosComplement is the usual two's complement representation.
+	TwosComplement SignedRep = iota
+
+	// OnesComplement negates a value by inverting all its bits,
+	// as in the Internet checksum, so it has a negative zero.
+	OnesComplement
+
+	// SignMagnitude holds a sign bit followed by the magnitude,
+	// as in legacy formats and floating-point significands,
+	// so it has a negative zero.
+	SignMagnitude
+
+	// OffsetBinary holds the value plus 2^(w-1) as an unsigned integer,
+	// as in the output of many ADCs,
+	// so that the most negative value is all zeros.
+	OffsetBinary
+)
+
+// String returns the name of the representation.
+func (rep SignedRep) String() string {
+	switch rep {
+	case TwosComplement:
+		return "TwosComplement"
+	case OnesComplement:
+		return "OnesComplement"
+	case SignMagnitude:
+		return "SignMagnitude"
+	case OffsetBinary:
+		return "OffsetBinary"
+	}
+	return "SignedRep(?)"
+}
+
+// Decode returns the w-bit signed integer in representation rep
+// held in the least-significant w bits of v, where w is from 1 to 64.
+// A negative zero decodes as zero.
+// Panics with ErrOutOfRange if w or rep is invalid.
+func (rep SignedRep) Decode(v uint64, w int) int64 {
+	if w < 1 || w > 64 {
+		panic(ErrOutOfRange)
+	}
+	v &= 1 << w - 1
+	neg := v >> (w-1) != 0
+	switch rep {
+	case TwosComplement:
+		return signExtend(v, w)
+	case OnesComplement:
+		if neg {
+			return -int64(^v & (1 << w - 1))
+		}
+		return int64(v)
+	case SignMagnitude:
+		m := int64(v & (1 << (w-1) - 1))
+		if neg {
+			return -m
+		}
+		return m
+	case OffsetBinary:
+		return signExtend(v ^ 1 << (w-1), w)
+	}
+	panic(ErrOutOfRange)
+}
+
+// Encode returns the encoding of v as a w-bit signed integer
+// in representation rep, in the least-significant w bits,
+// where w is from 1 to 64.
+// Zero encodes as a positive zero.
+// Panics with ErrOutOfRange if w or rep is invalid,
+// or if v is outside the range that rep can represent in w bits.
+func (rep SignedRep) Encode(v int64, w int) uint64 {
+	if w < 1 || w > 64 {
+		panic(ErrOutOfRange)
+	}
+	mask := uint64(1) << w - 1
+	max := int64(mask >> 1)
+	min := -max - 1
+	if rep == OnesComplement || rep == SignMagnitude {
+		min++
+	}
+	if v < min || v > max {
+		panic(ErrOutOfRange)
+	}
+	switch rep {
+	case TwosComplement:
+		return uint64(v) & mask
+	case OnesComplement:
+		if v < 0 {
+			return ^uint64(-v) & mask
+		}
+		return uint64(v)
+	case SignMagnitude:
+		if v < 0 {
+			return uint64(-v) | 1 << (w-1)
+		}
+		return uint64(v)
+	case OffsetBinary:
+		return (uint64(v) ^ 1 << (w-1)) & mask
+	}
+	panic(ErrOutOfRange)
+}
+
+// Int extracts a signed integer in representation rep w bits wide,
+// where w is from 1 to 64,
+// starting at bit position xofs in x, using the bit order given by order,
+// and returns it as an int64, as Decode does.
+func (rep SignedRep) Int(order BitOrder, x []byte, xofs, w int) int64 {
+	return rep.Decode(order.Uint(x, xofs, w), w)
+}
+
+// PutInt sets the signed integer in representation rep w bits wide
+// starting at zofs in z to v, where w is from 1 to 64,
+// using the bit order given by order, and returns z.
+// Copies z and returns a new slice if z is nil or not large enough.
+// Panics with ErrOutOfRange if v is not representable, as Encode does.
+func (rep SignedRep) PutInt(order BitOrder, z []byte, zofs, w int,
+		v int64) []byte {
+	return order.PutUint(z, zofs, w, rep.Encode(v, w))
+}
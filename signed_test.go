@@ -0,0 +1,81 @@
+package bytebits
+
+import (
+	"testing"
+)
+
+
+func TestInt(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, w := range []int{1, 2, 7, 13, 63, 64} {
+			for _, v := range []int64{0, -1, -1 << (w-1), 1 << (w-1) - 1} {
+				z := PutInt(order, []byte{0xff, 0xff}, 5, w, v)
+				if got := Int(order, z, 5, w); got != v {
+					t.Errorf("%v %v-bit Int of %v: got %v",
+						order, w, v, got)
+				}
+				if order.Uint(z, 0, 5) != 0x1f {
+					t.Errorf("%v PutInt clobbered preceding bits", order)
+				}
+			}
+		}
+	}
+	if v := Int(BigEndian, []byte{0xf8}, 1, 4); v != -1 {
+		t.Errorf("Int of 1111: got %v", v)
+	}
+	if v := Int(BigEndian, []byte{0x70}, 1, 4); v != -2 {
+		t.Errorf("Int of 1110: got %v", v)
+	}
+}
+
+func TestSignedRep(t *testing.T) {
+	// 4-bit encodings of -7, -1, 0, 5, and 7
+	for _, c := range []struct {
+		rep SignedRep
+		codes [5]uint64
+	}{
+		{TwosComplement, [5]uint64{0x9, 0xf, 0x0, 0x5, 0x7}},
+		{OnesComplement, [5]uint64{0x8, 0xe, 0x0, 0x5, 0x7}},
+		{SignMagnitude, [5]uint64{0xf, 0x9, 0x0, 0x5, 0x7}},
+		{OffsetBinary, [5]uint64{0x1, 0x7, 0x8, 0xd, 0xf}},
+	} {
+		for i, v := range []int64{-7, -1, 0, 5, 7} {
+			if e := c.rep.Encode(v, 4); e != c.codes[i] {
+				t.Errorf("%v Encode(%v): got %x, want %x",
+					c.rep, v, e, c.codes[i])
+			}
+			if d := c.rep.Decode(c.codes[i], 4); d != v {
+				t.Errorf("%v Decode(%x): got %v, want %v",
+					c.rep, c.codes[i], d, v)
+			}
+		}
+	}
+
+	// Negative zeros and extreme values
+	if OnesComplement.Decode(0xf, 4) != 0 || SignMagnitude.Decode(0x8, 4) != 0 {
+		t.Errorf("negative zero did not decode as zero")
+	}
+	for _, rep := range []SignedRep{TwosComplement, OffsetBinary} {
+		if d := rep.Decode(rep.Encode(-8, 4), 4); d != -8 {
+			t.Errorf("%v round trip of -8: got %v", rep, d)
+		}
+		if d := rep.Decode(rep.Encode(-1 << 63, 64), 64); d != -1 << 63 {
+			t.Errorf("%v round trip of MinInt64: got %v", rep, d)
+		}
+	}
+	if panicValue(func() { SignMagnitude.Encode(-8, 4) }) != ErrOutOfRange {
+		t.Errorf("SignMagnitude.Encode(-8) did not panic")
+	}
+
+	// Field accessors at unaligned offsets
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		z := SignMagnitude.PutInt(order, nil, 3, 12, -2047)
+		if v := SignMagnitude.Int(order, z, 3, 12); v != -2047 {
+			t.Errorf("%v SignMagnitude Int: got %v", order, v)
+		}
+		z = OffsetBinary.PutInt(order, z, 3, 12, -5)
+		if v := order.Uint(z, 3, 12); v != 0x7fb {
+			t.Errorf("%v OffsetBinary PutInt: got %x", order, v)
+		}
+	}
+}
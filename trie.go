@@ -0,0 +1,178 @@
+package bytebits
+
+
+// Trie is a path-compressed binary (Patricia) trie
+// mapping bit strings of arbitrary length to values.
+// Unlike a classic crit-bit tree, keys need not be prefix-free:
+// a trie may hold both a key and any number of its extensions,
+// which makes it suitable for routing tables and prefix dictionaries.
+//
+// Each node of the trie holds the complete key prefix leading to it,
+// and branches on the bit immediately following that prefix,
+// so a lookup visits at most one node per distinct branch point
+// along the path to the key.
+//
+// The zero value is an empty trie ready to use.
+// A Trie is not safe for concurrent modification.
+//
+type Trie struct {
+	root *trieNode
+	size int
+}
+
+type trieNode struct {
+	key BitString		// Complete key prefix leading to this node
+	val interface{}		// Value associated with key, if ok
+	ok bool			// Whether this node holds a value
+	child [2]*trieNode	// Subtries branching on the bit after key
+}
+
+// Len returns the number of keys in trie t.
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Insert associates value val with key in trie t,
+// replacing any value previously associated with the same key.
+func (t *Trie) Insert(key BitString, val interface{}) {
+	p := &t.root
+	for {
+		n := *p
+		if n == nil {		// fell off the trie: add a new leaf
+			*p = &trieNode{key: key, val: val, ok: true}
+			t.size++
+			return
+		}
+
+		c := commonPrefix(key, n.key)
+		if c < n.key.len {	// key diverges within n's prefix: split
+			m := &trieNode{key: key.prefix(c)}
+			m.child[n.key.Bit(c)] = n
+			if c == key.len {	// key is the split point itself
+				m.val, m.ok = val, true
+			} else {
+				m.child[key.Bit(c)] = &trieNode{key: key,
+							val: val, ok: true}
+			}
+			*p = m
+			t.size++
+			return
+		}
+
+		if c == key.len {	// n's key is exactly key
+			if !n.ok {
+				t.size++
+			}
+			n.val, n.ok = val, true
+			return
+		}
+		p = &n.child[key.Bit(c)]
+	}
+}
+
+// Lookup returns the value associated with key in trie t, if any,
+// and a boolean indicating whether the key was found.
+func (t *Trie) Lookup(key BitString) (val interface{}, ok bool) {
+	n := t.root
+	for n != nil && n.key.len <= key.len {
+		if commonPrefix(key, n.key) < n.key.len {
+			break
+		}
+		if n.key.len == key.len {
+			return n.val, n.ok
+		}
+		n = n.child[key.Bit(n.key.len)]
+	}
+	return nil, false
+}
+
+// LongestPrefixMatch finds the longest key in trie t
+// that is a prefix of (or identical to) the given key.
+// Returns the matching key and its associated value,
+// and a boolean indicating whether any matching key was found.
+func (t *Trie) LongestPrefixMatch(key BitString) (
+		match BitString, val interface{}, ok bool) {
+
+	n := t.root
+	for n != nil && n.key.len <= key.len {
+		if commonPrefix(key, n.key) < n.key.len {
+			break
+		}
+		if n.ok {
+			match, val, ok = n.key, n.val, true
+		}
+		if n.key.len == key.len {
+			break
+		}
+		n = n.child[key.Bit(n.key.len)]
+	}
+	return match, val, ok
+}
+
+// Delete removes key and its associated value from trie t.
+// Returns true if the key was present, or false if not.
+func (t *Trie) Delete(key BitString) bool {
+
+	// Find the node holding key and the links leading to it
+	var pp **trieNode	// link to n's parent
+	p := &t.root		// link to n
+	n := t.root
+	for n != nil && n.key.len < key.len {
+		if commonPrefix(key, n.key) < n.key.len {
+			return false
+		}
+		pp, p = p, &n.child[key.Bit(n.key.len)]
+		n = *p
+	}
+	if n == nil || n.key.len != key.len || !n.ok ||
+			commonPrefix(key, n.key) < key.len {
+		return false
+	}
+	n.val, n.ok = nil, false
+	t.size--
+
+	// Remove or bypass the node if it no longer holds a value
+	// or serves as a branch point between two subtries.
+	switch {
+	case n.child[0] != nil && n.child[1] != nil:
+		return true		// still a branch point
+	case n.child[0] != nil:
+		*p = n.child[0]
+	case n.child[1] != nil:
+		*p = n.child[1]
+	default:
+		*p = nil
+
+		// The parent may now be a valueless node with one child
+		if pp != nil {
+			m := *pp
+			if !m.ok {
+				if m.child[0] == nil {
+					*pp = m.child[1]
+				} else if m.child[1] == nil {
+					*pp = m.child[0]
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Walk calls fn for each key and associated value in trie t,
+// in bitwise lexicographic order of the keys,
+// with each key visited before all of its extensions.
+// Walk stops early if fn returns false.
+// The trie must not be modified during the walk.
+func (t *Trie) Walk(fn func(key BitString, val interface{}) bool) {
+	t.root.walk(fn)
+}
+
+func (n *trieNode) walk(fn func(key BitString, val interface{}) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.ok && !fn(n.key, n.val) {
+		return false
+	}
+	return n.child[0].walk(fn) && n.child[1].walk(fn)
+}
@@ -0,0 +1,115 @@
+package bytebits
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+
+// bitStringOf parses a string of '0' and '1' characters into a BitString.
+func bitStringOf(str string) BitString {
+	buf := make([]byte, (len(str)+7)/8)
+	for i, c := range str {
+		if c == '1' {
+			buf[i/8] |= 0x80 >> (i%8)
+		}
+	}
+	return MakeBitString(buf, 0, len(str))
+}
+
+// randomBitString returns a random string of '0' and '1' characters.
+func randomBitString(rnd *rand.Rand, maxLen int) string {
+	b := make([]byte, rnd.Intn(maxLen+1))
+	for i := range b {
+		b[i] = '0' + byte(rnd.Intn(2))
+	}
+	return string(b)
+}
+
+func TestTrie(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var tr Trie
+	ref := make(map[string]int)
+
+	check := func(key string) {
+		val, ok := tr.Lookup(bitStringOf(key))
+		rv, rok := ref[key]
+		if ok != rok || (ok && val.(int) != rv) {
+			t.Fatalf("Lookup(%q) = %v,%v, want %v,%v",
+				key, val, ok, rv, rok)
+		}
+
+		// Brute-force the longest prefix match
+		best, bestOK := "", false
+		for k := range ref {
+			if strings.HasPrefix(key, k) &&
+					(!bestOK || len(k) > len(best)) {
+				best, bestOK = k, true
+			}
+		}
+		m, val, ok := tr.LongestPrefixMatch(bitStringOf(key))
+		if ok != bestOK || (ok && (m.String() != best ||
+				val.(int) != ref[best])) {
+			t.Fatalf("LongestPrefixMatch(%q) = %v,%v, want %v,%v",
+				key, m, ok, best, bestOK)
+		}
+	}
+
+	for i := 0; i < 3000; i++ {
+		key := randomBitString(rnd, 12)
+		switch rnd.Intn(3) {
+		case 0, 1:
+			tr.Insert(bitStringOf(key), i)
+			ref[key] = i
+		case 2:
+			_, want := ref[key]
+			if got := tr.Delete(bitStringOf(key)); got != want {
+				t.Fatalf("Delete(%q) = %v, want %v",
+					key, got, want)
+			}
+			delete(ref, key)
+		}
+		if tr.Len() != len(ref) {
+			t.Fatalf("Len() = %v, want %v", tr.Len(), len(ref))
+		}
+		check(randomBitString(rnd, 14))
+	}
+
+	// Walk should visit all keys in lexicographic order
+	prev, n := "", 0
+	tr.Walk(func(key BitString, val interface{}) bool {
+		s := key.String()
+		if n > 0 && s <= prev {
+			t.Errorf("Walk visited %q after %q", s, prev)
+		}
+		if ref[s] != val.(int) {
+			t.Errorf("Walk: key %q has value %v, want %v",
+				s, val, ref[s])
+		}
+		prev, n = s, n+1
+		return true
+	})
+	if n != len(ref) {
+		t.Errorf("Walk visited %v keys, want %v", n, len(ref))
+	}
+}
+
+func TestSortBitStrings(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	strs := make([]string, 200)
+	bs := make([]BitString, len(strs))
+	for i := range strs {
+		strs[i] = randomBitString(rnd, 20)
+		bs[i] = bitStringOf(strs[i])
+	}
+	sort.Strings(strs)
+	SortBitStrings(bs)
+	for i := range strs {
+		if bs[i].String() != strs[i] {
+			t.Errorf("sorted bit string %v is %v, want %v",
+				i, bs[i], strs[i])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package bytebits
+
+import (
+	"encoding/binary"
+)
+
+
+// The varints here use the LEB128 layout of encoding/binary,
+// a sequence of 8-bit groups each holding a continuation bit
+// and the next 7 bits of the value, least-significant group first.
+// Each group is written as one 8-bit unit in the stream's bit order,
+// so that a byte-aligned varint in either bit order
+// is identical to the encoding binary.PutUvarint produces,
+// while an unaligned one can be embedded anywhere in a bit-packed record.
+
+
+// WriteUvarint writes v to bit stream w as an unsigned varint
+// of 8-bit groups, in the layout of binary.PutUvarint.
+func WriteUvarint(w BitWriter, v uint64) error {
+	for v >= 0x80 {
+		if err := w.WriteBits(8, v & 0x7f | 0x80); err != nil {
+			return err
+		}
+		v >>= 7
+	}
+	return w.WriteBits(8, v)
+}
+
+// ReadUvarint reads an unsigned varint written by WriteUvarint
+// from bit stream r.
+// Returns ErrInvalidCode if the varint overflows 64 bits,
+// and io.ErrUnexpectedEOF if the stream ends within it.
+func ReadUvarint(r BitReader) (uint64, error) {
+	b, err := r.ReadBits(8)
+	var v uint64
+	for i := 0; err == nil; i++ {
+		if i == binary.MaxVarintLen64 - 1 && b > 1 {
+			return 0, ErrInvalidCode
+		}
+		v |= (b & 0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, nil
+		}
+		b, err = readRest(r, 8)
+	}
+	return 0, err
+}
+
+// WriteVarint writes v to bit stream w as a signed varint,
+// zigzag-encoded in the layout of binary.PutVarint.
+func WriteVarint(w BitWriter, v int64) error {
+	return WriteUvarint(w, uint64(v) << 1 ^ uint64(v >> 63))
+}
+
+// ReadVarint reads a signed varint written by WriteVarint
+// from bit stream r, reporting errors as ReadUvarint does.
+func ReadVarint(r BitReader) (int64, error) {
+	u, err := ReadUvarint(r)
+	return int64(u >> 1) ^ -int64(u & 1), err
+}
+
+
+// PutUvarint writes v as an unsigned varint at bit offset zofs in slice z,
+// in the layout that WriteUvarint produces using bit order order,
+// and returns z and the number of bits written.
+// Copies z and returns a new slice if z is nil or not large enough.
+func PutUvarint(order BitOrder, z []byte, zofs int, v uint64) ([]byte, int) {
+	n := 0
+	for v >= 0x80 {
+		z = order.PutUint(z, zofs + n, 8, v & 0x7f | 0x80)
+		v >>= 7
+		n += 8
+	}
+	return order.PutUint(z, zofs + n, 8, v), n + 8
+}
+
+// Uvarint decodes an unsigned varint at bit offset xofs in slice x,
+// in the layout that PutUvarint produces using bit order order,
+// and returns its value and the number of bits read.
+// As with binary.Uvarint, the bit count is 0 if x ends within the varint,
+// or negative if it overflows 64 bits, giving the bits read so far.
+func Uvarint(order BitOrder, x []byte, xofs int) (uint64, int) {
+	var v uint64
+	for i := 0; i < binary.MaxVarintLen64; i++ {
+		n := 8 * i
+		if xofs + n + 8 > len(x) * 8 {
+			return 0, 0
+		}
+		b := order.Uint(x, xofs + n, 8)
+		if i == binary.MaxVarintLen64 - 1 && b > 1 {
+			return 0, -(n + 8)
+		}
+		v |= (b & 0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, n + 8
+		}
+	}
+	return 0, -8 * binary.MaxVarintLen64
+}
+
+// PutVarint writes v as a zigzag-encoded signed varint
+// at bit offset zofs in slice z, as PutUvarint does.
+func PutVarint(order BitOrder, z []byte, zofs int, v int64) ([]byte, int) {
+	return PutUvarint(order, z, zofs, uint64(v) << 1 ^ uint64(v >> 63))
+}
+
+// Varint decodes a signed varint at bit offset xofs in slice x,
+// as Uvarint does.
+func Varint(order BitOrder, x []byte, xofs int) (int64, int) {
+	u, n := Uvarint(order, x, xofs)
+	return int64(u >> 1) ^ -int64(u & 1), n
+}
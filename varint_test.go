@@ -0,0 +1,81 @@
+package bytebits
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+
+var varintTests = []int64{0, 1, -1, 63, -64, 64, 300, -300,
+	1 << 35, math.MaxInt64, math.MinInt64}
+
+func TestVarint(t *testing.T) {
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		// Byte-aligned varints match encoding/binary
+		var out bytes.Buffer
+		w := order.NewWriter(&out)
+		var want []byte
+		for _, v := range varintTests {
+			WriteUvarint(w, uint64(v))
+			WriteVarint(w, v)
+			want = binary.AppendUvarint(want, uint64(v))
+			want = binary.AppendVarint(want, v)
+		}
+		w.Flush()
+		if !bytes.Equal(out.Bytes(), want) {
+			t.Errorf("%v aligned varints: got %x, want %x",
+				order, out.Bytes(), want)
+		}
+
+		// Unaligned varints round-trip through streams and slices
+		out.Reset()
+		w = order.NewWriter(&out)
+		w.WriteBits(3, 5)
+		for _, v := range varintTests {
+			WriteVarint(w, v)
+			w.WriteBits(1, 1)
+		}
+		w.Flush()
+		r := order.NewReader(bytes.NewReader(out.Bytes()))
+		r.ReadBits(3)
+		ofs := 3
+		for _, v := range varintTests {
+			if got, err := ReadVarint(r); got != v || err != nil {
+				t.Errorf("%v ReadVarint: got %v, %v, want %v",
+					order, got, err, v)
+			}
+			r.ReadBits(1)
+			got, n := Varint(order, out.Bytes(), ofs)
+			if got != v || n != 8 * len(binary.AppendVarint(nil, v)) {
+				t.Errorf("%v Varint at %v: got %v, %v, want %v",
+					order, ofs, got, n, v)
+			}
+			z, m := PutVarint(order, nil, ofs, v)
+			if m != n || !order.Field(z, ofs, m).Equal(
+					order.Field(out.Bytes(), ofs, m)) {
+				t.Errorf("%v PutVarint(%v) at %v: got %x", order, v, ofs, z)
+			}
+			ofs += n + 1
+		}
+	}
+
+	// Truncated and overflowing varints
+	r := NewReader(bytes.NewReader([]byte{0x80, 0x80}))
+	if _, err := ReadUvarint(r); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated ReadUvarint: got %v", err)
+	}
+	if _, n := Uvarint(BigEndian, []byte{0x80, 0x80}, 0); n != 0 {
+		t.Errorf("truncated Uvarint: got %v bits", n)
+	}
+	over := bytes.Repeat([]byte{0xff}, 10)
+	if _, err := ReadUvarint(NewReader(bytes.NewReader(over)));
+			err != ErrInvalidCode {
+		t.Errorf("overflowing ReadUvarint: got %v", err)
+	}
+	if _, n := Uvarint(BigEndian, over, 0); n != -80 {
+		t.Errorf("overflowing Uvarint: got %v bits", n)
+	}
+}
@@ -0,0 +1,86 @@
+package bytebits
+
+import (
+	"io"
+	"math/bits"
+)
+
+
+// VINTUnknown is the value ReadVINT returns for, and WriteVINT accepts as,
+// the reserved EBML variable-size integer with all value bits set to one,
+// which denotes an element of unknown size.
+const VINTUnknown = ^uint64(0)
+
+// VINTMax is the largest value encodable as an EBML variable-size integer.
+const VINTMax = 1 << 56 - 2
+
+
+// ReadVINT reads an EBML variable-size integer, as used in Matroska,
+// from bit stream r, which must deliver its bits most-significant first.
+// The number of leading zero bits before the first one bit
+// gives the number of bytes following the first byte,
+// and the marker bit itself is not part of the returned value.
+// Returns VINTUnknown if all of the integer's value bits are one.
+// Returns ErrInvalidCode if the first byte is zero,
+// and io.ErrUnexpectedEOF if the stream ends within the integer.
+func ReadVINT(r BitReader) (uint64, error) {
+	b, err := r.ReadBits(8)
+	if err != nil {
+		return 0, err
+	}
+	l := bits.LeadingZeros8(uint8(b))	// length minus one, in bytes
+	if l == 8 {
+		return 0, ErrInvalidCode
+	}
+	v := b & (0xff >> (l + 1))
+	if l > 0 {
+		rest, err := r.ReadBits(8 * l)
+		if err == EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		v = v << (8 * l) | rest
+	}
+	if v == 1 << (7 * (l + 1)) - 1 {
+		return VINTUnknown, nil
+	}
+	return v, nil
+}
+
+// WriteVINT writes v as an EBML variable-size integer to bit stream w,
+// most-significant bit first, using the shortest possible encoding
+// that does not collide with the reserved all-ones value.
+// Writes VINTUnknown as the one-byte reserved encoding 0xff.
+// Returns ErrOutOfRange if v is greater than VINTMax.
+func WriteVINT(w BitWriter, v uint64) error {
+	if v == VINTUnknown {
+		return w.WriteBits(8, 0xff)
+	}
+	l := 1
+	for l <= 8 && v >= 1 << (7 * l) - 1 {
+		l++
+	}
+	return WriteVINTSize(w, v, l)
+}
+
+// WriteVINTSize writes v as an EBML variable-size integer
+// l bytes long, from 1 to 8, to bit stream w,
+// as needed when reserving space for a size that is backfilled later.
+// Writes VINTUnknown as the l-byte reserved encoding.
+// Returns ErrOutOfRange if l is not in the range 1 to 8,
+// or if v does not fit in l bytes
+// without colliding with the reserved all-ones value.
+func WriteVINTSize(w BitWriter, v uint64, l int) error {
+	if l < 1 || l > 8 {
+		return ErrOutOfRange
+	}
+	max := uint64(1) << (7 * l) - 1
+	if v == VINTUnknown {
+		v = max
+	} else if v >= max {
+		return ErrOutOfRange
+	}
+	return w.WriteBits(8 * l, v | 1 << (7 * l))
+}
@@ -0,0 +1,61 @@
+package bytebits
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+
+func TestVINT(t *testing.T) {
+	encodings := []struct {
+		v uint64
+		enc []byte
+	}{
+		{0, []byte{0x80}},
+		{1, []byte{0x81}},
+		{126, []byte{0xfe}},
+		{127, []byte{0x40, 0x7f}},
+		{0x3ffe, []byte{0x7f, 0xfe}},
+		{0x3fff, []byte{0x20, 0x3f, 0xff}},
+		{VINTMax, []byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}},
+		{VINTUnknown, []byte{0xff}},
+	}
+	for _, e := range encodings {
+		buf := make([]byte, len(e.enc) + 1)
+		var f BigEndianField
+		f.Init(buf, 3, len(e.enc) * 8)
+		if err := WriteVINT(&f, e.v); err != nil {
+			t.Fatalf("WriteVINT(%x): %v", e.v, err)
+		}
+		if f.w != 0 || !bytes.Equal(BigEndian.Copy(nil, buf, 0, 3,
+				len(e.enc) * 8), e.enc) {
+			t.Errorf("WriteVINT(%x): got %x, want %x",
+				e.v, buf, e.enc)
+		}
+
+		f.Init(buf, 3, len(e.enc) * 8)
+		if v, err := ReadVINT(&f); err != nil || v != e.v {
+			t.Errorf("ReadVINT(%x): got %x, %v, want %x",
+				e.enc, v, err, e.v)
+		}
+	}
+
+	// Reserved values of any length read as unknown
+	var f BigEndianField
+	f.Init([]byte{0x3f, 0xff, 0xff}, 0, 24)
+	if v, err := ReadVINT(&f); err != nil || v != VINTUnknown {
+		t.Errorf("ReadVINT(3fffff): got %x, %v, want unknown", v, err)
+	}
+	f.Init([]byte{0x00, 0x81}, 0, 16)
+	if _, err := ReadVINT(&f); err != ErrInvalidCode {
+		t.Errorf("ReadVINT(00): got %v, want ErrInvalidCode", err)
+	}
+	f.Init([]byte{0x40}, 0, 8)
+	if _, err := ReadVINT(&f); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadVINT(40): got %v, want ErrUnexpectedEOF", err)
+	}
+	if err := WriteVINT(&f, VINTMax + 1); err != ErrOutOfRange {
+		t.Errorf("WriteVINT(VINTMax+1): got %v, want ErrOutOfRange", err)
+	}
+}
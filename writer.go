@@ -0,0 +1,379 @@
+package bytebits
+
+import (
+	"bufio"
+	"io"
+)
+
+
+// Writer is a BitWriter that writes bits to an underlying io.Writer,
+// buffering them so that bitstreams can be produced incrementally.
+// A Writer created by NewWriter or BigEndian.NewWriter
+// writes the bits of each byte most-significant first,
+// taking the first bit written from the most-significant position,
+// while one created by LittleEndian.NewWriter writes them
+// least-significant first,
+// taking the first bit written from the least-significant position.
+// After all bits have been written, the client should call Flush or Close
+// to pad any trailing partial byte and write it out.
+// The Writer pads with zero bits unless configured otherwise
+// with SetPadding.
+//
+type Writer struct {
+	wr io.Writer		// Underlying byte stream
+	buf []byte		// Buffered bytes, the last possibly partial
+	pos int			// Bit offset of the next bit to write in buf
+	base int64		// Stream bit offset of buf[0]
+	err error		// Sticky error
+	lsb bool		// Write bits least-significant first
+	pad Padding		// Final partial byte policy
+	pinned bool		// Buffer pinned from mark on by Checkpoint
+	mark int64		// Earliest stream bit offset checkpointed
+}
+
+// WriterCheckpoint records a Writer's position for a later Rollback.
+type WriterCheckpoint struct {
+	off int64		// Stream bit offset
+}
+
+// NewWriter returns a new Writer writing to wr,
+// with a default-sized buffer,
+// that writes the bits of each byte most-significant first.
+func NewWriter(wr io.Writer) *Writer {
+	return BigEndian.NewWriter(wr)
+}
+
+// NewWriterSize returns a new Writer writing to wr
+// with a buffer of at least size bytes,
+// that writes the bits of each byte most-significant first.
+func NewWriterSize(wr io.Writer, size int) *Writer {
+	return BigEndian.NewWriterSize(wr, size)
+}
+
+// NewWriter returns a new Writer writing to wr,
+// with a default-sized buffer,
+// that writes the bits of each byte most-significant first.
+// If wr is a *bufio.Writer, the Writer uses only a minimal buffer
+// of its own, to avoid buffering the data twice.
+func (be BigEndianOrder) NewWriter(wr io.Writer) *Writer {
+	return be.NewWriterSize(wr, writerSize(wr))
+}
+
+// NewWriterSize returns a new Writer writing to wr
+// with a buffer of at least size bytes,
+// that writes the bits of each byte most-significant first.
+func (_ BigEndianOrder) NewWriterSize(wr io.Writer, size int) *Writer {
+	return newWriter(wr, size, false)
+}
+
+// NewWriter returns a new Writer writing to wr,
+// with a default-sized buffer,
+// that writes the bits of each byte least-significant first.
+// If wr is a *bufio.Writer, the Writer uses only a minimal buffer
+// of its own, to avoid buffering the data twice.
+func (le LittleEndianOrder) NewWriter(wr io.Writer) *Writer {
+	return le.NewWriterSize(wr, writerSize(wr))
+}
+
+// NewWriterSize returns a new Writer writing to wr
+// with a buffer of at least size bytes,
+// that writes the bits of each byte least-significant first.
+func (_ LittleEndianOrder) NewWriterSize(wr io.Writer, size int) *Writer {
+	return newWriter(wr, size, true)
+}
+
+// writerSize returns the default buffer size for a Writer writing to wr.
+func writerSize(wr io.Writer) int {
+	if _, ok := wr.(*bufio.Writer); ok {
+		return minBufSize
+	}
+	return defaultBufSize
+}
+
+func newWriter(wr io.Writer, size int, lsb bool) *Writer {
+	if size < minBufSize {
+		size = minBufSize
+	}
+	return &Writer{wr: wr, buf: make([]byte, size), lsb: lsb}
+}
+
+// WriteBits implements the BitWriter interface,
+// writing the least-significant n bits of v, or 64 bits maximum.
+// Returns any error encountered writing to the underlying writer,
+// in which case this and all further writes fail.
+func (w *Writer) WriteBits(n int, v uint64) error {
+	if n > 64 {
+		n = 64
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if w.pos + n > len(w.buf) * 8 {
+		if w.writeBytes(); w.err != nil {
+			return w.err
+		}
+	}
+	w.put(n, v)
+	return nil
+}
+
+// WriteBytes writes all of p, 8*len(p) bits,
+// starting at the current bit position, which need not be byte-aligned.
+// Bits are copied in blocks of up to 64 rather than byte by byte,
+// and in the Writer's bit order, as for Reader.ReadBytes.
+// Returns the number of bytes written and any error encountered
+// writing buffered data to the underlying writer.
+func (w *Writer) WriteBytes(p []byte) (n int, err error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	for n < len(p) {
+		k := (len(w.buf) * 8 - w.pos) >> 3	// whole bytes of room
+		if k == 0 {
+			if w.writeBytes(); w.err != nil {
+				return n, w.err
+			}
+			continue
+		}
+		if k > len(p) - n {
+			k = len(p) - n
+		}
+		zb, zo := w.buf[w.pos >> 3:], w.pos & 7
+		switch {
+		case zo == 0:
+			copy(zb, p[n:n + k])
+		case w.lsb:
+			leCopy(zb, p[n:], zo, 0, k * 8)
+		default:
+			beCopy(zb, p[n:], zo, 0, k * 8)
+		}
+		w.pos += k * 8
+		n += k
+	}
+	return n, nil
+}
+
+// WriteBool writes a single bit, 1 if b is true or 0 if false.
+func (w *Writer) WriteBool(b bool) error {
+	return WriteBool(w, b)
+}
+
+// put stores n bits, at most 64, into the buffer in the writer's bit order.
+// The buffer must have room for them.
+func (w *Writer) put(n int, v uint64) {
+	if w.lsb {
+		lePut(w.buf[w.pos >> 3:], w.pos & 7, n, v)
+	} else {
+		bePut(w.buf[w.pos >> 3:], w.pos & 7, n, v)
+	}
+	w.pos += n
+}
+
+// writeBytes writes all complete buffered bytes to the underlying writer,
+// keeping any trailing partial byte in the buffer,
+// along with any bytes a Checkpoint has pinned,
+// and grows the buffer if pinned bytes leave too little room.
+func (w *Writer) writeBytes() {
+	k := w.pos >> 3
+	if w.pinned && int64(k) > (w.mark - w.base) >> 3 {
+		k = int((w.mark - w.base) >> 3)
+	}
+	m, err := w.wr.Write(w.buf[:k])
+	if m < k && err == nil {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.base += int64(k) * 8
+	copy(w.buf, w.buf[k:(w.pos + 7) >> 3])	// keep the remaining bytes
+	w.pos -= k * 8
+	if len(w.buf) * 8 - w.pos < 64 {
+		w.buf = append(w.buf, make([]byte, len(w.buf))...)
+	}
+}
+
+// SetPadding sets the policy that Flush, Close and Align apply
+// to complete a trailing partial byte:
+// PadZeros (the default), PadOnes, or any other byte pattern
+// as described for Padding.
+// With Truncate, Flush writes only whole bytes,
+// holding back a trailing partial byte until further bits complete it,
+// and Close discards the partial byte, omitting it from the stream.
+// Align, which cannot discard bits, pads with zeros under Truncate.
+func (w *Writer) SetPadding(p Padding) {
+	w.pad = p
+}
+
+// Checkpoint returns the Writer's current position,
+// to which a later call to Rollback can return,
+// abandoning everything written since,
+// as an encoder might before writing an element that could prove too large.
+// Until Commit or Flush is called, the Writer holds back
+// all data from the earliest checkpoint onward,
+// growing its buffer as needed.
+func (w *Writer) Checkpoint() WriterCheckpoint {
+	off := w.Offset()
+	if !w.pinned || off < w.mark {
+		w.pinned, w.mark = true, off
+	}
+	return WriterCheckpoint{off}
+}
+
+// Rollback returns the Writer to position c, previously returned by
+// Checkpoint, discarding all bits written since.
+// Rollback does not commit the checkpoint,
+// so the same position may be rolled back to more than once.
+// Returns ErrOutOfRange if the data at c has already been written
+// to the underlying writer, because of an intervening Commit or Flush,
+// or any error that previously caused a write to fail.
+func (w *Writer) Rollback(c WriterCheckpoint) error {
+	if w.err != nil {
+		return w.err
+	}
+	if c.off < w.base || c.off > w.Offset() {
+		return ErrOutOfRange
+	}
+	w.pos = int(c.off - w.base)
+	return nil
+}
+
+// Commit discards all positions recorded with Checkpoint,
+// allowing the Writer to write out the data it was holding back.
+func (w *Writer) Commit() {
+	w.pinned = false
+}
+
+// Flush pads any trailing partial byte according to the padding policy,
+// so that the next bit written starts a new byte,
+// and writes all buffered data to the underlying writer,
+// committing any checkpoints as Commit does.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.pad != Truncate {
+		w.padByte()
+	}
+	w.pinned = false
+	w.writeBytes()
+	return w.err
+}
+
+// Close flushes the Writer as Flush does,
+// after which further writes fail with ErrClosed.
+// Close does not close the underlying writer.
+// A client needing to know the number of pad bits
+// may call Align before Close.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.err == nil {
+		w.pos &^= 7	// discard a partial byte held back by Truncate
+		w.err = ErrClosed
+	}
+	return err
+}
+
+// Align pads the current byte, if any, according to the padding policy,
+// so that the next write starts at a byte boundary in the stream,
+// and returns the number of bits written, from 0 to 7,
+// and any error encountered.
+// Unlike Flush, Align does not write buffered data
+// to the underlying writer.
+func (w *Writer) Align() (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.padByte(), nil
+}
+
+// padByte completes a trailing partial byte in the buffer
+// with bits from the padding pattern, or zeros under Truncate,
+// and returns the number of bits added.
+func (w *Writer) padByte() int {
+	r := w.pos & 7
+	if r == 0 {
+		return 0
+	}
+	var v uint64
+	if w.pad != Truncate {
+		v = w.pad.bits(r, w.lsb)
+	}
+	w.put(8 - r, v)
+	return 8 - r
+}
+
+// Offset returns the number of bits written to the stream so far,
+// including buffered bits and any padding written by Align or Flush.
+func (w *Writer) Offset() int64 {
+	return w.base + int64(w.pos)
+}
+
+
+// AppendWriter is a BitWriter that appends bits directly to a byte slice,
+// for small encoders that build bit-packed messages in memory
+// without the overhead of an io.Writer or a separate buffer.
+// A trailing partial byte is kept padded with zero bits,
+// so Bytes always returns a complete encoding.
+// AppendWriters created by NewAppendWriter or BigEndian.NewAppendWriter
+// fill each byte most-significant bit first,
+// while those created by LittleEndian.NewAppendWriter
+// fill each byte least-significant bit first.
+type AppendWriter struct {
+	b []byte		// Bytes appended to, the last possibly partial
+	pos int			// Bit offset of the next bit to write in b
+	lsb bool		// Write bits least-significant first
+}
+
+// NewAppendWriter returns an AppendWriter appending to b,
+// starting at the byte following the last byte of b,
+// that fills each byte most-significant bit first.
+func NewAppendWriter(b []byte) *AppendWriter {
+	return BigEndian.NewAppendWriter(b)
+}
+
+// NewAppendWriter returns an AppendWriter appending to b,
+// starting at the byte following the last byte of b,
+// that fills each byte most-significant bit first.
+func (_ BigEndianOrder) NewAppendWriter(b []byte) *AppendWriter {
+	return &AppendWriter{b: b, pos: len(b) * 8}
+}
+
+// NewAppendWriter returns an AppendWriter appending to b,
+// starting at the byte following the last byte of b,
+// that fills each byte least-significant bit first.
+func (_ LittleEndianOrder) NewAppendWriter(b []byte) *AppendWriter {
+	return &AppendWriter{b: b, pos: len(b) * 8, lsb: true}
+}
+
+// WriteBits implements the BitWriter interface,
+// appending the least-significant n bits of v, or 64 bits maximum.
+// Grows the slice as needed, as Grow does, and never fails.
+func (w *AppendWriter) WriteBits(n int, v uint64) error {
+	if n > 64 {
+		n = 64
+	}
+	w.b = appendGrow(w.b, w.pos, n)
+	if w.lsb {
+		lePut(w.b[w.pos >> 3:], w.pos & 7, n, v)
+	} else {
+		bePut(w.b[w.pos >> 3:], w.pos & 7, n, v)
+	}
+	w.pos += n
+	return nil
+}
+
+// Bytes returns the slice appended to,
+// including the original contents passed to NewAppendWriter
+// and any trailing partial byte padded with zero bits.
+// The slice is valid until the next write.
+func (w *AppendWriter) Bytes() []byte {
+	return w.b
+}
+
+// Len returns the number of bits in the slice returned by Bytes,
+// excluding the padding of any trailing partial byte.
+func (w *AppendWriter) Len() int {
+	return w.pos
+}
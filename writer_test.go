@@ -0,0 +1,320 @@
+package bytebits
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+
+// failWriter is an io.Writer whose writes always fail.
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriter(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	ofs := 0
+	for i := 0; ofs < len(data) * 8 - 3; i++ {
+		n := (i * 37) % 65
+		if n > len(data) * 8 - 3 - ofs {
+			n = len(data) * 8 - 3 - ofs
+		}
+		if err := w.WriteBits(n, BigEndian.Uint(data, ofs, n)); err != nil {
+			t.Fatalf("WriteBits(%v) at %v: %v", n, ofs, err)
+		}
+		ofs += n
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	want := append([]byte(nil), data...)
+	want[len(want)-1] &^= 7		// last three bits padded with zeros
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("Writer output differs from input")
+	}
+	if err := w.WriteBits(1, 1); err != ErrClosed {
+		t.Errorf("WriteBits after Close: got %v, want ErrClosed", err)
+	}
+
+	// Flush pads to a byte boundary but allows writing to continue
+	out.Reset()
+	w = NewWriter(&out)
+	w.WriteBits(4, 0xa)
+	w.Flush()
+	w.WriteBits(12, 0xbcd)
+	w.Flush()
+	if !bytes.Equal(out.Bytes(), []byte{0xa0, 0xbc, 0xd0}) {
+		t.Errorf("Flush: got %x, want a0bcd0", out.Bytes())
+	}
+
+	// Errors from the underlying writer are sticky
+	w = NewWriter(failWriter{})
+	w.WriteBits(8, 0xff)
+	if err := w.Flush(); err == nil || w.WriteBits(1, 0) == nil {
+		t.Errorf("write errors not reported")
+	}
+}
+
+func TestWriterLSBFirst(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.Read(data)
+	var out bytes.Buffer
+	w := LittleEndian.NewWriter(&out)
+	ofs := 0
+	for i := 0; ofs < len(data) * 8; i++ {
+		n := (i * 29) % 65
+		if n > len(data) * 8 - ofs {
+			n = len(data) * 8 - ofs
+		}
+		w.WriteBits(n, LittleEndian.Uint(data, ofs, n))
+		ofs += n
+	}
+	w.WriteBits(3, 0x5)
+	if err := w.Flush(); err != nil ||
+			!bytes.Equal(out.Bytes(), append(data, 0x05)) {
+		t.Errorf("LSB-first Writer output differs from input")
+	}
+}
+
+// countWriter counts the calls made to its Write method.
+type countWriter struct {
+	bytes.Buffer
+	calls int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.calls++
+	return w.Buffer.Write(p)
+}
+
+func TestWriterSize(t *testing.T) {
+	var out countWriter
+	w := NewWriterSize(&out, 1000)
+	for i := 0; i < 8000; i++ {
+		w.WriteBits(8, uint64(i))
+	}
+	w.Flush()
+	if out.Len() != 8000 || out.calls > 9 {
+		t.Errorf("wrote %v bytes in %v calls", out.Len(), out.calls)
+	}
+
+	// A bufio.Writer is wrapped with only a minimal extra buffer
+	out = countWriter{}
+	bw := bufio.NewWriter(&out)
+	w = LittleEndian.NewWriter(bw)
+	if len(w.buf) != minBufSize {
+		t.Errorf("buffer of %v bytes over bufio.Writer", len(w.buf))
+	}
+	for i := 0; i < 100; i++ {
+		w.WriteBits(13, uint64(i))
+	}
+	w.Flush()
+	bw.Flush()
+	r := LittleEndian.NewReader(&out.Buffer)
+	for i := 0; i < 100; i++ {
+		if v, err := r.ReadBits(13); err != nil || v != uint64(i) {
+			t.Fatalf("value %v: got %v, %v", i, v, err)
+		}
+	}
+}
+
+func TestWriterAlign(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	if n, err := w.Align(); n != 0 || err != nil {
+		t.Errorf("Align at start: got %v, %v", n, err)
+	}
+	w.WriteBits(3, 0x7)
+	if n, err := w.Align(); n != 5 || err != nil {
+		t.Errorf("Align after 3 bits: got %v, %v, want 5", n, err)
+	}
+	w.WriteBits(8, 0xcd)
+	if out.Len() != 0 {
+		t.Errorf("Align wrote to the underlying writer")
+	}
+	w.Flush()
+	if !bytes.Equal(out.Bytes(), []byte{0xe0, 0xcd}) {
+		t.Errorf("Align: got %x, want e0cd", out.Bytes())
+	}
+}
+
+func TestWriterOffset(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriterSize(&out, 100)
+	want := int64(0)
+	for i := 0; i < 1000; i++ {
+		n := (i * 37) % 65
+		w.WriteBits(n, 0)
+		want += int64(n)
+		if i % 5 == 0 {
+			a, _ := w.Align()
+			want += int64(a)
+		}
+		if o := w.Offset(); o != want {
+			t.Fatalf("Offset after %v writes: got %v, want %v", i+1, o, want)
+		}
+	}
+	w.Flush()
+	if w.Offset() != int64(out.Len()) * 8 {
+		t.Errorf("Offset after Flush: got %v for %v bytes",
+			w.Offset(), out.Len())
+	}
+}
+
+func TestWriterWriteBytes(t *testing.T) {
+	data := make([]byte, 5000)
+	rand.Read(data)
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		for _, skip := range []int{0, 3, 8, 13} {
+			var got, want bytes.Buffer
+			w := order.NewWriterSize(&got, 100)
+			ref := order.NewWriter(&want)
+			w.WriteBits(skip, 0x1555)
+			ref.WriteBits(skip, 0x1555)
+			if n, err := w.WriteBytes(data); n != len(data) || err != nil {
+				t.Fatalf("WriteBytes: got %v, %v", n, err)
+			}
+			for _, b := range data {
+				ref.WriteBits(8, uint64(b))
+			}
+			w.WriteBits(5, 0x11)
+			ref.WriteBits(5, 0x11)
+			w.Flush()
+			ref.Flush()
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Errorf("%v WriteBytes after %v bits: wrong output",
+					order, skip)
+			}
+		}
+	}
+
+	w := NewWriterSize(failWriter{}, 32)
+	if n, err := w.WriteBytes(data); err == nil || n >= len(data) {
+		t.Errorf("WriteBytes to failing writer: got %v, %v", n, err)
+	}
+}
+
+func TestWriterPadding(t *testing.T) {
+	for _, c := range []struct {
+		order BitOrder
+		pad Padding
+		want []byte
+	}{
+		{BigEndian, PadZeros, []byte{0xab, 0xa0}},
+		{BigEndian, PadOnes, []byte{0xab, 0xbf}},
+		{BigEndian, Padding(0x55), []byte{0xab, 0xb5}},
+		{BigEndian, Truncate, []byte{0xab}},
+		{LittleEndian, PadZeros, []byte{0xab, 0x03}},
+		{LittleEndian, PadOnes, []byte{0xab, 0xfb}},
+		{LittleEndian, Padding(0x55), []byte{0xab, 0x53}},
+		{LittleEndian, Truncate, []byte{0xab}},
+	} {
+		var out bytes.Buffer
+		w := c.order.NewWriter(&out)
+		w.SetPadding(c.pad)
+		w.WriteBits(8, 0xab)
+		if c.order == BigEndian {
+			w.WriteBits(3, 0x5)
+		} else {
+			w.WriteBits(3, 0x3)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+		if !bytes.Equal(out.Bytes(), c.want) {
+			t.Errorf("%v padding %v: got %x, want %x",
+				c.order, c.pad, out.Bytes(), c.want)
+		}
+	}
+
+	// Truncate holds back a partial byte across Flush
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	w.SetPadding(Truncate)
+	w.WriteBits(4, 0xc)
+	w.Flush()
+	w.WriteBits(12, 0x3de)
+	w.Flush()
+	if !bytes.Equal(out.Bytes(), []byte{0xc3, 0xde}) {
+		t.Errorf("Truncate Flush: got %x, want c3de", out.Bytes())
+	}
+
+	// Align reports the pad length
+	w = NewWriter(&out)
+	w.SetPadding(PadOnes)
+	w.WriteBits(2, 0)
+	if n, err := w.Align(); n != 6 || err != nil || w.Offset() != 8 {
+		t.Errorf("Align: got %v, %v", n, err)
+	}
+}
+
+func TestWriterCheckpoint(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriterSize(&out, 32)
+	w.WriteBits(3, 0x5)
+	c := w.Checkpoint()
+
+	// Write far more than the buffer holds, then abandon it
+	for i := 0; i < 1000; i++ {
+		w.WriteBits(7, 0x7f)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Writer wrote %v bytes past a checkpoint", out.Len())
+	}
+	if err := w.Rollback(c); err != nil || w.Offset() != 3 {
+		t.Errorf("Rollback: %v, offset %v", err, w.Offset())
+	}
+	w.WriteBits(13, 0x1234)
+	w.Commit()
+	for i := 0; i < 100; i++ {
+		w.WriteBits(8, uint64(i))
+	}
+	w.Flush()
+	if err := w.Rollback(c); err != ErrOutOfRange {
+		t.Errorf("Rollback after Flush: got %v, want ErrOutOfRange", err)
+	}
+
+	want := []byte{0xb2, 0x34}
+	for i := 0; i < 100; i++ {
+		want = append(want, byte(i))
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("Checkpoint and Rollback: got %x, want %x",
+			out.Bytes(), want)
+	}
+}
+
+func TestAppendWriter(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.Read(data)
+	for _, order := range []BitOrder{BigEndian, LittleEndian} {
+		// Stale bytes in spare capacity must not leak into the output
+		prefix := append(make([]byte, 0, 64), 0xaa, 0xff, 0xff, 0xff)[:1]
+		w := order.NewAppendWriter(prefix)
+		var out bytes.Buffer
+		ref := order.NewWriter(&out)
+		ref.WriteBits(8, 0xaa)
+		ofs := 0
+		for i := 0; ofs < 5000; i++ {
+			n := (i * 29) % 65
+			v := order.Uint(data, ofs, n)
+			w.WriteBits(n, v)
+			ref.WriteBits(n, v)
+			ofs += n
+		}
+		ref.Flush()
+		if w.Len() != 8 + ofs || !bytes.Equal(w.Bytes(), out.Bytes()) {
+			t.Errorf("%v AppendWriter: wrong output of %v bits",
+				order, w.Len())
+		}
+	}
+}